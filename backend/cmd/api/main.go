@@ -2,14 +2,20 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"net/http"
+	_ "time/tzdata" // user timezones must load in images without zoneinfo
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/server"
 )
 
 func main() {
 	database.Init()
+	if err := repository.LinkUnassignedItems(); err != nil {
+		log.Println("Inventory product migration warning:", err)
+	}
 	server := server.NewServer()
 
 	err := server.ListenAndServe()
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,7 +14,13 @@ type Config struct {
 	JWTSecret    string
 
 	PythonServiceURL string
+	OCRServiceURL    string
+	ProductLookup    string // "local" skips Open Food Facts and uses only the product catalog
 	MaxFileSize      int64
+	ImageMaxDim      int // px, photos are downscaled to this longest side before AI calls
+
+	// Uploaded images, STORAGE_DRIVER=local keeps them on disk, s3 sends them to an S3-compatible bucket
+	Storage StorageConfig
 
 	// Environment configuration
 	Environment    string
@@ -24,12 +31,32 @@ type Config struct {
 	AllowedOrigins []string
 }
 
+type StorageConfig struct {
+	Driver     string
+	LocalDir   string
+	PublicURL  string // base URL local file links are signed under
+	SigningKey string
+	URLTTL     time.Duration
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool // MinIO and most self-hosted stores need path-style URLs
+}
+
 func LoadConfig() Config {
 	maxFileSize, _ := strconv.ParseInt(os.Getenv("MAX_FILE_SIZE"), 10, 64)
 	if maxFileSize == 0 {
 		maxFileSize = 10 * 1024 * 1024 // 10MB default
 	}
 
+	imageMaxDim, _ := strconv.Atoi(os.Getenv("IMAGE_MAX_DIMENSION"))
+	if imageMaxDim <= 0 {
+		imageMaxDim = 1600
+	}
+
 	environment := os.Getenv("ENVIRONMENT")
 	if environment == "" {
 		environment = "development"
@@ -79,6 +106,39 @@ func LoadConfig() Config {
 		}
 	}
 
+	storage := StorageConfig{
+		Driver:      os.Getenv("STORAGE_DRIVER"),
+		LocalDir:    os.Getenv("STORAGE_LOCAL_DIR"),
+		PublicURL:   os.Getenv("STORAGE_PUBLIC_URL"),
+		SigningKey:  os.Getenv("STORAGE_SIGNING_KEY"),
+		S3Endpoint:  os.Getenv("S3_ENDPOINT"),
+		S3Region:    os.Getenv("S3_REGION"),
+		S3Bucket:    os.Getenv("S3_BUCKET"),
+		S3AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle: os.Getenv("S3_PATH_STYLE") != "false",
+	}
+	if storage.Driver == "" {
+		storage.Driver = "local"
+	}
+	if storage.LocalDir == "" {
+		storage.LocalDir = "uploads"
+	}
+	if storage.PublicURL == "" {
+		storage.PublicURL = "http://localhost:5000"
+	}
+	if storage.SigningKey == "" {
+		storage.SigningKey = os.Getenv("JWT_SECRET")
+	}
+	if storage.S3Region == "" {
+		storage.S3Region = "us-east-1"
+	}
+	urlTTL, _ := strconv.Atoi(os.Getenv("STORAGE_URL_TTL_MINUTES"))
+	if urlTTL <= 0 {
+		urlTTL = 60
+	}
+	storage.URLTTL = time.Duration(urlTTL) * time.Minute
+
 	return Config{
 		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
 		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
@@ -86,7 +146,11 @@ func LoadConfig() Config {
 		JWTSecret:    os.Getenv("JWT_SECRET"),
 
 		PythonServiceURL: os.Getenv("PYTHON_SERVICE_URL"),
+		OCRServiceURL:    os.Getenv("OCR_SERVICE_URL"),
+		ProductLookup:    os.Getenv("PRODUCT_LOOKUP"),
 		MaxFileSize:      maxFileSize,
+		ImageMaxDim:      imageMaxDim,
+		Storage:          storage,
 
 		Environment:    environment,
 		IsProduction:   isProduction,
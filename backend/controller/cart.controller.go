@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -8,12 +9,29 @@ import (
 	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 var cartService = service.CartService{}
 
+type CartController struct {
+	recipeService *service.RecipeService
+}
+
+func NewCartController(recipeService *service.RecipeService) *CartController {
+	return &CartController{
+		recipeService: recipeService,
+	}
+}
+
+type CheckCartItemRequest struct {
+	IsChecked bool     `json:"IsChecked"`
+	PricePaid *float64 `json:"PricePaid"`
+}
+
 type CreateCartItemRequest struct {
 	CartID     uuid.UUID `json:"CartID"`
 	Name       string    `json:"Name"`
@@ -75,12 +93,25 @@ func GetAllCartHandler(c *gin.Context) {
 	userData := user.(middleware.JWTUserData)
 	userID, _ := uuid.Parse(userData.ID)
 
-	carts, err := cartService.GetAllCartsByUser(userID)
+	page, err := utils.ParsePageRequest(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	carts, pageInfo, err := cartService.GetAllCartsByUser(userID, page)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch carts"})
 		return
 	}
-	c.JSON(http.StatusOK, carts)
+	c.JSON(http.StatusOK, gin.H{
+		"data":       carts,
+		"pagination": pageInfo,
+	})
 }
 
 func GetCartDetailHandler(c *gin.Context) {
@@ -143,3 +174,83 @@ func DeleteCartHandler(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Cart deleted"})
 }
+
+func CheckCartItemHandler(c *gin.Context) {
+	user, _ := c.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, _ := uuid.Parse(userData.ID)
+
+	cartItemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart item ID"})
+		return
+	}
+
+	var req CheckCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := cartService.CheckCartItem(cartItemID, userID, req.IsChecked, req.PricePaid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrCartNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cart item updated"})
+}
+
+func (ctrl *CartController) CompleteShoppingHandler(c *gin.Context) {
+	user, _ := c.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, _ := uuid.Parse(userData.ID)
+
+	cartID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart ID"})
+		return
+	}
+
+	items, err := cartService.CompleteShopping(cartID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrCartNotOwned):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		case errors.Is(err, service.ErrNothingToPurchase):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete shopping"})
+		}
+		return
+	}
+
+	afterInventoryChange(ctrl.recipeService, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shopping completed", "items": items})
+}
+
+func GetCartBudgetHandler(c *gin.Context) {
+	user, _ := c.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, _ := uuid.Parse(userData.ID)
+
+	cartID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart ID"})
+		return
+	}
+
+	estimate, err := service.EstimateCartCost(cartID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrCartNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": estimate})
+}
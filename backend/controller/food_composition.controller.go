@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SearchFoodCompositionsHandler fuzzy-searches the food composition table, ?q=nasgor&limit=10
+func SearchFoodCompositionsHandler(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	foods, err := service.SearchFoodCompositions(ctx.Query("q"), limit)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    foods,
+	})
+}
+
+func GetFoodCompositionHandler(ctx *gin.Context) {
+	food, err := repository.GetFoodCompositionByCode(ctx.Param("code"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Food not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    food,
+	})
+}
@@ -2,33 +2,48 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 )
 
 type FoodJournalRequest struct {
-	ID             string `json:"id"`
-	MealName       string `json:"meal_name"`
-	MealType       string `json:"meal_type"`
-	Description    string `json:"description"`
-	FeelingBefore  string `json:"feeling_before"`
-	FeelingAfter   string `json:"feeling_after"`
-	InputType      string `json:"input_type"`
-	RawInput       string `json:"raw_input"`
-	ProcessedInput string `json:"processed_input"`
-	ImageURL       string `json:"image_url"`
-	FoodAnalysis   string `json:"food_analysis"`
-	CreatedAt      string `json:"created_at"`
+	ID             string   `json:"id"`
+	MealName       string   `json:"meal_name"`
+	MealType       string   `json:"meal_type"`
+	Description    string   `json:"description"`
+	FeelingBefore  string   `json:"feeling_before"`
+	FeelingAfter   string   `json:"feeling_after"`
+	InputType      string   `json:"input_type"`
+	RawInput       string   `json:"raw_input"`
+	ProcessedInput string   `json:"processed_input"`
+	ImageURL       string   `json:"image_url"`
+	ImageID        string   `json:"image_id"`  // returned by analyze-image
+	ImageIDs       []string `json:"image_ids"` // returned by analyze-meal
+	FoodAnalysis   string   `json:"food_analysis"`
+	CreatedAt      string   `json:"created_at"`
+
+	// Packaged products eaten in the meal, their nutrition comes from the label
+	ProductItems []schema.ProductPortion `json:"product_items"`
+
+	// Picked on the feeling scale, 1 to 5. Left at 0 the intensity is read from the feeling text.
+	FeelingBeforeIntensity int              `json:"feeling_before_intensity"`
+	FeelingAfterIntensity  int              `json:"feeling_after_intensity"`
+	Symptoms               []schema.Feeling `json:"symptoms"`
 }
 
 func formatFoodJournalResponse(journal schema.FoodJournal) gin.H {
@@ -40,10 +55,12 @@ func formatFoodJournalResponse(journal schema.FoodJournal) gin.H {
 		"description":        journal.Description,
 		"feeling_before":     journal.FeelingBefore,
 		"feeling_after":      journal.FeelingAfter,
+		"feelings":           journal.Feelings,
 		"input_type":         journal.InputType,
 		"raw_input":          journal.RawInput,
 		"processed_input":    journal.ProcessedInput,
 		"image_url":          journal.ImageURL,
+		"image_id":           journal.ImageID,
 		"ai_nutrition":       journal.AINutrition,
 		"ai_feedback":        journal.AIFeedback,
 		"ai_recommendations": journal.AIRecommendations,
@@ -73,7 +90,8 @@ func GetFoodJournalDashboardHandler(c *gin.Context) {
 		return
 	}
 
-	todayJournals, err := repository.GetTodayFoodJournalByUserID(userData.ID)
+	todayStart, todayEnd := service.TodayBounds(service.UserLocation(userID))
+	todayJournals, err := repository.GetTodayFoodJournalByUserID(userData.ID, todayStart, todayEnd)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -90,6 +108,12 @@ func GetFoodJournalDashboardHandler(c *gin.Context) {
 		todayNutrition.Sodium += journal.AINutrition.Sodium
 	}
 
+	targets, err := service.GetNutritionTargets(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute nutrition targets: " + err.Error()})
+		return
+	}
+
 	recentJournals, err := repository.GetRecentFoodJournalByUserID(userData.ID, 5)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -116,6 +140,8 @@ func GetFoodJournalDashboardHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"data": gin.H{
 			"today_nutrition":       todayNutrition,
+			"nutrition_targets":     targets,
+			"remaining_nutrition":   service.RemainingNutrition(targets, todayNutrition),
 			"recent_meals":          recentMeals,
 			"next_meal_suggestions": recipes,
 			"total_meals_today":     len(todayJournals),
@@ -123,6 +149,98 @@ func GetFoodJournalDashboardHandler(c *gin.Context) {
 	})
 }
 
+// GetNutritionProgressHandler returns intake against targets for ?startDate=&endDate=, bucketed by
+// ?period=day|week. Defaults to the last 7 days, or the last 4 weeks for weekly buckets.
+func GetNutritionProgressHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	period := c.Query("period")
+	endDate := service.UserToday(userID)
+	startDate := endDate.AddDate(0, 0, -6)
+	if period == "week" {
+		startDate = endDate.AddDate(0, 0, -27)
+	}
+
+	if startStr := c.Query("startDate"); startStr != "" {
+		startDate, err = time.Parse("2006-01-02", startStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startDate format, use YYYY-MM-DD"})
+			return
+		}
+	}
+	if endStr := c.Query("endDate"); endStr != "" {
+		endDate, err = time.Parse("2006-01-02", endStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endDate format, use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	progress, err := service.GetNutritionProgress(userID, startDate, endDate, period)
+	if errors.Is(err, service.ErrInvalidNutritionPeriod) || errors.Is(err, service.ErrInvalidDateRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get nutrition progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
+
+// GetFeelingInsightsHandler relates feelings and symptoms after meals to foods, meal times and nutrients
+// over ?startDate=&endDate= (YYYY-MM-DD), the last 90 days by default
+func GetFeelingInsightsHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	endDate := service.UserToday(userID)
+	startDate := endDate.AddDate(0, 0, -89)
+
+	if startStr := c.Query("startDate"); startStr != "" {
+		startDate, err = time.Parse("2006-01-02", startStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startDate format, use YYYY-MM-DD"})
+			return
+		}
+	}
+	if endStr := c.Query("endDate"); endStr != "" {
+		endDate, err = time.Parse("2006-01-02", endStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endDate format, use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	insights, err := service.GetFeelingInsights(userID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": insights})
+}
+
 // func generateAIMealSuggestions(userID string, currentNutrition schema.AINutrition, todayMeals []schema.FoodJournal) ([]gin.H, error) {
 // 	context := buildMealSuggestionContext(currentNutrition, todayMeals, userID)
 
@@ -482,6 +600,7 @@ func GetFoodJournalDashboardHandler(c *gin.Context) {
 // 	return false
 // }
 
+// GetAllFoodJournalHandler returns the user's journal newest first, paginated with ?limit=&cursor=
 func GetAllFoodJournalHandler(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
@@ -490,20 +609,41 @@ func GetAllFoodJournalHandler(c *gin.Context) {
 	}
 
 	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	respondFoodJournalPage(c, userID, schema.FoodJournalSearch{})
+}
+
+// respondFoodJournalPage writes one page of matching journal entries with the pagination envelope
+func respondFoodJournalPage(c *gin.Context, userID uuid.UUID, search schema.FoodJournalSearch) {
+	page, err := utils.ParsePageRequest(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	data, err := repository.GetAllFoodJournalByUserID(userData.ID)
+	data, pageInfo, err := service.SearchFoodJournal(userID, search, page)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	var response []gin.H
+	response := []gin.H{}
 	for _, journal := range data {
 		response = append(response, formatFoodJournalResponse(journal))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": response,
+		"data":       response,
+		"pagination": pageInfo,
 	})
 }
 
@@ -515,8 +655,14 @@ func GetTodayFoodJournalHandler(c *gin.Context) {
 	}
 
 	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
-	data, err := repository.GetTodayFoodJournalByUserID(userData.ID)
+	todayStart, todayEnd := service.TodayBounds(service.UserLocation(userID))
+	data, err := repository.GetTodayFoodJournalByUserID(userData.ID, todayStart, todayEnd)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -532,75 +678,93 @@ func GetTodayFoodJournalHandler(c *gin.Context) {
 	})
 }
 
-// func GetFilteredFoodJournalHandler(c *gin.Context) {
-// 	user, exists := c.Get("user")
-// 	if !exists {
-// 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-// 		return
-// 	}
-
-// 	userData := user.(middleware.JWTUserData)
-// 	searchName := c.Query("meal_name")
-// 	startDateStr := c.Query("start_date")
-// 	endDateStr := c.Query("end_date")
-// 	mealType := c.Query("meal_type")
+// SearchFoodJournalHandler filters the journal by ?q= (meal name, description or detected food),
+// ?start_date=&end_date= (the user's calendar days, inclusive), ?meal_type= and nutrient ranges such as
+// ?min_calories=&max_sodium=. Results are paginated with ?limit=&cursor=.
+func SearchFoodJournalHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
 
-// 	var startDate, endDate *time.Time
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
-// 	if startDateStr != "" {
-// 		if t, err := time.Parse("2006-01-02", startDateStr); err == nil {
-// 			startDate = &t
-// 		} else {
-// 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
-// 			return
-// 		}
-// 	}
+	search := schema.FoodJournalSearch{
+		Query:       strings.TrimSpace(c.Query("q")),
+		MealType:    c.Query("meal_type"),
+		MinNutrient: map[string]float64{},
+		MaxNutrient: map[string]float64{},
+	}
 
-// 	if endDateStr != "" {
-// 		if t, err := time.Parse("2006-01-02", endDateStr); err == nil {
-// 			endDate = &t
-// 		} else {
-// 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
-// 			return
-// 		}
-// 	}
+	loc := service.UserLocation(userID)
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, use YYYY-MM-DD"})
+			return
+		}
+		start, _ := service.LocalDayBounds(t, loc)
+		search.Start = &start
+	}
 
-// 	data, err := repository.GetFilteredFoodJournal(userData.ID, searchName, startDate, endDate, mealType)
-// 	if err != nil {
-// 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-// 		return
-// 	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, use YYYY-MM-DD"})
+			return
+		}
+		_, end := service.LocalDayBounds(t, loc)
+		search.End = &end
+	}
 
-// 	c.JSON(http.StatusOK, gin.H{
-// 		"data": data,
-// 	})
-// }
+	for key, values := range c.Request.URL.Query() {
+		bound, nutrient, ok := strings.Cut(key, "_")
+		if (bound != "min" && bound != "max") || !ok || !repository.IsJournalNutrient(nutrient) {
+			continue
+		}
+		value, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s, must be a number", key)})
+			return
+		}
+		if bound == "min" {
+			search.MinNutrient[nutrient] = value
+		} else {
+			search.MaxNutrient[nutrient] = value
+		}
+	}
 
-// func GetFoodJournalByMealTypeHandler(c *gin.Context) {
-// 	user, exists := c.Get("user")
-// 	if !exists {
-// 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-// 		return
-// 	}
+	respondFoodJournalPage(c, userID, search)
+}
 
-// 	userData := user.(middleware.JWTUserData)
-// 	mealType := c.Query("type")
+func GetFoodJournalByMealTypeHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
 
-// 	if mealType == "" {
-// 		c.JSON(http.StatusBadRequest, gin.H{"error": "Meal type is required"})
-// 		return
-// 	}
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
-// 	data, err := repository.GetFoodJournalByMealType(userData.ID, mealType)
-// 	if err != nil {
-// 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-// 		return
-// 	}
+	mealType := c.Query("type")
+	if mealType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Meal type is required"})
+		return
+	}
 
-// 	c.JSON(http.StatusOK, gin.H{
-// 		"data": data,
-// 	})
-// }
+	respondFoodJournalPage(c, userID, schema.FoodJournalSearch{MealType: mealType})
+}
 
 func CreateNewFoodJournalHandler(c *gin.Context) {
 	user, exists := c.Get("user")
@@ -638,12 +802,32 @@ func CreateNewFoodJournalHandler(c *gin.Context) {
 			return
 		}
 	case "image":
-		if req.ImageURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Image URL is required for image input"})
+		if req.ImageURL == "" && req.ImageID == "" && len(req.ImageIDs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Image URL or image ID is required for image input"})
+			return
+		}
+	case "product":
+		if len(req.ProductItems) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At least one product item is required for product input"})
 			return
 		}
 	}
 
+	var imageIDs []uuid.UUID
+	for _, id := range append([]string{req.ImageID}, req.ImageIDs...) {
+		if id == "" {
+			continue
+		}
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+			return
+		}
+		if !slices.Contains(imageIDs, parsed) {
+			imageIDs = append(imageIDs, parsed)
+		}
+	}
+
 	err = service.CreateNewFoodJournal(service.FoodJournalInput{
 		UserID:         userID,
 		MealName:       req.MealName,
@@ -652,11 +836,17 @@ func CreateNewFoodJournalHandler(c *gin.Context) {
 		FeelingBefore:  req.FeelingBefore,
 		FeelingAfter:   req.FeelingAfter,
 		ImageURL:       req.ImageURL,
+		ImageIDs:       imageIDs,
 		InputType:      req.InputType,
 		RawInput:       req.RawInput,
 		ProcessedInput: req.ProcessedInput,
 		FoodAnalysis:   req.FoodAnalysis,
+		ProductItems:   req.ProductItems,
 		CreatedAt:      createdAt,
+
+		FeelingBeforeIntensity: req.FeelingBeforeIntensity,
+		FeelingAfterIntensity:  req.FeelingAfterIntensity,
+		Symptoms:               req.Symptoms,
 	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -690,6 +880,18 @@ func UpdateFoodJournalHandler(c *gin.Context) {
 		createdAt = time.Now()
 	}
 
+	feelings, err := service.NormalizeFeelings(service.FeelingInput{
+		Before:          req.FeelingBefore,
+		After:           req.FeelingAfter,
+		BeforeIntensity: req.FeelingBeforeIntensity,
+		AfterIntensity:  req.FeelingAfterIntensity,
+		Symptoms:        req.Symptoms,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	foodJournal := schema.FoodJournal{
 		BaseModel:      schema.BaseModel{ID: id},
 		MealName:       req.MealName,
@@ -697,6 +899,7 @@ func UpdateFoodJournalHandler(c *gin.Context) {
 		Description:    req.Description,
 		FeelingBefore:  req.FeelingBefore,
 		FeelingAfter:   req.FeelingAfter,
+		Feelings:       feelings,
 		ImageURL:       req.ImageURL,
 		InputType:      req.InputType,
 		RawInput:       req.RawInput,
@@ -743,6 +946,115 @@ func GetFoodJournalByIDHandler(c *gin.Context) {
 	})
 }
 
+// EditDetectedFoodsHandler changes portions of the entry's detected foods and recomputes its nutrition
+func EditDetectedFoodsHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req schema.DetectedFoodEditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	journal, err := service.EditDetectedFoods(c.Param("id"), userID, req.Foods)
+	if err != nil {
+		respondFoodJournalEditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": formatFoodJournalResponse(*journal)})
+}
+
+// ReanalyzeFoodJournalHandler runs a fresh AI analysis of the entry, optionally from a corrected description
+func ReanalyzeFoodJournalHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req schema.ReanalyzeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	journal, err := service.ReanalyzeFoodJournal(c.Param("id"), userID, strings.TrimSpace(req.Description))
+	if err != nil {
+		respondFoodJournalEditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": formatFoodJournalResponse(*journal)})
+}
+
+func GetFoodJournalHistoryHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	page, err := utils.ParsePageRequest(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, pageInfo, err := service.GetFoodJournalHistory(c.Param("id"), userID, page)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondFoodJournalEditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       revisions,
+		"pagination": pageInfo,
+	})
+}
+
+func respondFoodJournalEditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrFoodJournalNotOwned):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Food journal not found"})
+	case errors.Is(err, service.ErrInvalidFoodEdit), errors.Is(err, service.ErrNoFoodAnalysis), errors.Is(err, service.ErrNothingToAnalyze):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func AnalyzeFoodFromTextHandler(c *gin.Context) {
 	var req struct {
 		Description string `json:"description" binding:"required"`
@@ -764,11 +1076,29 @@ func AnalyzeFoodFromTextHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze food: " + err.Error()})
 		return
 	}
+	service.GroundDetectedFoods(foodAnalysis)
 
 	c.JSON(http.StatusOK, foodAnalysis)
 }
 
-func AnalyzeFoodFromImageHandler(c *gin.Context) {
+// FoodJournalController holds the journal handlers that need injected services
+type FoodJournalController struct {
+	imageService *service.ImageService
+}
+
+func NewFoodJournalController(imageService *service.ImageService) *FoodJournalController {
+	return &FoodJournalController{
+		imageService: imageService,
+	}
+}
+
+// foodImageAnalysis is the analysis with the stored photo, whose id is sent back when creating the entry
+type foodImageAnalysis struct {
+	*schema.FoodAnalysis
+	Image *schema.StoredImage `json:"image,omitempty"`
+}
+
+func (fc *FoodJournalController) AnalyzeFoodFromImageHandler(c *gin.Context) {
 	file, header, err := c.Request.FormFile("image")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required"})
@@ -776,6 +1106,16 @@ func AnalyzeFoodFromImageHandler(c *gin.Context) {
 	}
 	defer file.Close()
 
+	reference, referenceHint, ok := readPortionReference(c)
+	if !ok {
+		return
+	}
+
+	upload, ok := readImageUpload(c, fc.imageService, file, header)
+	if !ok {
+		return
+	}
+
 	description := c.PostForm("description")
 
 	geminiService, err := service.NewGeminiService()
@@ -784,7 +1124,7 @@ func AnalyzeFoodFromImageHandler(c *gin.Context) {
 		return
 	}
 
-	foodAnalysis, err := geminiService.AnalyzeFoodFromImage(file, header)
+	foodAnalysis, err := geminiService.AnalyzeFoodFromImage(upload.Prepared.Data, upload.Prepared.ContentType, referenceHint)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze food: " + err.Error()})
 		return
@@ -793,8 +1133,117 @@ func AnalyzeFoodFromImageHandler(c *gin.Context) {
 	if description != "" && strings.TrimSpace(description) != "" {
 		foodAnalysis = enhanceFoodAnalysisWithText(geminiService, foodAnalysis, strings.TrimSpace(description))
 	}
+	service.GroundDetectedFoods(foodAnalysis)
+	service.ApplyPortionBounds(foodAnalysis, reference)
 
-	c.JSON(http.StatusOK, foodAnalysis)
+	// Keeping the photo is best effort, it is only stored once the analysis succeeded
+	image := storeUserUpload(c, fc.imageService, upload)
+	c.JSON(http.StatusOK, foodImageAnalysis{FoodAnalysis: foodAnalysis, Image: image})
+}
+
+// mealAnalysis is a multi-photo analysis with the stored photos, in the order they were sent
+type mealAnalysis struct {
+	*schema.FoodAnalysis
+	Images []*schema.StoredImage `json:"images"`
+}
+
+// AnalyzeMealHandler analyzes several photos of one meal ("images", optional "kinds" in the same
+// order: plate, menu or label) and an optional "description" in one AI request
+func (fc *FoodJournalController) AnalyzeMealHandler(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["images"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one image is required"})
+		return
+	}
+	headers := form.File["images"]
+	if len(headers) > service.MaxMealPhotos {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d images can be analyzed together", service.MaxMealPhotos)})
+		return
+	}
+	kinds := form.Value["kinds"]
+
+	reference, referenceHint, ok := readPortionReference(c)
+	if !ok {
+		return
+	}
+
+	// Every photo is validated before any is analyzed or stored
+	uploads := make([]*service.ImageUpload, 0, len(headers))
+	images := make([]service.MealImage, 0, len(headers))
+	photos := make([]schema.MealPhoto, 0, len(headers))
+	for i, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read image " + header.Filename})
+			return
+		}
+		upload, ok := readImageUpload(c, fc.imageService, file, header)
+		file.Close()
+		if !ok {
+			return
+		}
+
+		kind := schema.MealPhotoPlate
+		if i < len(kinds) {
+			kind = service.MealPhotoKind(kinds[i])
+		}
+
+		uploads = append(uploads, upload)
+		images = append(images, service.MealImage{
+			Data:     upload.Prepared.Data,
+			MimeType: upload.Prepared.ContentType,
+			Kind:     kind,
+		})
+		photos = append(photos, schema.MealPhoto{Number: i + 1, Kind: kind})
+	}
+
+	foodAnalysis, err := service.AnalyzeMeal(images, photos, strings.TrimSpace(c.PostForm("description")), reference, referenceHint)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze meal: " + err.Error()})
+		return
+	}
+
+	// A failed analysis leaves no stored photos behind
+	stored := make([]*schema.StoredImage, len(uploads))
+	for i, upload := range uploads {
+		stored[i] = storeUserUpload(c, fc.imageService, upload)
+		if stored[i] != nil {
+			foodAnalysis.Photos[i].ImageID = &stored[i].ID
+		}
+	}
+
+	c.JSON(http.StatusOK, mealAnalysis{FoodAnalysis: foodAnalysis, Images: stored})
+}
+
+// readPortionReference reads the optional object of known size in the photo from the form fields
+// reference_kind (plate, hand, spoon or package), reference_size (plate diameter in cm),
+// reference_barcode and reference_note, and describes it for the AI prompt
+func readPortionReference(c *gin.Context) (*schema.PortionReference, string, bool) {
+	kind := strings.ToLower(strings.TrimSpace(c.PostForm("reference_kind")))
+	if kind == "" {
+		return nil, "", true
+	}
+
+	reference := &schema.PortionReference{
+		Kind:    kind,
+		Barcode: strings.TrimSpace(c.PostForm("reference_barcode")),
+		Note:    strings.TrimSpace(c.PostForm("reference_note")),
+	}
+	if size := strings.TrimSpace(c.PostForm("reference_size")); size != "" {
+		value, err := strconv.ParseFloat(size, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reference_size must be a number"})
+			return nil, "", false
+		}
+		reference.Size = value
+	}
+
+	hint, err := service.PortionReferenceHint(reference)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, "", false
+	}
+	return reference, hint, true
 }
 
 func enhanceFoodAnalysisWithText(geminiService *service.GeminiService, imageAnalysis *schema.FoodAnalysis, description string) *schema.FoodAnalysis {
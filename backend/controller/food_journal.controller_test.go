@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/config"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+	img.Set(0, 0, color.White)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func postMealPhotos(t *testing.T, files map[string][]byte, names ...string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	images, err := service.NewImageService(config.Config{
+		MaxFileSize: 10 << 20,
+		ImageMaxDim: 1024,
+		Storage:     config.StorageConfig{Driver: "local", LocalDir: dir, SigningKey: "signing-key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewFoodJournalController(images)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for _, name := range names {
+		part, err := form.CreateFormFile("images", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(files[name])
+	}
+	form.Close()
+
+	router := gin.New()
+	router.POST("/meal", func(c *gin.Context) {
+		c.Set("user", middleware.JWTUserData{ID: uuid.NewString()})
+	}, controller.AnalyzeMealHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/meal", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, dir
+}
+
+func TestAnalyzeMealStoresNothingOnFailure(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "")
+	files := map[string][]byte{
+		"piring.jpg":  testJPEG(t),
+		"menu.jpg":    testJPEG(t),
+		"animasi.gif": []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00"),
+	}
+
+	tests := []struct {
+		name   string
+		photos []string
+		status int
+	}{
+		// The valid first photo is not kept when a later one is rejected
+		{"invalid later photo", []string{"piring.jpg", "animasi.gif"}, http.StatusUnsupportedMediaType},
+		// Without Gemini the analysis fails, and the photos are not kept either
+		{"failed analysis", []string{"piring.jpg", "menu.jpg"}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, dir := postMealPhotos(t, files, tt.photos...)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("storage has %d entries after a failed request, want none", len(entries))
+			}
+		})
+	}
+}
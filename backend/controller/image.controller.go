@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImageController struct {
+	imageService *service.ImageService
+}
+
+func NewImageController(imageService *service.ImageService) *ImageController {
+	return &ImageController{
+		imageService: imageService,
+	}
+}
+
+// GetImageHandler returns an uploaded image with fresh signed links
+func (ic *ImageController) GetImageHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	image, err := ic.imageService.GetImage(c.Param("id"), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrImageNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": image})
+}
+
+// GetOwnerImagesHandler lists the images of a journal entry, receipt or item (?owner_type=&owner_id=)
+func (ic *ImageController) GetOwnerImagesHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ownerType := c.Query("owner_type")
+	switch ownerType {
+	case schema.ImageOwnerFoodJournal, schema.ImageOwnerReceipt, schema.ImageOwnerItem:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "owner_type must be food_journal, receipt or item"})
+		return
+	}
+	ownerID, err := uuid.Parse(c.Query("owner_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid owner ID"})
+		return
+	}
+
+	images, err := ic.imageService.GetOwnerImages(userID, ownerType, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": images})
+}
+
+// ServeFileHandler serves files of the local storage driver behind their signed links
+func (ic *ImageController) ServeFileHandler(c *gin.Context) {
+	storage, ok := ic.imageService.Storage().(*service.LocalStorage)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := storage.VerifySignedURL(key, c.Query("expires"), c.Query("signature")); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	path, err := storage.Path(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	if contentType := service.StoredContentType(key); contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	c.File(path)
+}
+
+// readImageUpload validates the uploaded file, a rejected image is answered with its error code
+func readImageUpload(c *gin.Context, imageService *service.ImageService, file multipart.File, header *multipart.FileHeader) (*service.ImageUpload, bool) {
+	upload, err := imageService.ReadUpload(file, header)
+	if err != nil {
+		var imageErr *utils.ImageError
+		if !errors.As(err, &imageErr) {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+			return nil, false
+		}
+
+		status := http.StatusUnprocessableEntity
+		switch imageErr {
+		case utils.ErrImageTooLarge:
+			status = http.StatusRequestEntityTooLarge
+		case utils.ErrImageUnsupported:
+			status = http.StatusUnsupportedMediaType
+		}
+		c.JSON(status, gin.H{"success": false, "error": imageErr.Message, "code": imageErr.Code})
+		return nil, false
+	}
+	return upload, true
+}
+
+// storeUserUpload keeps the upload for a logged-in user, a failure only costs the stored copy. Handlers
+// call it once the analysis succeeded, so failed requests leave no stored images behind.
+func storeUserUpload(c *gin.Context, imageService *service.ImageService, upload *service.ImageUpload) *schema.StoredImage {
+	user, exists := c.Get("user")
+	if !exists || imageService == nil {
+		return nil
+	}
+	userID, err := uuid.Parse(user.(middleware.JWTUserData).ID)
+	if err != nil {
+		return nil
+	}
+
+	image, err := imageService.StoreImage(userID, upload.Original)
+	if err != nil {
+		fmt.Printf("Warning: failed to store uploaded image: %v\n", err)
+		return nil
+	}
+	return image
+}
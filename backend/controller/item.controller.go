@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"errors"
+	"io"
 	"log"
 	"net/http"
 	"time"
@@ -9,17 +11,21 @@ import (
 	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ItemController struct {
-	recipeService *service.RecipeService
+	recipeService  *service.RecipeService
+	productService service.ProductService
 }
 
-func NewItemController(recipeService *service.RecipeService) *ItemController {
+func NewItemController(recipeService *service.RecipeService, productService service.ProductService) *ItemController {
 	return &ItemController{
-		recipeService: recipeService,
+		recipeService:  recipeService,
+		productService: productService,
 	}
 }
 
@@ -32,93 +38,122 @@ type ItemRequest struct {
 	Desc       string  `json:"desc"`      // optional
 	StartDate  string  `json:"startDate"` // format: yyyy-mm-dd
 	ExpDate    string  `json:"expDate"`   // format: yyyy-mm-dd
+	ImageID    string  `json:"imageId"`   // optional, photo stored by /predict/image
 }
 
-func (ctrl *ItemController) GetAllItemHandler(c *gin.Context) {
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+type ScanItemRequest struct {
+	Barcode  string `json:"barcode" binding:"required"`
+	Quantity int    `json:"quantity"` // number of packages, default 1
+	ExpDate  string `json:"expDate"`  // optional, format: yyyy-mm-dd
+}
 
-	userData := user.(middleware.JWTUserData)
+type ScanItemBatchRequest struct {
+	Items []ScanItemRequest `json:"items" binding:"required,dive"`
+}
 
-	data, err := repository.GetAllItemByUserID(userData.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+type ConsumeProductRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": data,
-	})
+// afterInventoryChange refreshes restock suggestions and recipes in the background
+func afterInventoryChange(recipeService *service.RecipeService, userID uuid.UUID) {
+	go func() {
+		if err := service.EvaluateRestock(userID); err != nil {
+			log.Printf("Error evaluating restock for user %s: %v", userID, err)
+		}
+		if err := recipeService.GenerateAndSaveRecipes(userID); err != nil {
+			log.Printf("Error generating recipes for user %s: %v", userID, err)
+		}
+	}()
 }
 
-func (ctrl *ItemController) GetAllExpiredItemHandler(c *gin.Context) {
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+// respondItemPage writes one page of the user's batches matching search with the pagination envelope.
+// ?groupBy=product folds the batches into products, which needs every batch of a product, so that
+// view stays a single unpaged list.
+func respondItemPage(c *gin.Context, userID uuid.UUID, search schema.ItemSearch) {
+	if c.Query("groupBy") == "product" {
+		data, err := service.SearchAllItems(userID, search)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": service.GroupItemsByProduct(data),
+		})
 		return
 	}
 
-	userData := user.(middleware.JWTUserData)
+	page, err := utils.ParsePageRequest(c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	data, err := repository.GetAllExpiredItem(userData.ID)
+	data, pageInfo, err := service.SearchItems(userID, search, page)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": data,
+		"data":       data,
+		"pagination": pageInfo,
 	})
 }
 
-func (ctrl *ItemController) GetSearchedExpiredItemHandler(c *gin.Context) {
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
+// bindItemSearch reads the name, startDate, expDate and itemType filters, writing a 400 for bad dates
+func bindItemSearch(c *gin.Context, state string) (schema.ItemSearch, bool) {
+	search := schema.ItemSearch{
+		State: state,
+		Name:  c.Query("name"),
+		Type:  c.Query("itemType"),
 	}
-
-	userData := user.(middleware.JWTUserData)
-	searchName := c.Query("name")
 	startDateStr := c.Query("startDate")
 	expDateStr := c.Query("expDate")
-	itemType := c.Query("itemType")
-
-	var startDate, expDate *time.Time
 
 	if startDateStr != "" {
 		if t, err := time.Parse("2006-01-02", startDateStr); err == nil {
-			startDate = &t
+			search.Start = &t
 		} else {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startDate format"})
-			return
+			return search, false
 		}
 	}
 
 	if expDateStr != "" {
 		if t, err := time.Parse("2006-01-02", expDateStr); err == nil {
-			expDate = &t
+			search.Exp = &t
 		} else {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expDate format"})
-			return
+			return search, false
 		}
 	}
+	return search, true
+}
 
-	data, err := repository.GetFilteredExpiredItem(userData.ID, searchName, startDate, expDate, itemType)
+func (ctrl *ItemController) GetAllItemHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": data,
-	})
+	respondItemPage(c, userID, schema.ItemSearch{})
 }
 
-func (ctrl *ItemController) GetAllFreshItemHandler(c *gin.Context) {
+func (ctrl *ItemController) GetAllExpiredItemHandler(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -126,19 +161,16 @@ func (ctrl *ItemController) GetAllFreshItemHandler(c *gin.Context) {
 	}
 
 	userData := user.(middleware.JWTUserData)
-
-	data, err := repository.GetAllFreshItem(userData.ID)
+	userID, err := uuid.Parse(userData.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": data,
-	})
+	respondItemPage(c, userID, schema.ItemSearch{State: schema.ItemStateExpired})
 }
 
-func (ctrl *ItemController) GetSearchedFreshItemHandler(c *gin.Context) {
+func (ctrl *ItemController) GetSearchedExpiredItemHandler(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -146,40 +178,55 @@ func (ctrl *ItemController) GetSearchedFreshItemHandler(c *gin.Context) {
 	}
 
 	userData := user.(middleware.JWTUserData)
-	searchName := c.Query("name")
-	startDateStr := c.Query("startDate")
-	expDateStr := c.Query("expDate")
-	itemType := c.Query("itemType")
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
-	var startDate, expDate *time.Time
+	search, ok := bindItemSearch(c, schema.ItemStateExpired)
+	if !ok {
+		return
+	}
+	respondItemPage(c, userID, search)
+}
 
-	if startDateStr != "" {
-		if t, err := time.Parse("2006-01-02", startDateStr); err == nil {
-			startDate = &t
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startDate format"})
-			return
-		}
+func (ctrl *ItemController) GetAllFreshItemHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
 
-	if expDateStr != "" {
-		if t, err := time.Parse("2006-01-02", expDateStr); err == nil {
-			expDate = &t
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expDate format"})
-			return
-		}
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
 	}
 
-	data, err := repository.GetFilteredFreshItem(userData.ID, searchName, startDate, expDate, itemType)
+	respondItemPage(c, userID, schema.ItemSearch{State: schema.ItemStateFresh})
+}
+
+func (ctrl *ItemController) GetSearchedFreshItemHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": data,
-	})
+	search, ok := bindItemSearch(c, schema.ItemStateFresh)
+	if !ok {
+		return
+	}
+	respondItemPage(c, userID, search)
 }
 
 func (ctrl *ItemController) CreateNewItemHandler(c *gin.Context) {
@@ -202,6 +249,16 @@ func (ctrl *ItemController) CreateNewItemHandler(c *gin.Context) {
 		return
 	}
 
+	var imageID *uuid.UUID
+	if req.ImageID != "" {
+		parsed, err := uuid.Parse(req.ImageID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+			return
+		}
+		imageID = &parsed
+	}
+
 	err = service.CreateNewItem(service.ItemInput{
 		UserID:     userID,
 		Name:       req.Name,
@@ -211,23 +268,32 @@ func (ctrl *ItemController) CreateNewItemHandler(c *gin.Context) {
 		Desc:       req.Desc,
 		StartDate:  req.StartDate,
 		ExpDate:    req.ExpDate,
+		ImageID:    imageID,
 	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Trigger recipe generation in the background
-	go func() {
-		if err := ctrl.recipeService.GenerateAndSaveRecipes(userID); err != nil {
-			log.Printf("Error generating recipes for user %s: %v", userID, err)
-		}
-	}()
+	afterInventoryChange(ctrl.recipeService, userID)
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Item created successfully"})
 }
 
 func (ctrl *ItemController) UpdateItemHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
 	var req ItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -263,22 +329,383 @@ func (ctrl *ItemController) UpdateItemHandler(c *gin.Context) {
 		ExpDate:    expDate,
 	}
 
-	err = service.UpdateItem(item)
+	err = service.UpdateItem(item, userID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrItemNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item"})
 		return
 	}
 
+	afterInventoryChange(ctrl.recipeService, userID)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Item updated successfully"})
 }
 
+func (ctrl *ItemController) GetInventoryProductsHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	data, err := service.GetInventoryProducts(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+	})
+}
+
+func (ctrl *ItemController) GetProductBatchesHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	data, err := service.GetProductBatches(c.Param("id"), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrItemNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+	})
+}
+
+func (ctrl *ItemController) GetProductPriceTrendHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	data, err := service.GetProductPriceTrend(c.Param("id"), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrItemNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+	})
+}
+
+func (ctrl *ItemController) ConsumeProductHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req ConsumeProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	batches, err := service.ConsumeProduct(c.Param("id"), userID, req.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrItemNotOwned):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		case errors.Is(err, repository.ErrInsufficientStock):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	afterInventoryChange(ctrl.recipeService, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product consumed successfully",
+		"data":    batches,
+	})
+}
+
+type RestockRuleRequest struct {
+	MinQuantity     float64 `json:"minQuantity" binding:"required"`
+	RestockQuantity float64 `json:"restockQuantity"` // optional, defaults to minQuantity
+}
+
+func (ctrl *ItemController) SetRestockRuleHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req RestockRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := service.SetRestockRule(c.Param("id"), userID, service.RestockRuleInput{
+		MinQuantity:     req.MinQuantity,
+		RestockQuantity: req.RestockQuantity,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrItemNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	afterInventoryChange(ctrl.recipeService, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Restock rule saved",
+		"data":    rule,
+	})
+}
+
+func (ctrl *ItemController) DeleteRestockRuleHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := service.DeleteRestockRule(c.Param("id"), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrItemNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete restock rule"})
+		return
+	}
+
+	afterInventoryChange(ctrl.recipeService, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Restock rule deleted"})
+}
+
+func (ctrl *ItemController) GetLowStockItemsHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	data, err := service.GetLowStockItems(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+	})
+}
+
+type OpenItemRequest struct {
+	OpenedAt string `json:"openedAt"` // optional, format: yyyy-mm-dd
+}
+
+func (ctrl *ItemController) OpenItemHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req OpenItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := service.OpenItem(c.Param("id"), userID, req.OpenedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrItemNotOwned):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	// Effective expiry changed, fresh stock may have dropped
+	afterInventoryChange(ctrl.recipeService, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item marked as opened",
+		"data":    item,
+	})
+}
+
 func (ctrl *ItemController) DeleteItemHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
 	itemID := c.Param("id")
-	err := service.DeleteItem(itemID)
+	err = service.DeleteItem(itemID, userID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrItemNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete item"})
 		return
 	}
 
+	afterInventoryChange(ctrl.recipeService, userID)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Item deleted successfully"})
-}
\ No newline at end of file
+}
+
+// ScanItemHandler adds one scanned barcode product to the fridge
+func (ctrl *ItemController) ScanItemHandler(c *gin.Context) {
+	var req ScanItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctrl.scanItems(c, []ScanItemRequest{req})
+}
+
+// ScanItemBatchHandler adds many scanned barcode products in one request
+func (ctrl *ItemController) ScanItemBatchHandler(c *gin.Context) {
+	var req ScanItemBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctrl.scanItems(c, req.Items)
+}
+
+func (ctrl *ItemController) scanItems(c *gin.Context, requests []ScanItemRequest) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	entries := make([]service.ScanEntry, 0, len(requests))
+	for _, req := range requests {
+		entries = append(entries, service.ScanEntry{
+			Barcode:  req.Barcode,
+			Quantity: req.Quantity,
+			ExpDate:  req.ExpDate,
+		})
+	}
+
+	results, err := service.ScanToInventory(ctrl.productService, userID, entries)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stored := 0
+	for _, result := range results {
+		if result.Status == service.ScanStatusAdded || result.Status == service.ScanStatusIncremented {
+			stored++
+		}
+	}
+	if stored > 0 {
+		afterInventoryChange(ctrl.recipeService, userID)
+	}
+
+	status := http.StatusOK
+	if stored == 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, gin.H{
+		"data":   results,
+		"stored": stored,
+	})
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+)
+
+// GetMealTemplatesHandler lists the user's favorite meals, most used first. The list is not paged:
+// the picker ranks every favorite by use count, which the time-keyed page cursor cannot follow.
+func GetMealTemplatesHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	templates, err := service.GetMealTemplates(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": templates})
+}
+
+// CreateMealTemplateHandler saves a favorite meal, from {"journal_id"} or composed from "foods" and "product_items"
+func CreateMealTemplateHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req schema.MealTemplateCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := service.CreateMealTemplate(userID, req)
+	if err != nil {
+		respondMealTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": template})
+}
+
+func GetMealTemplateByIDHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	template, err := service.GetMealTemplate(c.Param("id"), userID)
+	if err != nil {
+		respondMealTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+func UpdateMealTemplateHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req schema.MealTemplateUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := service.UpdateMealTemplate(c.Param("id"), userID, req)
+	if err != nil {
+		respondMealTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+func DeleteMealTemplateHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := service.DeleteMealTemplate(c.Param("id"), userID); err != nil {
+		respondMealTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Meal template deleted successfully"})
+}
+
+// LogMealTemplateHandler adds the saved meal to the journal at "eaten_at" without an AI call
+func LogMealTemplateHandler(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req schema.MealTemplateLog
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	journal, err := service.LogMealTemplate(c.Param("id"), userID, req)
+	if err != nil {
+		respondMealTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": formatFoodJournalResponse(*journal)})
+}
+
+func respondMealTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrMealTemplateNotOwned):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Meal template not found"})
+	case errors.Is(err, service.ErrFoodJournalNotOwned):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Food journal not found"})
+	case errors.Is(err, service.ErrInvalidMealTemplate), errors.Is(err, service.ErrNoFoodAnalysis),
+		errors.Is(err, service.ErrInvalidFeeling):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
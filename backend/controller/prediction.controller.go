@@ -7,16 +7,17 @@ import (
 	"time"
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
-	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 )
 
 type PredictionController struct {
 	predictionService *service.GeminiService
+	imageService      *service.ImageService
 }
 
-func NewPredictionController(predictionService *service.GeminiService) *PredictionController {
+func NewPredictionController(predictionService *service.GeminiService, imageService *service.ImageService) *PredictionController {
 	return &PredictionController{
 		predictionService: predictionService,
+		imageService:      imageService,
 	}
 }
 
@@ -33,24 +34,14 @@ func (pc *PredictionController) PredictItemHandler(ctx *gin.Context) {
 	}
 	defer file.Close()
 
-	if !utils.IsValidImageType(header.Filename) {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Format file tidak didukung. Hanya JPG, JPEG, PNG yang diizinkan.",
-		})
-		return
-	}
-
-	if !utils.IsValidImageSize(header.Size) {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Ukuran file terlalu besar. Maksimal 10MB",
-		})
+	// Jenis file dicek dari isinya, lalu gambar diperkecil sebelum dikirim ke AI
+	upload, ok := readImageUpload(ctx, pc.imageService, file, header)
+	if !ok {
 		return
 	}
 
 	// 2. Panggil service untuk melakukan prediksi
-	aiResult, err := pc.predictionService.PredictItem(file, header)
+	aiResult, err := pc.predictionService.PredictItem(upload.Prepared.Data, upload.Prepared.ContentType)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -59,14 +50,21 @@ func (pc *PredictionController) PredictItemHandler(ctx *gin.Context) {
 		return
 	}
 
+	// Foto disimpan setelah prediksi berhasil jika pengguna login, id-nya dikirim lagi saat item dibuat
+	image := storeUserUpload(ctx, pc.imageService, upload)
+
 	// 3. Return the AI result directly in the format expected by frontend
-	ctx.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"item_name":                aiResult.ItemName,
 		"condition_description":    aiResult.ConditionDescription,
 		"predicted_remaining_days": aiResult.PredictedRemainingDays,
 		"reasoning":                aiResult.Reasoning,
 		"confidence":               aiResult.Confidence,
-	})
+	}
+	if image != nil {
+		response["image"] = image
+	}
+	ctx.JSON(http.StatusOK, response)
 }
 
 // Handler untuk health check
@@ -1,19 +1,30 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ReceiptController struct {
 	receiptService *service.ReceiptService
+	recipeService  *service.RecipeService
+	imageService   *service.ImageService
 }
 
-func NewReceiptController(receiptService *service.ReceiptService) *ReceiptController {
+func NewReceiptController(receiptService *service.ReceiptService, recipeService *service.RecipeService, imageService *service.ImageService) *ReceiptController {
 	return &ReceiptController{
 		receiptService: receiptService,
+		recipeService:  recipeService,
+		imageService:   imageService,
 	}
 }
 
@@ -29,8 +40,14 @@ func (rc *ReceiptController) AnalyzeReceiptHandler(ctx *gin.Context) {
 	}
 	defer file.Close()
 
-	// 2. Panggil service untuk melakukan analisis
-	result, err := rc.receiptService.AnalyzeReceipt(file, header)
+	// 2. Validasi isi file dan perkecil gambar sebelum dianalisis
+	upload, ok := readImageUpload(ctx, rc.imageService, file, header)
+	if !ok {
+		return
+	}
+
+	// 3. Panggil service untuk melakukan analisis, teks OCR dari client dipakai jika Gemini gagal
+	result, err := rc.receiptService.AnalyzeReceipt(upload.Prepared.Data, upload.Prepared.ContentType, ctx.PostForm("ocrText"))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -38,7 +55,178 @@ func (rc *ReceiptController) AnalyzeReceiptHandler(ctx *gin.Context) {
 		})
 		return
 	}
+	// Foto hanya disimpan setelah analisis berhasil
+	result.Image = storeUserUpload(ctx, rc.imageService, upload)
+
+	// 4. Return the result
+	ctx.JSON(http.StatusOK, result)
+}
+
+// AnalyzeReceiptTextHandler parses OCR text of a receipt with the local parser, without Gemini
+func (rc *ReceiptController) AnalyzeReceiptTextHandler(ctx *gin.Context) {
+	var req schema.ReceiptTextRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Teks struk tidak ditemukan",
+		})
+		return
+	}
+
+	result, err := rc.receiptService.AnalyzeReceiptText(req.Text)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrNoReceiptItems) {
+			status = http.StatusUnprocessableEntity
+		}
+		ctx.JSON(status, gin.H{
+			"success": false,
+			"error":   "Gagal membaca teks struk: " + err.Error(),
+		})
+		return
+	}
 
-	// 3. Return the result
 	ctx.JSON(http.StatusOK, result)
-}
\ No newline at end of file
+}
+
+func (rc *ReceiptController) CreateImportDraftHandler(ctx *gin.Context) {
+	user, _ := ctx.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid user ID"})
+		return
+	}
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "File gambar tidak ditemukan atau format tidak valid",
+		})
+		return
+	}
+	defer file.Close()
+
+	upload, ok := readImageUpload(ctx, rc.imageService, file, header)
+	if !ok {
+		return
+	}
+
+	var imageID *uuid.UUID
+	image := storeUserUpload(ctx, rc.imageService, upload)
+	if image != nil {
+		imageID = &image.ID
+	}
+
+	draft, err := rc.receiptService.CreateImportDraft(userID, upload, ctx.PostForm("ocrText"), imageID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Gagal melakukan analisis struk: " + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    draft,
+		"image":   image,
+	})
+}
+
+func (rc *ReceiptController) ConfirmImportHandler(ctx *gin.Context) {
+	user, _ := ctx.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid user ID"})
+		return
+	}
+
+	var req schema.ReceiptConfirmRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	items, err := rc.receiptService.ConfirmImport(userID, req.ReceiptID, req.Items)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrNothingToImport):
+			status = http.StatusBadRequest
+		case errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrReceiptNotOwned):
+			status = http.StatusNotFound
+		case errors.Is(err, repository.ErrReceiptAlreadyImported):
+			status = http.StatusConflict
+		}
+		ctx.JSON(status, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	// One refresh for the whole receipt instead of one per item
+	afterInventoryChange(rc.recipeService, userID)
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    items,
+	})
+}
+
+func (rc *ReceiptController) GetReceiptHistoryHandler(ctx *gin.Context) {
+	user, _ := ctx.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid user ID"})
+		return
+	}
+
+	page, err := utils.ParsePageRequest(ctx.Query("limit"), ctx.Query("cursor"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	receipts, pageInfo, err := rc.receiptService.GetReceiptHistory(userID, page)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Gagal mengambil riwayat struk"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       receipts,
+		"pagination": pageInfo,
+	})
+}
+
+func (rc *ReceiptController) GetReceiptDetailHandler(ctx *gin.Context) {
+	user, _ := ctx.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid user ID"})
+		return
+	}
+
+	receipt, err := rc.receiptService.GetReceiptDetail(ctx.Param("id"), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrReceiptNotOwned) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Struk tidak ditemukan"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    receipt,
+	})
+}
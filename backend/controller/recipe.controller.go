@@ -20,6 +20,8 @@ func NewRecipeController(recipeService *service.RecipeService) *RecipeController
 	}
 }
 
+// GetRecipesHandler returns the eight recipes last generated from the user's fridge. They are one
+// batch replaced on every generation, so the list is not paged.
 func (rc *RecipeController) GetRecipesHandler(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
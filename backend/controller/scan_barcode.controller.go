@@ -1,21 +1,26 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/google/uuid"
 )
 
 type ProductController struct {
-	productService service.ProductService
+	catalogService *service.CatalogService
 }
 
-func NewProductController(productService service.ProductService) *ProductController {
+func NewProductController(catalogService *service.CatalogService) *ProductController {
 	return &ProductController{
-		productService: productService,
+		catalogService: catalogService,
 	}
 }
 
@@ -32,7 +37,7 @@ func (pc *ProductController) GetProductInfoByBarcodeHandler(ctx *gin.Context) {
 
 	fmt.Printf("Menerima permintaan untuk barcode: %s\n", barcode)
 
-	productInfo, err := pc.productService.GetProductByBarcode(barcode)
+	productInfo, err := pc.catalogService.GetProductByBarcode(barcode)
 	if err != nil {
 		fmt.Printf("Error mendapatkan produk: %v\n", err)
 		ctx.JSON(http.StatusNotFound, gin.H{
@@ -44,12 +49,61 @@ func (pc *ProductController) GetProductInfoByBarcodeHandler(ctx *gin.Context) {
 
 	fmt.Printf("Produk ditemukan: %s\n", productInfo.Name)
 
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    productInfo,
+		"prefill": productInfo.ItemPrefill(today),
+	})
+}
+
+// SubmitProductHandler lets users add products Open Food Facts doesn't know, or fill in what a
+// catalog entry is missing. Fields the entry already has are kept.
+func (pc *ProductController) SubmitProductHandler(ctx *gin.Context) {
+	user, _ := ctx.Get("user")
+	userData := user.(middleware.JWTUserData)
+	userID, err := uuid.Parse(userData.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid user ID"})
+		return
+	}
+
+	var req schema.ProductSubmission
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	product, err := pc.catalogService.SubmitProduct(ctx.Param("barcode"), userID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidBarcode) || errors.Is(err, service.ErrProductNameless) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    product,
+	})
+}
+
+func (pc *ProductController) SearchProductsHandler(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	products, err := pc.catalogService.SearchProducts(ctx.Query("q"), limit)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": gin.H{
-			"name": productInfo.Name,
-			"barcode": barcode,
-		},
+		"data":    products,
 	})
 }
 
@@ -7,21 +7,27 @@ import (
 	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type OnboardingRequest struct {
-	DailyFoodCost  float64 `json:"dailyFoodCost"`
-	Age            int     `json:"age"`
-	BMI            float64 `json:"bmi"`
-	BloodSugar     int     `json:"bloodSugar"`
-	Cholesterol    int     `json:"cholesterol"`
-	BloodPressure  string  `json:"bloodPressure"`
-	DailyActivity  string  `json:"dailyActivity"`
-	HealthTarget   string  `json:"healthTarget"`
-	FridgeCapacity int     `json:"fridgeCapacity"`
-	FridgeModel    string  `json:"fridgeModel"`
+	DailyFoodCost   float64 `json:"dailyFoodCost"`
+	BudgetAwareMode *bool   `json:"budgetAwareMode"` // left unchanged when omitted
+	Age             int     `json:"age"`
+	BMI             float64 `json:"bmi"`
+	Height          float64 `json:"height"`
+	Weight          float64 `json:"weight"`
+	Sex             string  `json:"sex"`
+	BloodSugar      int     `json:"bloodSugar"`
+	Cholesterol     int     `json:"cholesterol"`
+	BloodPressure   string  `json:"bloodPressure"`
+	DailyActivity   string  `json:"dailyActivity"`
+	HealthTarget    string  `json:"healthTarget"`
+	FridgeCapacity  int     `json:"fridgeCapacity"`
+	FridgeModel     string  `json:"fridgeModel"`
+	Timezone        string  `json:"timezone"`
 }
 
 func OnboardingHandler(c *gin.Context) {
@@ -37,6 +43,13 @@ func OnboardingHandler(c *gin.Context) {
 
 	fmt.Printf("Received onboarding data: %+v\n", req)
 
+	if req.Timezone != "" {
+		if _, err := service.LoadTimezone(req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	var userPref schema.UserPreference
 	if err := database.DB.Where("user_id = ?", userID).First(&userPref).Error; err != nil {
 		// Create new preference if not found
@@ -46,8 +59,14 @@ func OnboardingHandler(c *gin.Context) {
 	}
 
 	userPref.DailyFoodCost = req.DailyFoodCost
+	if req.BudgetAwareMode != nil {
+		userPref.BudgetAwareMode = *req.BudgetAwareMode
+	}
 	userPref.Age = req.Age
 	userPref.BMI = req.BMI
+	userPref.Height = req.Height
+	userPref.Weight = req.Weight
+	userPref.Sex = req.Sex
 	userPref.BloodSugar = req.BloodSugar
 	userPref.Cholesterol = req.Cholesterol
 	userPref.BloodPressure = req.BloodPressure
@@ -55,6 +74,9 @@ func OnboardingHandler(c *gin.Context) {
 	userPref.HealthTarget = req.HealthTarget
 	userPref.FridgeCapacity = req.FridgeCapacity
 	userPref.FridgeModel = req.FridgeModel
+	if req.Timezone != "" {
+		userPref.Timezone = req.Timezone
+	}
 
 	if err := database.DB.Save(&userPref).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save onboarding data"})
@@ -87,6 +109,13 @@ func UpdateOnboardingDataHandler(c *gin.Context) {
 		return
 	}
 
+	if req.Timezone != "" {
+		if _, err := service.LoadTimezone(req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	var userPref schema.UserPreference
 	if err := database.DB.Where("user_id = ?", userID).First(&userPref).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User preference not found"})
@@ -94,8 +123,14 @@ func UpdateOnboardingDataHandler(c *gin.Context) {
 	}
 
 	userPref.DailyFoodCost = req.DailyFoodCost
+	if req.BudgetAwareMode != nil {
+		userPref.BudgetAwareMode = *req.BudgetAwareMode
+	}
 	userPref.Age = req.Age
 	userPref.BMI = req.BMI
+	userPref.Height = req.Height
+	userPref.Weight = req.Weight
+	userPref.Sex = req.Sex
 	userPref.BloodSugar = req.BloodSugar
 	userPref.Cholesterol = req.Cholesterol
 	userPref.BloodPressure = req.BloodPressure
@@ -103,6 +138,9 @@ func UpdateOnboardingDataHandler(c *gin.Context) {
 	userPref.HealthTarget = req.HealthTarget
 	userPref.FridgeCapacity = req.FridgeCapacity
 	userPref.FridgeModel = req.FridgeModel
+	if req.Timezone != "" {
+		userPref.Timezone = req.Timezone
+	}
 
 	if err := database.DB.Save(&userPref).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update onboarding data"})
@@ -111,3 +149,38 @@ func UpdateOnboardingDataHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Onboarding data updated successfully"})
 }
+
+func GetNutritionTargetsHandler(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	userData := userCtx.(middleware.JWTUserData)
+	userID, _ := uuid.Parse(userData.ID)
+
+	targets, err := service.GetNutritionTargets(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute nutrition targets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": targets})
+}
+
+// UpdateNutritionTargetsHandler stores the user's own targets, omitted fields use the computed value again
+func UpdateNutritionTargetsHandler(c *gin.Context) {
+	userCtx, _ := c.Get("user")
+	userData := userCtx.(middleware.JWTUserData)
+	userID, _ := uuid.Parse(userData.ID)
+
+	var req schema.NutritionTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targets, err := service.SetNutritionTargetOverride(userID, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save nutrition targets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": targets})
+}
@@ -24,6 +24,7 @@ func Init() {
 
 	if err := DB.AutoMigrate(
 		&schema.User{},
+		&schema.InventoryProduct{},
 		&schema.Item{},
 		&schema.UserActivity{},
 		&schema.UserPreference{},
@@ -31,9 +32,21 @@ func Init() {
 		&schema.UserActivityRecipeTag{},
 		&schema.Cart{},
 		&schema.CartItem{},
+		&schema.RestockRule{},
+		&schema.Receipt{},
+		&schema.ReceiptLine{},
+		&schema.PriceRecord{},
+		&schema.Product{},
+		&schema.NutritionTargetOverride{},
 		&schema.FoodJournal{},
+		&schema.FoodJournalRevision{},
 		&schema.GeneratedRecipe{},
+		&schema.StoredImage{},
+		&schema.FoodComposition{},
+		&schema.MealTemplate{},
 	); err != nil {
 		log.Println("AutoMigrate warning:", err)
 	}
+
+	seedFoodCompositions()
 }
\ No newline at end of file
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"log"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"gorm.io/gorm/clause"
+)
+
+type compositionSeed struct {
+	code     string
+	name     string
+	aliases  []string
+	category string
+	source   string
+	calories float64
+	protein  float64
+	fat      float64
+	carbs    float64
+	fiber    float64
+	sodium   float64
+}
+
+const (
+	tkpi   = schema.CompositionSourceTKPI
+	recipe = schema.CompositionSourceRecipe
+)
+
+// Per 100 g edible portion, after Tabel Komposisi Pangan Indonesia (TKPI 2017). Dishes the table
+// has no entry for use values of a typical home recipe. Codes are local to this table.
+var foodCompositionSeed = []compositionSeed{
+	// Serealia
+	{"SR001", "Nasi putih", []string{"nasi", "white rice"}, "serealia", tkpi, 180, 3.0, 0.3, 39.8, 0.2, 1},
+	{"SR002", "Nasi merah", []string{"brown rice"}, "serealia", tkpi, 149, 2.8, 0.4, 32.5, 0.3, 2},
+	{"SR003", "Nasi uduk", []string{"nasi lemak"}, "serealia", recipe, 189, 3.5, 4.0, 34.0, 0.3, 150},
+	{"SR004", "Nasi kuning", nil, "serealia", recipe, 185, 3.4, 3.6, 34.5, 0.4, 180},
+	{"SR005", "Nasi goreng", []string{"fried rice", "nasgor"}, "serealia", recipe, 250, 6.2, 9.5, 35.0, 0.6, 420},
+	{"SR006", "Lontong", nil, "serealia", tkpi, 144, 2.4, 0.2, 31.7, 0.3, 3},
+	{"SR007", "Ketupat", nil, "serealia", tkpi, 144, 2.4, 0.2, 31.7, 0.3, 3},
+	{"SR008", "Bubur nasi", []string{"bubur", "congee"}, "serealia", tkpi, 72, 1.2, 0.2, 15.8, 0.1, 2},
+	{"SR009", "Bubur ayam", nil, "hidangan", recipe, 105, 4.5, 2.8, 15.5, 0.3, 320},
+	{"SR010", "Roti putih", []string{"roti tawar", "white bread"}, "serealia", tkpi, 248, 8.0, 1.2, 50.0, 2.4, 530},
+	{"SR011", "Mi goreng", []string{"mie goreng", "fried noodles"}, "serealia", recipe, 205, 4.6, 8.6, 27.5, 1.2, 450},
+	{"SR012", "Mi ayam", []string{"mie ayam", "bakmi ayam"}, "hidangan", recipe, 120, 6.0, 4.0, 15.0, 0.8, 300},
+	{"SR013", "Mi instan, dimasak", []string{"indomie", "mie instan", "instant noodles"}, "serealia", recipe, 138, 2.9, 5.8, 18.8, 0.8, 390},
+	{"SR014", "Bihun goreng", []string{"fried vermicelli"}, "serealia", recipe, 190, 3.0, 6.5, 30.0, 0.8, 380},
+	{"SR015", "Jagung rebus", []string{"corn", "jagung"}, "serealia", tkpi, 108, 3.3, 1.2, 22.8, 2.9, 1},
+	{"SR016", "Oatmeal, dimasak", []string{"oat", "havermut"}, "serealia", recipe, 71, 2.5, 1.5, 12.0, 1.7, 4},
+	{"SR017", "Mi telur rebus", []string{"mie", "mi", "mie rebus", "boiled noodles"}, "serealia", recipe, 138, 4.5, 2.1, 25.2, 1.2, 5},
+
+	// Umbi
+	{"UM001", "Kentang rebus", []string{"kentang", "boiled potato"}, "umbi", tkpi, 86, 2.0, 0.1, 20.0, 1.8, 4},
+	{"UM002", "Kentang goreng", []string{"french fries", "fries"}, "umbi", recipe, 312, 3.4, 15.0, 41.0, 3.8, 210},
+	{"UM003", "Singkong rebus", []string{"singkong", "ubi kayu", "cassava"}, "umbi", tkpi, 146, 1.0, 0.3, 34.8, 0.9, 2},
+	{"UM004", "Ubi jalar rebus", []string{"ubi", "sweet potato"}, "umbi", tkpi, 123, 1.8, 0.7, 27.9, 2.3, 15},
+
+	// Kacang dan olahannya
+	{"KC001", "Tahu", []string{"tofu"}, "kacang", tkpi, 80, 10.9, 4.7, 0.8, 0.1, 2},
+	{"KC002", "Tahu goreng", []string{"fried tofu"}, "kacang", tkpi, 115, 9.7, 8.5, 2.5, 0.1, 12},
+	{"KC003", "Tempe", []string{"tempeh"}, "kacang", tkpi, 201, 20.8, 8.8, 13.5, 1.4, 9},
+	{"KC004", "Tempe goreng", []string{"fried tempeh"}, "kacang", tkpi, 350, 20.0, 28.0, 7.8, 1.2, 20},
+	{"KC005", "Tempe mendoan", []string{"mendoan"}, "jajanan", recipe, 240, 11.5, 15.0, 15.5, 1.0, 280},
+	{"KC006", "Kacang tanah goreng", []string{"peanuts"}, "kacang", tkpi, 580, 26.9, 49.4, 17.5, 2.4, 8},
+
+	// Sayur
+	{"SY001", "Bayam rebus", []string{"bayam", "spinach"}, "sayur", tkpi, 23, 2.8, 0.4, 3.4, 2.2, 70},
+	{"SY002", "Kangkung", []string{"water spinach"}, "sayur", tkpi, 28, 3.0, 0.3, 5.4, 2.0, 49},
+	{"SY003", "Tumis kangkung", []string{"cah kangkung"}, "hidangan", recipe, 80, 2.7, 5.5, 5.6, 2.0, 300},
+	{"SY004", "Wortel", []string{"carrot"}, "sayur", tkpi, 36, 1.0, 0.6, 7.9, 1.0, 70},
+	{"SY005", "Kubis", []string{"kol", "cabbage"}, "sayur", tkpi, 24, 1.4, 0.2, 5.3, 2.5, 18},
+	{"SY006", "Ketimun", []string{"timun", "mentimun", "cucumber"}, "sayur", tkpi, 8, 0.7, 0.1, 1.4, 0.5, 2},
+	{"SY007", "Tomat", []string{"tomato"}, "sayur", tkpi, 24, 1.3, 0.5, 4.2, 1.0, 5},
+	{"SY008", "Kacang panjang", []string{"long beans"}, "sayur", tkpi, 30, 2.7, 0.3, 5.3, 3.2, 4},
+	{"SY009", "Buncis", []string{"green beans"}, "sayur", tkpi, 34, 2.4, 0.3, 7.2, 3.2, 6},
+	{"SY010", "Sayur asem", []string{"sayur asam"}, "hidangan", recipe, 29, 0.7, 0.6, 5.0, 1.5, 200},
+	{"SY011", "Sayur lodeh", nil, "hidangan", recipe, 62, 1.4, 4.4, 4.6, 1.5, 250},
+	{"SY012", "Sayur sop", []string{"sop sayur", "sup sayur"}, "hidangan", recipe, 36, 1.5, 1.2, 5.0, 1.2, 280},
+	{"SY013", "Capcay", []string{"cap cay"}, "hidangan", recipe, 97, 4.5, 6.0, 6.5, 1.8, 380},
+	{"SY014", "Gado-gado", []string{"gado gado"}, "hidangan", tkpi, 137, 6.1, 3.2, 21.0, 3.0, 250},
+	{"SY015", "Pecel", []string{"nasi pecel sayur", "pecel sayur"}, "hidangan", recipe, 120, 5.0, 6.0, 12.0, 3.5, 300},
+	{"SY016", "Urap", []string{"urap sayur"}, "hidangan", recipe, 95, 3.0, 5.5, 9.0, 3.5, 180},
+
+	// Buah
+	{"BH001", "Pisang ambon", []string{"pisang", "banana"}, "buah", tkpi, 108, 1.0, 0.8, 24.3, 1.9, 10},
+	{"BH002", "Pepaya", []string{"papaya"}, "buah", tkpi, 46, 0.5, 0.0, 12.2, 1.6, 4},
+	{"BH003", "Mangga harum manis", []string{"mangga", "mango"}, "buah", tkpi, 52, 0.4, 0.2, 13.4, 1.6, 2},
+	{"BH004", "Semangka", []string{"watermelon"}, "buah", tkpi, 28, 0.5, 0.2, 6.9, 0.4, 7},
+	{"BH005", "Jeruk manis", []string{"jeruk", "orange"}, "buah", tkpi, 45, 0.9, 0.2, 11.2, 1.4, 4},
+	{"BH006", "Apel", []string{"apple"}, "buah", tkpi, 58, 0.3, 0.4, 14.9, 2.6, 2},
+	{"BH007", "Alpukat", []string{"avocado"}, "buah", tkpi, 85, 0.9, 6.5, 7.7, 1.4, 2},
+	{"BH008", "Salak", []string{"snake fruit"}, "buah", tkpi, 77, 0.4, 0.0, 20.9, 4.2, 1},
+	{"BH009", "Nanas", []string{"pineapple"}, "buah", tkpi, 40, 0.6, 0.3, 9.9, 0.6, 18},
+	{"BH010", "Pisang goreng", []string{"fried banana"}, "jajanan", recipe, 205, 1.6, 8.5, 31.0, 1.5, 60},
+
+	// Daging dan unggas
+	{"DG001", "Dada ayam rebus", []string{"ayam rebus", "chicken breast"}, "daging", recipe, 165, 31.0, 3.6, 0.0, 0.0, 74},
+	{"DG002", "Ayam goreng", []string{"fried chicken"}, "daging", recipe, 260, 25.0, 16.0, 3.0, 0.1, 450},
+	{"DG003", "Ayam bakar", []string{"grilled chicken"}, "daging", recipe, 210, 27.0, 10.0, 2.0, 0.0, 400},
+	{"DG004", "Opor ayam", nil, "hidangan", recipe, 163, 12.0, 11.5, 3.0, 0.3, 380},
+	{"DG005", "Soto ayam", []string{"soto"}, "hidangan", recipe, 70, 6.0, 3.5, 3.5, 0.3, 400},
+	{"DG006", "Sate ayam", []string{"chicken satay", "sate"}, "daging", recipe, 225, 21.5, 12.0, 7.5, 0.5, 500},
+	{"DG007", "Sate kambing", []string{"goat satay"}, "daging", recipe, 216, 20.0, 13.5, 3.5, 0.2, 450},
+	{"DG008", "Daging sapi", []string{"beef"}, "daging", tkpi, 201, 18.8, 14.0, 0.0, 0.0, 57},
+	{"DG009", "Rendang sapi", []string{"rendang"}, "daging", tkpi, 193, 22.6, 7.9, 7.8, 0.9, 470},
+	{"DG010", "Rawon", nil, "hidangan", recipe, 90, 7.5, 5.0, 3.5, 0.5, 420},
+	{"DG011", "Bakso", []string{"baso", "meatball soup"}, "hidangan", recipe, 95, 6.0, 4.5, 8.0, 0.3, 520},
+	{"DG012", "Gulai kambing", []string{"gule kambing"}, "hidangan", recipe, 140, 10.0, 10.0, 3.0, 0.3, 400},
+	{"DG013", "Sosis sapi", []string{"sosis", "sausage"}, "daging", tkpi, 452, 14.5, 42.0, 2.7, 0.0, 950},
+
+	// Ikan dan hasil laut
+	{"IK001", "Ikan tongkol", []string{"tongkol", "tuna"}, "ikan", tkpi, 117, 25.0, 1.5, 0.0, 0.0, 50},
+	{"IK002", "Ikan kembung", []string{"kembung", "mackerel"}, "ikan", tkpi, 112, 21.3, 3.4, 2.2, 0.0, 70},
+	{"IK003", "Ikan lele goreng", []string{"lele goreng", "pecel lele"}, "ikan", recipe, 240, 18.0, 17.5, 2.5, 0.0, 300},
+	{"IK004", "Ikan bandeng goreng", []string{"bandeng"}, "ikan", recipe, 230, 22.0, 15.0, 1.5, 0.0, 280},
+	{"IK005", "Ikan bakar", []string{"grilled fish"}, "ikan", recipe, 150, 24.0, 5.0, 2.0, 0.0, 350},
+	{"IK006", "Udang", []string{"shrimp", "prawn"}, "ikan", tkpi, 91, 21.0, 0.2, 0.1, 0.0, 185},
+	{"IK007", "Cumi-cumi", []string{"cumi", "squid"}, "ikan", tkpi, 75, 16.1, 0.7, 0.1, 0.0, 44},
+	{"IK008", "Pempek", []string{"empek-empek"}, "jajanan", recipe, 190, 8.0, 3.5, 31.0, 0.5, 420},
+	{"IK009", "Siomay", []string{"somay"}, "jajanan", recipe, 158, 9.0, 6.0, 17.0, 1.0, 430},
+
+	// Telur
+	{"TL001", "Telur ayam rebus", []string{"telur rebus", "boiled egg", "telur"}, "telur", tkpi, 154, 12.4, 10.8, 0.7, 0.0, 142},
+	{"TL002", "Telur ayam goreng", []string{"telur ceplok", "telur mata sapi", "fried egg"}, "telur", recipe, 196, 13.6, 15.3, 0.8, 0.0, 207},
+	{"TL003", "Telur dadar", []string{"omelet", "omelette"}, "telur", recipe, 188, 10.9, 14.5, 2.8, 0.0, 300},
+	{"TL004", "Telur bebek asin", []string{"telur asin", "salted egg"}, "telur", tkpi, 179, 13.6, 13.3, 1.4, 0.0, 483},
+
+	// Susu
+	{"SS001", "Susu sapi", []string{"susu", "milk"}, "susu", tkpi, 61, 3.2, 3.5, 4.3, 0.0, 36},
+	{"SS002", "Susu kental manis", []string{"skm", "condensed milk"}, "susu", tkpi, 343, 8.2, 10.0, 55.0, 0.0, 127},
+	{"SS003", "Keju", []string{"cheese"}, "susu", tkpi, 326, 22.8, 20.3, 13.1, 0.0, 1250},
+	{"SS004", "Yoghurt", []string{"yogurt"}, "susu", tkpi, 52, 3.3, 2.5, 4.0, 0.0, 40},
+
+	// Jajanan
+	{"JJ001", "Bakwan sayur", []string{"bakwan", "ote-ote", "bala-bala"}, "jajanan", recipe, 280, 5.0, 17.0, 27.0, 1.5, 310},
+	{"JJ002", "Tahu isi", []string{"tahu berontak"}, "jajanan", recipe, 250, 8.0, 16.0, 18.0, 1.2, 300},
+	{"JJ003", "Martabak manis", []string{"terang bulan", "kue bandung"}, "jajanan", recipe, 300, 6.5, 12.0, 42.0, 1.0, 250},
+	{"JJ004", "Martabak telur", []string{"martabak asin"}, "jajanan", recipe, 270, 11.0, 17.0, 18.0, 1.0, 480},
+	{"JJ005", "Kerupuk udang goreng", []string{"kerupuk", "krupuk"}, "jajanan", recipe, 530, 6.0, 32.0, 55.0, 0.5, 900},
+
+	// Bumbu dan minuman
+	{"BM001", "Sambal", []string{"sambal terasi", "chili sauce"}, "bumbu", recipe, 60, 1.8, 3.0, 7.5, 2.5, 650},
+	{"BM002", "Kecap manis", []string{"sweet soy sauce"}, "bumbu", tkpi, 71, 5.7, 1.3, 9.0, 0.6, 4000},
+	{"MN001", "Teh manis", []string{"es teh manis", "es teh", "sweet tea"}, "minuman", recipe, 36, 0.0, 0.0, 9.0, 0.0, 3},
+	{"MN002", "Kopi hitam", []string{"kopi", "kopi tubruk", "black coffee"}, "minuman", recipe, 2, 0.3, 0.0, 0.0, 0.0, 2},
+	{"MN003", "Kopi susu", []string{"es kopi susu", "kopi susu gula aren"}, "minuman", recipe, 60, 1.5, 2.0, 9.0, 0.0, 25},
+	{"MN004", "Jus jeruk", []string{"es jeruk", "orange juice"}, "minuman", recipe, 45, 0.7, 0.2, 10.4, 0.2, 1},
+	{"MN005", "Teh tawar", []string{"teh", "teh tanpa gula", "tea"}, "minuman", recipe, 1, 0.0, 0.0, 0.3, 0.0, 3},
+}
+
+// seedFoodCompositions loads the composition table and its trigram index, it is safe to run on every start
+func seedFoodCompositions() {
+	// Fuzzy search needs pg_trgm, without it grounding is skipped and the AI values are kept
+	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Println("pg_trgm warning:", err)
+	} else if err := DB.Exec("CREATE INDEX IF NOT EXISTS idx_food_compositions_search ON food_compositions USING gin (search_text gin_trgm_ops)").Error; err != nil {
+		log.Println("Food composition index warning:", err)
+	}
+
+	foods := FoodCompositionSeed()
+	err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "aliases", "category", "calories", "protein", "fat", "carbs", "fiber", "sodium", "source", "search_text"}),
+	}).Create(&foods).Error
+	if err != nil {
+		log.Println("Food composition seed warning:", err)
+	}
+}
+
+// FoodCompositionSeed is the composition table as it is seeded
+func FoodCompositionSeed() []schema.FoodComposition {
+	foods := make([]schema.FoodComposition, 0, len(foodCompositionSeed))
+	for _, seed := range foodCompositionSeed {
+		foods = append(foods, schema.FoodComposition{
+			Code:     seed.code,
+			Name:     seed.name,
+			Aliases:  seed.aliases,
+			Category: seed.category,
+			Calories: seed.calories,
+			Protein:  seed.protein,
+			Fat:      seed.fat,
+			Carbs:    seed.carbs,
+			Fiber:    seed.fiber,
+			Sodium:   seed.sodium,
+			Source:   seed.source,
+		})
+	}
+	return foods
+}
@@ -48,3 +48,37 @@ func JWTMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalJWTMiddleware sets the user when a valid token is sent and lets anonymous requests through
+func OptionalJWTMiddleware() gin.HandlerFunc {
+	jwtSecret := os.Getenv("JWT_SECRET")
+
+	return func(c *gin.Context) {
+		tokenStr, err := c.Cookie("token")
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (any, error) {
+			return []byte(jwtSecret), nil
+		})
+		if err != nil || !token.Valid {
+			c.Next()
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			c.Next()
+			return
+		}
+		id, _ := claims["id"].(string)
+		email, _ := claims["email"].(string)
+		name, _ := claims["name"].(string)
+		if id != "" {
+			c.Set("user", JWTUserData{ID: id, Email: email, Name: name})
+		}
+		c.Next()
+	}
+}
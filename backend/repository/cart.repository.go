@@ -1,18 +1,33 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func CreateCart(cart *schema.Cart) error {
 	return database.DB.Create(cart).Error
 }
 
-func GetAllCartsByUser(userID uuid.UUID) ([]schema.Cart, error) {
+func GetAllCartsByUser(userID uuid.UUID, page schema.PageRequest) ([]schema.Cart, error) {
 	var carts []schema.Cart
-	err := database.DB.Where("user_id = ?", userID).Find(&carts).Error
+	query := database.DB.Where("user_id = ?", userID)
+	if page.Cursor != nil {
+		if len(page.Cursor.Keys) != 1 {
+			return nil, utils.ErrInvalidCursor
+		}
+		query = query.Where("(created_at, id) < (?, ?)", page.Cursor.Keys[0], page.Cursor.ID)
+	}
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit + 1).
+		Find(&carts).Error
 	return carts, err
 }
 
@@ -23,7 +38,8 @@ func GetCartDetail(cartID uuid.UUID) (schema.Cart, error) {
 }
 
 func UpdateCart(cart *schema.Cart) error {
-	return database.DB.Save(cart).Error
+	// The restock flag is owned by the restock evaluator, not by client edits
+	return database.DB.Omit("IsRestock").Save(cart).Error
 }
 
 func GetCartItems(cartID uuid.UUID) ([]schema.CartItem, error) {
@@ -32,6 +48,102 @@ func GetCartItems(cartID uuid.UUID) ([]schema.CartItem, error) {
 	return cartItems, err
 }
 
+func CreateCartItem(cartItem *schema.CartItem) error {
+	return database.DB.Create(cartItem).Error
+}
+
+func UpdateCartItemAmount(cartItemID uuid.UUID, amount float64) error {
+	return database.DB.Model(&schema.CartItem{}).Where("id = ?", cartItemID).Update("amount", amount).Error
+}
+
+func DeleteCartItem(cartItemID uuid.UUID) error {
+	return database.DB.Delete(&schema.CartItem{}, "id = ?", cartItemID).Error
+}
+
+func GetCartItemByID(cartItemID uuid.UUID) (*schema.CartItem, error) {
+	var cartItem schema.CartItem
+	if err := database.DB.Preload("Cart").Where("id = ?", cartItemID).First(&cartItem).Error; err != nil {
+		return nil, err
+	}
+	return &cartItem, nil
+}
+
+func UpdateCartItemCheck(cartItemID uuid.UUID, checked bool, pricePaid *float64) error {
+	return database.DB.Model(&schema.CartItem{}).
+		Where("id = ?", cartItemID).
+		Updates(map[string]any{
+			"is_checked": checked,
+			"price_paid": pricePaid,
+		}).Error
+}
+
+func GetCheckedCartItems(cartID uuid.UUID) ([]schema.CartItem, error) {
+	var cartItems []schema.CartItem
+	err := database.DB.Where("cart_id = ? AND is_checked = ? AND is_purchased = ?", cartID, true, false).Find(&cartItems).Error
+	return cartItems, err
+}
+
+// PurchaseCartItems stores items[i] as the fridge batch bought for cartItems[i] and marks the
+// lines purchased, all in one transaction. The lines are locked first, and any line a concurrent
+// checkout already purchased is skipped; the lines and batches actually purchased are returned.
+func PurchaseCartItems(cartItems []schema.CartItem, items []schema.Item, purchasedAt time.Time) ([]schema.CartItem, []schema.Item, error) {
+	var purchasedLines []schema.CartItem
+	var purchasedItems []schema.Item
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		ids := make([]uuid.UUID, len(cartItems))
+		for i, cartItem := range cartItems {
+			ids[i] = cartItem.ID
+		}
+		var locked []schema.CartItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND is_purchased = ?", ids, false).
+			Find(&locked).Error; err != nil {
+			return err
+		}
+		open := make(map[uuid.UUID]bool, len(locked))
+		for _, line := range locked {
+			open[line.ID] = true
+		}
+
+		purchasedLines, purchasedItems = nil, nil
+		for i := range items {
+			if !open[cartItems[i].ID] {
+				continue
+			}
+			item := &items[i]
+			if item.ProductID == nil {
+				product, err := findOrCreateInventoryProduct(tx, item.UserID, item.Name, item.Type, item.AmountType)
+				if err != nil {
+					return err
+				}
+				item.ProductID = &product.ID
+			}
+
+			if err := tx.Create(item).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&schema.CartItem{}).
+				Where("id = ?", cartItems[i].ID).
+				Updates(map[string]any{
+					"is_purchased": true,
+					"purchased_at": purchasedAt,
+					"item_id":      item.ID,
+					"product_id":   item.ProductID,
+				}).Error; err != nil {
+				return err
+			}
+			purchasedLines = append(purchasedLines, cartItems[i])
+			purchasedItems = append(purchasedItems, *item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return purchasedLines, purchasedItems, nil
+}
+
 func DeleteCart(cartID uuid.UUID) error {
 	
 	tx := database.DB.Begin()
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"gorm.io/gorm/clause"
+)
+
+// SearchFoodCompositions returns the entries where the query matches a stretch of the name or aliases
+// (pg_trgm word similarity) at least minScore, best first. Callers rank them more closely.
+func SearchFoodCompositions(query string, minScore float64, limit int) ([]schema.FoodComposition, error) {
+	var foods []schema.FoodComposition
+	result := database.DB.
+		Where("word_similarity(?, search_text) >= ?", query, minScore).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "word_similarity(?, search_text) DESC, similarity(?, search_text) DESC",
+			Vars:               []interface{}{query, query},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&foods)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return foods, nil
+}
+
+func GetFoodCompositionByCode(code string) (*schema.FoodComposition, error) {
+	var food schema.FoodComposition
+	result := database.DB.Where("code = ?", code).First(&food)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &food, nil
+}
@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"gorm.io/gorm"
 )
 
 func GetAllFoodJournalByUserID(userID string) ([]schema.FoodJournal, error) {
@@ -25,10 +28,20 @@ func GetFoodJournalByID(journalID string) (*schema.FoodJournal, error) {
 	return &journal, nil
 }
 
-func GetTodayFoodJournalByUserID(userID string) ([]schema.FoodJournal, error) {
+// GetTodayFoodJournalByUserID returns the journals of the user's day, which runs from todayStart to todayEnd
+func GetTodayFoodJournalByUserID(userID string, todayStart, todayEnd time.Time) ([]schema.FoodJournal, error) {
 	var journals []schema.FoodJournal
-	today := time.Now().Format("2006-01-02")
-	result := database.DB.Where("user_id = ? AND DATE(created_at) = ?", userID, today).Order("created_at DESC").Find(&journals)
+	result := database.DB.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, todayStart, todayEnd).Order("created_at DESC").Find(&journals)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return journals, nil
+}
+
+// GetFoodJournalsBetween returns the entries eaten in [start, end), oldest first
+func GetFoodJournalsBetween(userID string, start, end time.Time) ([]schema.FoodJournal, error) {
+	var journals []schema.FoodJournal
+	result := database.DB.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, start, end).Order("created_at ASC").Find(&journals)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -44,37 +57,69 @@ func GetRecentFoodJournalByUserID(userID string, limit int) ([]schema.FoodJourna
 	return journals, nil
 }
 
-func GetFilteredFoodJournal(userID string, mealName string, startDate, endDate *time.Time, mealType string) ([]schema.FoodJournal, error) {
+// Nutrient filters map to these columns only
+var journalNutrientColumns = map[string]string{
+	"calories": "ai_calories",
+	"protein":  "ai_protein",
+	"carbs":    "ai_carbs",
+	"fat":      "ai_fat",
+	"sugar":    "ai_sugar",
+	"fiber":    "ai_fiber",
+	"sodium":   "ai_sodium",
+}
+
+// IsJournalNutrient reports whether the journal can be filtered on the nutrient
+func IsJournalNutrient(nutrient string) bool {
+	_, ok := journalNutrientColumns[nutrient]
+	return ok
+}
+
+// SearchFoodJournal returns one page of the user's journal, newest first. It loads one row more than
+// the limit so callers can tell whether another page follows.
+func SearchFoodJournal(userID string, search schema.FoodJournalSearch, page schema.PageRequest) ([]schema.FoodJournal, error) {
 	var journals []schema.FoodJournal
 
 	query := database.DB.Where("user_id = ?", userID)
 
-	if mealName != "" {
-		query = query.Where("meal_name ILIKE ?", "%"+mealName+"%")
+	if search.Query != "" {
+		pattern := "%" + search.Query + "%"
+		query = query.Where(`(meal_name ILIKE ? OR description ILIKE ? OR EXISTS (
+			SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(food_analysis->'detected_foods') = 'array'
+				THEN food_analysis->'detected_foods' ELSE '[]'::jsonb END) AS food
+			WHERE food->>'name' ILIKE ?))`, pattern, pattern, pattern)
 	}
 
-	if startDate != nil {
-		query = query.Where("created_at >= ?", *startDate)
+	if search.Start != nil {
+		query = query.Where("created_at >= ?", *search.Start)
 	}
 
-	if endDate != nil {
-		query = query.Where("created_at <= ?", *endDate)
+	if search.End != nil {
+		query = query.Where("created_at < ?", *search.End)
 	}
 
-	if mealType != "" && mealType != "all" {
-		query = query.Where("meal_type = ?", mealType)
+	if search.MealType != "" && search.MealType != "all" {
+		query = query.Where("meal_type = ?", search.MealType)
 	}
 
-	result := query.Order("created_at DESC").Find(&journals)
-	if result.Error != nil {
-		return nil, result.Error
+	for nutrient, value := range search.MinNutrient {
+		if column, ok := journalNutrientColumns[nutrient]; ok {
+			query = query.Where(column+" >= ?", value)
+		}
+	}
+	for nutrient, value := range search.MaxNutrient {
+		if column, ok := journalNutrientColumns[nutrient]; ok {
+			query = query.Where(column+" <= ?", value)
+		}
 	}
-	return journals, nil
-}
 
-func GetFoodJournalByMealType(userID string, mealType string) ([]schema.FoodJournal, error) {
-	var journals []schema.FoodJournal
-	result := database.DB.Where("user_id = ? AND meal_type = ?", userID, mealType).Order("created_at DESC").Find(&journals)
+	if page.Cursor != nil {
+		if len(page.Cursor.Keys) != 1 {
+			return nil, utils.ErrInvalidCursor
+		}
+		query = query.Where("(created_at, id) < (?, ?)", page.Cursor.Keys[0], page.Cursor.ID)
+	}
+
+	result := query.Order("created_at DESC, id DESC").Limit(page.Limit + 1).Find(&journals)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -90,22 +135,79 @@ func CreateNewFoodJournal(journal schema.FoodJournal, userID string) error {
 }
 
 func UpdateFoodJournal(journal schema.FoodJournal) error {
+	// Map updates skip serializers, the symptoms are stored as their JSON text
+	symptoms, err := json.Marshal(journal.Feelings.Symptoms)
+	if err != nil {
+		return err
+	}
+
 	return database.DB.Model(&schema.FoodJournal{}).
 		Where("id = ?", journal.ID).
 		Updates(map[string]any{
-			"meal_name":       journal.MealName,
-			"meal_type":       journal.MealType,
-			"description":     journal.Description,
-			"feeling_before":  journal.FeelingBefore,
-			"feeling_after":   journal.FeelingAfter,
-			"input_type":      journal.InputType,
-			"raw_input":       journal.RawInput,
-			"processed_input": journal.ProcessedInput,
-			"food_analysis":   journal.FoodAnalysis,
-			"updated_at":      journal.UpdatedAt,
+			"meal_name":                journal.MealName,
+			"meal_type":                journal.MealType,
+			"description":              journal.Description,
+			"feeling_before":           journal.FeelingBefore,
+			"feeling_after":            journal.FeelingAfter,
+			"feeling_before_code":      journal.Feelings.Before.Code,
+			"feeling_before_intensity": journal.Feelings.Before.Intensity,
+			"feeling_after_code":       journal.Feelings.After.Code,
+			"feeling_after_intensity":  journal.Feelings.After.Intensity,
+			"feeling_symptoms":         string(symptoms),
+			"input_type":               journal.InputType,
+			"raw_input":                journal.RawInput,
+			"processed_input":          journal.ProcessedInput,
+			"food_analysis":            journal.FoodAnalysis,
+			"updated_at":               journal.UpdatedAt,
 		}).Error
 }
 
+// UpdateFoodJournalAnalysis stores a new analysis for the entry and keeps the previous one as a revision
+func UpdateFoodJournalAnalysis(journal schema.FoodJournal, revision *schema.FoodJournalRevision) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&schema.FoodJournal{}).
+			Where("id = ?", journal.ID).
+			Updates(map[string]any{
+				"meal_name":                journal.MealName,
+				"description":              journal.Description,
+				"food_analysis":            journal.FoodAnalysis,
+				"ai_calories":              journal.AINutrition.Calories,
+				"ai_protein":               journal.AINutrition.Protein,
+				"ai_carbs":                 journal.AINutrition.Carbs,
+				"ai_fat":                   journal.AINutrition.Fat,
+				"ai_sugar":                 journal.AINutrition.Sugar,
+				"ai_fiber":                 journal.AINutrition.Fiber,
+				"ai_sodium":                journal.AINutrition.Sodium,
+				"ai_confidence":            journal.AINutrition.Confidence,
+				"ai_feedback":              journal.AIFeedback,
+				"rec_next_meal_suggestion": journal.AIRecommendations.NextMealSuggestion,
+				"rec_nutrition_tips":       journal.AIRecommendations.NutritionTips,
+				"rec_motivational_message": journal.AIRecommendations.MotivationalMessage,
+				"updated_at":               journal.UpdatedAt,
+			}).Error
+	})
+}
+
+func GetFoodJournalRevisions(journalID string, page schema.PageRequest) ([]schema.FoodJournalRevision, error) {
+	var revisions []schema.FoodJournalRevision
+	query := database.DB.Where("food_journal_id = ?", journalID)
+	if page.Cursor != nil {
+		if len(page.Cursor.Keys) != 1 {
+			return nil, utils.ErrInvalidCursor
+		}
+		query = query.Where("(created_at, id) < (?, ?)", page.Cursor.Keys[0], page.Cursor.ID)
+	}
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit + 1).
+		Find(&revisions).Error
+	return revisions, err
+}
+
 func DeleteFoodJournal(journalID string) error {
 	result := database.DB.Delete(&schema.FoodJournal{}, "id = ?", journalID)
 	if result.Error != nil {
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock to consume requested amount")
+
+func FindOrCreateInventoryProduct(userID uuid.UUID, name, itemType, amountType string) (*schema.InventoryProduct, error) {
+	return findOrCreateInventoryProduct(database.DB, userID, name, itemType, amountType)
+}
+
+func findOrCreateInventoryProduct(tx *gorm.DB, userID uuid.UUID, name, itemType, amountType string) (*schema.InventoryProduct, error) {
+	product := schema.InventoryProduct{
+		UserID:         userID,
+		Name:           name,
+		NormalizedName: utils.NormalizeName(name),
+		Type:           itemType,
+		AmountType:     amountType,
+	}
+
+	err := tx.
+		Where("user_id = ? AND normalized_name = ?", userID, product.NormalizedName).
+		Attrs(product).
+		FirstOrCreate(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// LinkUnassignedItems attaches items created before batches existed to their product. New items are
+// linked when they are created, so after the first start this finds nothing.
+func LinkUnassignedItems() error {
+	var items []schema.Item
+	if err := database.DB.Where("product_id IS NULL").Find(&items).Error; err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		product, err := findOrCreateInventoryProduct(database.DB, item.UserID, item.Name, item.Type, item.AmountType)
+		if err != nil {
+			return err
+		}
+		if err := database.DB.Model(&schema.Item{}).Where("id = ?", item.ID).Update("product_id", product.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetInventoryProductByID(productID string) (*schema.InventoryProduct, error) {
+	var product schema.InventoryProduct
+	result := database.DB.Where("id = ?", productID).First(&product)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &product, nil
+}
+
+func GetAllInventoryProductByUserID(userID string) ([]schema.InventoryProduct, error) {
+	var products []schema.InventoryProduct
+	result := database.DB.
+		Preload("Batches", func(db *gorm.DB) *gorm.DB {
+			return db.Order(effectiveExpDateColumn + " ASC")
+		}).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&products)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return products, nil
+}
+
+func GetInventoryProductsByUserID(userID uuid.UUID) ([]schema.InventoryProduct, error) {
+	var products []schema.InventoryProduct
+	err := database.DB.Where("user_id = ?", userID).Find(&products).Error
+	return products, err
+}
+
+// CreateItemBatches stores several batches at once, linking each to its product
+func CreateItemBatches(items []schema.Item) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return createItemBatches(tx, items)
+	})
+}
+
+func createItemBatches(tx *gorm.DB, items []schema.Item) error {
+	for i := range items {
+		item := &items[i]
+		if item.ProductID == nil {
+			product, err := findOrCreateInventoryProduct(tx, item.UserID, item.Name, item.Type, item.AmountType)
+			if err != nil {
+				return err
+			}
+			item.ProductID = &product.ID
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetBatchesByProductID(productID string) ([]schema.Item, error) {
+	var items []schema.Item
+	result := database.DB.Where("product_id = ?", productID).Order(effectiveExpDateColumn + " ASC").Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+// ConsumeProductFIFO depletes fresh batches starting from the earliest-expiring one.
+// Emptied batches are deleted, the rest are returned with their remaining amount.
+func ConsumeProductFIFO(productID string, amount float64, now time.Time) ([]schema.Item, error) {
+	var touched []schema.Item
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var batches []schema.Item
+		// Locked so a concurrent consume waits instead of spending the same batches
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ?", productID).
+			Where(effectiveExpDateColumn+" > ?", now).
+			Order(effectiveExpDateColumn + " ASC").
+			Find(&batches).Error; err != nil {
+			return err
+		}
+
+		var available float64
+		for _, batch := range batches {
+			available += batch.Amount
+		}
+		if available < amount {
+			return ErrInsufficientStock
+		}
+
+		remaining := amount
+		for _, batch := range batches {
+			if remaining <= 0 {
+				break
+			}
+
+			if batch.Amount <= remaining {
+				remaining -= batch.Amount
+				if err := tx.Delete(&schema.Item{}, "id = ?", batch.ID).Error; err != nil {
+					return err
+				}
+				batch.Amount = 0
+			} else {
+				batch.Amount -= remaining
+				remaining = 0
+				if err := tx.Model(&schema.Item{}).Where("id = ?", batch.ID).Update("amount", batch.Amount).Error; err != nil {
+					return err
+				}
+			}
+			touched = append(touched, batch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return touched, nil
+}
+
+// FindUnopenedBatch finds a batch of the product bought and expiring on the same days, which a new
+// purchase can be added to instead of starting another batch
+func FindUnopenedBatch(productID uuid.UUID, startDate, expDate time.Time) (*schema.Item, error) {
+	var item schema.Item
+	result := database.DB.
+		Where("product_id = ? AND start_date = ? AND exp_date = ? AND opened_at IS NULL", productID, startDate, expDate).
+		First(&item)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &item, nil
+}
+
+func IncrementItemAmount(itemID uuid.UUID, amount float64) error {
+	return database.DB.Model(&schema.Item{}).Where("id = ?", itemID).Update("amount", gorm.Expr("amount + ?", amount)).Error
+}
@@ -6,91 +6,75 @@ import (
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 )
 
-func GetAllItemByUserID(userID string) ([]schema.Item, error) {
-	var items []schema.Item
-	result := database.DB.Where("user_id = ?", userID).Find(&items).Order("exp_date ASC")
+// Opened items expire on effective_exp_date, unopened ones on exp_date. Both hold the user's local
+// wall-clock time as UTC, so expiry checks take the user's now from service.UserClock.
+const effectiveExpDateColumn = "COALESCE(effective_exp_date, exp_date)"
+
+func GetItemByID(itemID string) (*schema.Item, error) {
+	var item schema.Item
+	result := database.DB.Where("id = ?", itemID).First(&item)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return items, nil
+	return &item, nil
 }
 
-func GetAllFreshItem(userID string) ([]schema.Item, error) {
+// GetAllFreshItem returns every batch that has not expired yet, soonest expiry first
+func GetAllFreshItem(userID string, now time.Time) ([]schema.Item, error) {
 	var items []schema.Item
-	result := database.DB.Where("user_id = ?", userID).Where("exp_date > ?", time.Now().UTC()).Find(&items).Order("exp_date ASC")
+	result := database.DB.Where("user_id = ?", userID).Where(effectiveExpDateColumn+" > ?", now).Order(effectiveExpDateColumn + " ASC").Find(&items)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return items, nil
 }
 
-func GetFilteredFreshItem(userID string, name string, startDate, expDate *time.Time, itemType string) ([]schema.Item, error) {
-	var items []schema.Item
-
-	query := database.DB.
-		Where("user_id = ?", userID).
-		Where("exp_date > ?", time.Now().UTC())
-
-	if name != "" {
-		query = query.Where("name ILIKE ?", "%"+name+"%")
+// SearchItems returns the user's batches matching search. Expired batches come latest expiry first,
+// the others soonest expiry first. With a page it returns that page plus one extra row to tell whether
+// another page follows, without one every match.
+func SearchItems(userID string, search schema.ItemSearch, now time.Time, page *schema.PageRequest) ([]schema.Item, error) {
+	query := database.DB.Where("user_id = ?", userID)
+
+	order, after := "ASC", ">"
+	switch search.State {
+	case schema.ItemStateFresh:
+		query = query.Where(effectiveExpDateColumn+" > ?", now)
+	case schema.ItemStateExpired:
+		query = query.Where(effectiveExpDateColumn+" < ?", now)
+		order, after = "DESC", "<"
 	}
 
-	if startDate != nil {
-		query = query.Where("start_date >= ?", *startDate)
+	if search.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+search.Name+"%")
 	}
 
-	if expDate != nil {
-		query = query.Where("exp_date <= ?", *expDate)
+	if search.Start != nil {
+		query = query.Where("start_date >= ?", *search.Start)
 	}
 
-	if itemType != "" && itemType != "Semua" {
-		query = query.Where("type = ?", strings.ToLower(itemType))
+	if search.Exp != nil {
+		query = query.Where("exp_date <= ?", *search.Exp)
 	}
 
-	result := query.Order("exp_date ASC").Find(&items)
-
-	if result.Error != nil {
-		return nil, result.Error
+	if search.Type != "" && search.Type != "Semua" {
+		query = query.Where("type = ?", strings.ToLower(search.Type))
 	}
-	return items, nil
-}
 
-func GetAllExpiredItem(userID string) ([]schema.Item, error) {
-	var items []schema.Item
-	result := database.DB.Where("user_id = ?", userID).Where("exp_date < ?", time.Now().UTC()).Find(&items).Order("exp_date DESC")
-	if result.Error != nil {
-		return nil, result.Error
+	if page != nil {
+		if page.Cursor != nil {
+			if len(page.Cursor.Keys) != 1 {
+				return nil, utils.ErrInvalidCursor
+			}
+			query = query.Where("("+effectiveExpDateColumn+", id) "+after+" (?, ?)", page.Cursor.Keys[0], page.Cursor.ID)
+		}
+		query = query.Limit(page.Limit + 1)
 	}
-	return items, nil
-}
 
-func GetFilteredExpiredItem(userID string, name string, startDate, expDate *time.Time, itemType string) ([]schema.Item, error) {
 	var items []schema.Item
-
-	query := database.DB.
-		Where("user_id = ?", userID).
-		Where("exp_date < ?", time.Now().UTC())
-
-	if name != "" {
-		query = query.Where("name ILIKE ?", "%"+name+"%")
-	}
-
-	if startDate != nil {
-		query = query.Where("start_date >= ?", *startDate)
-	}
-
-	if expDate != nil {
-		query = query.Where("exp_date <= ?", *expDate)
-	}
-
-	if itemType != "" && itemType != "Semua" {
-		query = query.Where("type = ?", strings.ToLower(itemType))
-	}
-
-	result := query.Order("exp_date DESC").Find(&items)
-
+	result := query.Order(effectiveExpDateColumn + " " + order + ", id " + order).Find(&items)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -109,13 +93,24 @@ func UpdateItem(item schema.Item) error {
 	return database.DB.Model(&schema.Item{}).
 		Where("id = ?", item.ID).
 		Updates(map[string]any{
-			"name":        item.Name,
-			"type":        item.Type,
-			"amount":      item.Amount,
-			"amount_type": item.AmountType,
-			"desc":        item.Desc,
-			"start_date":  item.StartDate,
-			"exp_date":    item.ExpDate,
+			"product_id":         item.ProductID,
+			"name":               item.Name,
+			"type":               item.Type,
+			"amount":             item.Amount,
+			"amount_type":        item.AmountType,
+			"desc":               item.Desc,
+			"start_date":         item.StartDate,
+			"exp_date":           item.ExpDate,
+			"effective_exp_date": item.EffectiveExpDate,
+		}).Error
+}
+
+func UpdateItemOpenedState(itemID string, openedAt *time.Time, effectiveExpDate *time.Time) error {
+	return database.DB.Model(&schema.Item{}).
+		Where("id = ?", itemID).
+		Updates(map[string]any{
+			"opened_at":          openedAt,
+			"effective_exp_date": effectiveExpDate,
 		}).Error
 }
 
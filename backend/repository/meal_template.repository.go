@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"gorm.io/gorm"
+)
+
+func CreateMealTemplate(template *schema.MealTemplate) error {
+	return database.DB.Create(template).Error
+}
+
+// GetMealTemplatesByUserID lists the user's templates, most used first
+func GetMealTemplatesByUserID(userID string) ([]schema.MealTemplate, error) {
+	var templates []schema.MealTemplate
+	result := database.DB.Where("user_id = ?", userID).
+		Order("use_count DESC, last_used_at DESC NULLS LAST, created_at DESC").
+		Find(&templates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return templates, nil
+}
+
+func GetMealTemplateByID(templateID string) (*schema.MealTemplate, error) {
+	var template schema.MealTemplate
+	result := database.DB.Where("id = ?", templateID).First(&template)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &template, nil
+}
+
+func UpdateMealTemplate(template *schema.MealTemplate) error {
+	return database.DB.Model(&schema.MealTemplate{}).
+		Where("id = ?", template.ID).
+		Updates(map[string]any{
+			"name":        template.Name,
+			"meal_type":   template.MealType,
+			"description": template.Description,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+func DeleteMealTemplate(templateID string) error {
+	return database.DB.Delete(&schema.MealTemplate{}, "id = ?", templateID).Error
+}
+
+// LogMealTemplate creates the journal entry and counts the use of the template together
+func LogMealTemplate(journal *schema.FoodJournal, templateID string, usedAt time.Time) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(journal).Error; err != nil {
+			return err
+		}
+		return tx.Model(&schema.MealTemplate{}).
+			Where("id = ?", templateID).
+			Updates(map[string]any{
+				"use_count":    gorm.Expr("use_count + 1"),
+				"last_used_at": usedAt,
+			}).Error
+	})
+}
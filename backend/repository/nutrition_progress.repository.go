@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+// NutritionLimits decide whether a day counts as within target
+type NutritionLimits struct {
+	MinCalories float64
+	MaxCalories float64
+	MaxSugar    float64
+	MaxSodium   float64
+}
+
+// Per-day totals of the user's journal, days follow the user's time zone
+const nutritionDays = `days AS (
+	SELECT (created_at AT TIME ZONE @timezone)::date AS day, COUNT(*) AS meals,
+		SUM(ai_calories) AS calories, SUM(ai_protein) AS protein, SUM(ai_carbs) AS carbs, SUM(ai_fat) AS fat,
+		SUM(ai_sugar) AS sugar, SUM(ai_fiber) AS fiber, SUM(ai_sodium) AS sodium
+	FROM food_journals
+	WHERE user_id = @user AND created_at >= @start AND created_at < @end
+	GROUP BY 1
+)`
+
+const withinTarget = `(calories BETWEEN @min_calories AND @max_calories AND sugar <= @max_sugar AND sodium <= @max_sodium)`
+
+// Rolling averages look back over this range, per bucket period
+var nutritionRollingWindows = map[string]string{
+	"day":  "6 days",
+	"week": "3 weeks",
+}
+
+func nutritionParams(userID uuid.UUID, start, end time.Time, timezone string, limits NutritionLimits) map[string]any {
+	return map[string]any{
+		"user":         userID,
+		"timezone":     timezone,
+		"start":        start,
+		"end":          end,
+		"min_calories": limits.MinCalories,
+		"max_calories": limits.MaxCalories,
+		"max_sugar":    limits.MaxSugar,
+		"max_sodium":   limits.MaxSodium,
+	}
+}
+
+// GetNutritionBuckets returns the intake per day or week between start (inclusive) and end (exclusive),
+// with rolling averages over the preceding logged buckets
+func GetNutritionBuckets(userID uuid.UUID, start, end time.Time, timezone, period string, limits NutritionLimits) ([]schema.NutritionBucket, error) {
+	window, ok := nutritionRollingWindows[period]
+	if !ok {
+		return nil, fmt.Errorf("unsupported period %q", period)
+	}
+
+	params := nutritionParams(userID, start, end, timezone, limits)
+	params["period"] = period
+
+	var buckets []schema.NutritionBucket
+	err := database.DB.Raw(`
+		WITH `+nutritionDays+`,
+		buckets AS (
+			SELECT date_trunc(@period, day)::date AS period_start, COUNT(*) AS days_logged, SUM(meals) AS meals,
+				AVG(calories) AS calories, AVG(protein) AS protein, AVG(carbs) AS carbs, AVG(fat) AS fat,
+				AVG(sugar) AS sugar, AVG(fiber) AS fiber, AVG(sodium) AS sodium
+			FROM days
+			GROUP BY 1
+		)
+		SELECT *,
+			AVG(calories) OVER w AS rolling_calories, AVG(protein) OVER w AS rolling_protein,
+			AVG(carbs) OVER w AS rolling_carbs, AVG(fat) OVER w AS rolling_fat,
+			`+withinTarget+` AS within_target
+		FROM buckets
+		WINDOW w AS (ORDER BY period_start RANGE BETWEEN INTERVAL '`+window+`' PRECEDING AND CURRENT ROW)
+		ORDER BY period_start`, params).Scan(&buckets).Error
+	return buckets, err
+}
+
+// GetNutritionByMealType totals the intake per meal type
+func GetNutritionByMealType(userID uuid.UUID, start, end time.Time) ([]schema.MealTypeNutrition, error) {
+	var mealTypes []schema.MealTypeNutrition
+	err := database.DB.Raw(`
+		SELECT COALESCE(NULLIF(meal_type, ''), 'other') AS meal_type, COUNT(*) AS meals,
+			SUM(ai_calories) AS calories, SUM(ai_protein) AS protein, SUM(ai_carbs) AS carbs, SUM(ai_fat) AS fat,
+			SUM(ai_sugar) AS sugar, SUM(ai_fiber) AS fiber, SUM(ai_sodium) AS sodium,
+			COALESCE(100 * SUM(ai_calories) / NULLIF(SUM(SUM(ai_calories)) OVER (), 0), 0) AS calories_share,
+			AVG(ai_calories) AS avg_meal_calories
+		FROM food_journals
+		WHERE user_id = @user AND created_at >= @start AND created_at < @end
+		GROUP BY 1
+		ORDER BY calories DESC`, map[string]any{"user": userID, "start": start, "end": end}).Scan(&mealTypes).Error
+	return mealTypes, err
+}
+
+// GetNutritionStreak finds runs of consecutive days within target. The current run must reach
+// today or yesterday, since today may not be fully logged yet.
+func GetNutritionStreak(userID uuid.UUID, start, end time.Time, today, timezone string, limits NutritionLimits) (schema.NutritionStreak, error) {
+	params := nutritionParams(userID, start, end, timezone, limits)
+	params["today"] = today
+
+	var streak schema.NutritionStreak
+	err := database.DB.Raw(`
+		WITH `+nutritionDays+`,
+		islands AS (
+			SELECT day, day - (ROW_NUMBER() OVER (ORDER BY day))::int AS run
+			FROM days
+			WHERE `+withinTarget+`
+		),
+		runs AS (
+			SELECT MAX(day) AS last_day, COUNT(*) AS run_length
+			FROM islands
+			GROUP BY run
+		)
+		SELECT COALESCE(MAX(run_length) FILTER (WHERE last_day >= @today::date - 1), 0) AS current,
+			COALESCE(MAX(run_length), 0) AS longest,
+			COALESCE(SUM(run_length), 0) AS days_in_range
+		FROM runs`, params).Scan(&streak).Error
+	return streak, err
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetNutritionTargetOverride returns the user's overrides, or nil when none were set
+func GetNutritionTargetOverride(userID uuid.UUID) (*schema.NutritionTargetOverride, error) {
+	var override schema.NutritionTargetOverride
+	err := database.DB.Where("user_id = ?", userID).First(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func SaveNutritionTargetOverride(override *schema.NutritionTargetOverride) error {
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"calories", "protein", "carbs", "fat", "sugar", "fiber", "sodium", "updated_at"}),
+	}).Create(override).Error
+}
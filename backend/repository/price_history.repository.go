@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+func CreatePriceRecords(records []schema.PriceRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return database.DB.Create(&records).Error
+}
+
+// GetPriceRecordsByProduct returns a product's price history, oldest first. Records taken before
+// the product existed are matched by normalized name.
+func GetPriceRecordsByProduct(userID, productID uuid.UUID, normalizedName string) ([]schema.PriceRecord, error) {
+	var records []schema.PriceRecord
+	result := database.DB.
+		Where("user_id = ? AND (product_id = ? OR normalized_name = ?)", userID, productID, normalizedName).
+		Order("recorded_at ASC").
+		Find(&records)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return records, nil
+}
+
+// GetLatestStorePrices returns the most recent price of every product at every store the user bought it
+func GetLatestStorePrices(userID uuid.UUID) ([]schema.LatestPrice, error) {
+	var prices []schema.LatestPrice
+	err := database.DB.Raw(`
+		SELECT DISTINCT ON (normalized_name, unit, store_name)
+			normalized_name, store_name, unit, unit_price, recorded_at
+		FROM price_records
+		WHERE user_id = ? AND unit_price > 0
+		ORDER BY normalized_name, unit, store_name, recorded_at DESC`, userID).Scan(&prices).Error
+	return prices, err
+}
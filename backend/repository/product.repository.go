@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetProductByBarcode(barcode string) (*schema.Product, error) {
+	var product schema.Product
+	result := database.DB.Where("barcode = ?", barcode).First(&product)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &product, nil
+}
+
+// UpsertProduct stores a product refreshed from Open Food Facts, replacing what the catalog had
+// for its barcode. The product is reloaded so it carries the ID of the existing row.
+func UpsertProduct(product *schema.Product) error {
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "barcode"}},
+		UpdateAll: true,
+	}).Create(product).Error
+	if err != nil {
+		return err
+	}
+	return reloadProduct(product)
+}
+
+// Columns a user submission may fill in on an existing product
+var (
+	productTextColumns   = []string{"name", "brand", "quantity", "amount_type", "item_type", "ingredients_text", "image_url"}
+	productNumberColumns = []string{"package_amount", "nutri_energy_kcal", "nutri_proteins", "nutri_carbohydrates", "nutri_sugars",
+		"nutri_fat", "nutri_saturated_fat", "nutri_fiber", "nutri_salt", "nutri_sodium"}
+	productListColumns = []string{"categories", "allergens"}
+)
+
+// FillProduct stores a user-submitted product. The catalog is shared, so for a barcode it already
+// has only the empty fields are filled in and what is there is kept. The product is reloaded.
+func FillProduct(product *schema.Product) error {
+	var set clause.Set
+	for _, column := range productTextColumns {
+		set = append(set, clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("COALESCE(NULLIF(products." + column + ", ''), excluded." + column + ")"),
+		})
+	}
+	for _, column := range productNumberColumns {
+		set = append(set, clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("CASE WHEN COALESCE(products." + column + ", 0) = 0 THEN excluded." + column + " ELSE products." + column + " END"),
+		})
+	}
+	for _, column := range productListColumns {
+		set = append(set, clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("CASE WHEN COALESCE(products." + column + ", '') IN ('', '[]', 'null') THEN excluded." + column + " ELSE products." + column + " END"),
+		})
+	}
+	set = append(set, clause.Assignment{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")})
+
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "barcode"}},
+		DoUpdates: set,
+	}).Create(product).Error
+	if err != nil {
+		return err
+	}
+	return reloadProduct(product)
+}
+
+// reloadProduct reads the stored row of the product's barcode. It is read into a fresh value, the
+// generated ID of a conflicting insert would otherwise become part of the condition.
+func reloadProduct(product *schema.Product) error {
+	var stored schema.Product
+	if err := database.DB.Where("barcode = ?", product.Barcode).First(&stored).Error; err != nil {
+		return err
+	}
+	*product = stored
+	return nil
+}
+
+// SearchProducts matches the query against product name and brand, or a barcode prefix
+func SearchProducts(query string, limit int) ([]schema.Product, error) {
+	var products []schema.Product
+	result := database.DB.
+		Where("name ILIKE ? OR brand ILIKE ? OR barcode LIKE ?", "%"+query+"%", "%"+query+"%", query+"%").
+		Order("name ASC").
+		Limit(limit).
+		Find(&products)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return products, nil
+}
+
+func GetProductByID(productID string) (*schema.Product, error) {
+	var product schema.Product
+	result := database.DB.Where("id = ?", productID).First(&product)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &product, nil
+}
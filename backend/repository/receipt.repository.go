@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrReceiptAlreadyImported = errors.New("receipt has already been imported")
+
+// CreateReceipt saves the receipt together with its lines
+func CreateReceipt(receipt *schema.Receipt) error {
+	return database.DB.Create(receipt).Error
+}
+
+// GetReceiptsByUserID returns one page of imported receipts, latest purchase first, plus one extra row to tell
+// whether another page follows
+func GetReceiptsByUserID(userID uuid.UUID, page schema.PageRequest) ([]schema.Receipt, error) {
+	var receipts []schema.Receipt
+	query := database.DB.Where("user_id = ? AND NOT is_draft", userID)
+	if page.Cursor != nil {
+		if len(page.Cursor.Keys) != 2 {
+			return nil, utils.ErrInvalidCursor
+		}
+		query = query.Where("(purchase_date, created_at, id) < (?, ?, ?)", page.Cursor.Keys[0], page.Cursor.Keys[1], page.Cursor.ID)
+	}
+	err := query.
+		Order("purchase_date DESC, created_at DESC, id DESC").
+		Limit(page.Limit + 1).
+		Find(&receipts).Error
+	return receipts, err
+}
+
+func GetReceiptByID(receiptID string) (*schema.Receipt, error) {
+	var receipt schema.Receipt
+	if err := database.DB.Preload("Lines").Where("id = ?", receiptID).First(&receipt).Error; err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// ImportReceipt stores the fridge batches of a confirmed receipt import, turns the draft receipt
+// into history and records its prices, all in one transaction. receipt is nil when the import
+// has no saved receipt. Prices of lines not yet linked to a product take the product of the
+// batch with the same name.
+func ImportReceipt(items []schema.Item, receipt *schema.Receipt, prices []schema.PriceRecord) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := createItemBatches(tx, items); err != nil {
+			return err
+		}
+		if receipt == nil {
+			return nil
+		}
+
+		result := tx.Model(&schema.Receipt{}).
+			Where("id = ? AND is_draft", receipt.ID).
+			Update("is_draft", false)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReceiptAlreadyImported
+		}
+		receipt.IsDraft = false
+
+		if len(prices) == 0 {
+			return nil
+		}
+		productIDs := make(map[string]*uuid.UUID, len(items))
+		for _, item := range items {
+			productIDs[utils.NormalizeName(item.Name)] = item.ProductID
+		}
+		for i := range prices {
+			if prices[i].ProductID == nil {
+				prices[i].ProductID = productIDs[prices[i].NormalizedName]
+			}
+		}
+		return tx.Create(&prices).Error
+	})
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+const RestockCartName = "Restock"
+
+// UpsertRestockRule creates or replaces the product's rule and loads the stored row into rule,
+// which on conflict keeps its original ID
+func UpsertRestockRule(rule *schema.RestockRule) error {
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_quantity", "restock_quantity", "updated_at"}),
+	}).Create(rule).Error
+	if err != nil {
+		return err
+	}
+
+	var stored schema.RestockRule
+	if err := database.DB.Where("product_id = ?", rule.ProductID).First(&stored).Error; err != nil {
+		return err
+	}
+	*rule = stored
+	return nil
+}
+
+func DeleteRestockRule(productID string) error {
+	return database.DB.Delete(&schema.RestockRule{}, "product_id = ?", productID).Error
+}
+
+func GetRestockRulesByUserID(userID uuid.UUID) ([]schema.RestockRule, error) {
+	var rules []schema.RestockRule
+	err := database.DB.Preload("Product").Where("user_id = ?", userID).Find(&rules).Error
+	return rules, err
+}
+
+// GetFreshStockByProduct sums the non-expired batch amounts of every product the user has
+func GetFreshStockByProduct(userID uuid.UUID, now time.Time) (map[uuid.UUID]float64, error) {
+	var rows []struct {
+		ProductID uuid.UUID
+		Total     float64
+	}
+	err := database.DB.Model(&schema.Item{}).
+		Select("product_id, SUM(amount) AS total").
+		Where("user_id = ? AND product_id IS NOT NULL", userID).
+		Where(effectiveExpDateColumn+" > ?", now).
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stock := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		stock[row.ProductID] = row.Total
+	}
+	return stock, nil
+}
+
+func FindOrCreateRestockCart(userID uuid.UUID) (*schema.Cart, error) {
+	cart := schema.Cart{
+		UserID:    userID,
+		Name:      RestockCartName,
+		IsRestock: true,
+	}
+	err := database.DB.
+		Where("user_id = ? AND is_restock = ?", userID, true).
+		Attrs(cart).
+		FirstOrCreate(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func GetCartItemsByProduct(cartID uuid.UUID) (map[uuid.UUID]schema.CartItem, error) {
+	var cartItems []schema.CartItem
+	if err := database.DB.Where("cart_id = ? AND product_id IS NOT NULL AND is_purchased = ?", cartID, false).Find(&cartItems).Error; err != nil {
+		return nil, err
+	}
+
+	byProduct := make(map[uuid.UUID]schema.CartItem, len(cartItems))
+	for _, cartItem := range cartItems {
+		byProduct[*cartItem.ProductID] = cartItem
+	}
+	return byProduct, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+// Lines of imported receipts and priced cart purchases, as one spending table
+const spendingSource = `(
+	SELECT r.purchase_date AS spent_at, r.store_name AS store, l.type AS category, l.line_total AS amount
+	FROM receipt_lines l
+	JOIN receipts r ON r.id = l.receipt_id
+	WHERE r.user_id = @user AND NOT r.is_draft AND r.purchase_date >= @start AND r.purchase_date < @end
+	UNION ALL
+	SELECT ci.purchased_at, '', ci.type, ci.price_paid
+	FROM cart_items ci
+	JOIN carts c ON c.id = ci.cart_id
+	WHERE c.user_id = @user AND ci.is_purchased AND ci.price_paid IS NOT NULL
+		AND ci.purchased_at >= @start AND ci.purchased_at < @end
+) AS spending`
+
+func spendingParams(userID uuid.UUID, start, end time.Time) map[string]any {
+	return map[string]any{"user": userID, "start": start, "end": end}
+}
+
+// GetSpendingByPeriod totals spending per day, week or month between start (inclusive) and end (exclusive)
+func GetSpendingByPeriod(userID uuid.UUID, start, end time.Time, period string) ([]schema.SpendingBucket, error) {
+	params := spendingParams(userID, start, end)
+	params["period"] = period
+
+	var buckets []schema.SpendingBucket
+	err := database.DB.Raw(`
+		SELECT date_trunc(@period, spent_at) AS period_start, SUM(amount) AS total
+		FROM `+spendingSource+`
+		GROUP BY 1
+		ORDER BY 1`, params).Scan(&buckets).Error
+	return buckets, err
+}
+
+func GetSpendingByStore(userID uuid.UUID, start, end time.Time) ([]schema.SpendingGroup, error) {
+	var groups []schema.SpendingGroup
+	err := database.DB.Raw(`
+		SELECT COALESCE(NULLIF(store, ''), 'Tidak diketahui') AS name, SUM(amount) AS total
+		FROM `+spendingSource+`
+		GROUP BY 1
+		ORDER BY total DESC`, spendingParams(userID, start, end)).Scan(&groups).Error
+	return groups, err
+}
+
+func GetSpendingByCategory(userID uuid.UUID, start, end time.Time) ([]schema.SpendingGroup, error) {
+	var groups []schema.SpendingGroup
+	err := database.DB.Raw(`
+		SELECT COALESCE(NULLIF(category, ''), 'Lainnya') AS name, SUM(amount) AS total
+		FROM `+spendingSource+`
+		GROUP BY 1
+		ORDER BY total DESC`, spendingParams(userID, start, end)).Scan(&groups).Error
+	return groups, err
+}
+
+func GetTotalSpending(userID uuid.UUID, start, end time.Time) (float64, error) {
+	var total float64
+	err := database.DB.Raw(`
+		SELECT COALESCE(SUM(amount), 0)
+		FROM `+spendingSource, spendingParams(userID, start, end)).Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+func CreateStoredImage(image *schema.StoredImage) error {
+	return database.DB.Create(image).Error
+}
+
+func GetStoredImageByID(imageID string) (*schema.StoredImage, error) {
+	var image schema.StoredImage
+	if err := database.DB.Where("id = ?", imageID).First(&image).Error; err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func GetStoredImagesByOwner(userID uuid.UUID, ownerType string, ownerID uuid.UUID) ([]schema.StoredImage, error) {
+	var images []schema.StoredImage
+	err := database.DB.
+		Where("user_id = ? AND owner_type = ? AND owner_id = ?", userID, ownerType, ownerID).
+		Order("created_at ASC").
+		Find(&images).Error
+	return images, err
+}
+
+// LinkStoredImage attaches an image to the record it was uploaded for
+func LinkStoredImage(imageID uuid.UUID, ownerType string, ownerID uuid.UUID) error {
+	return database.DB.Model(&schema.StoredImage{}).
+		Where("id = ?", imageID).
+		Updates(map[string]any{
+			"owner_type": ownerType,
+			"owner_id":   ownerID,
+		}).Error
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func CartRoute(r *gin.Engine, cfg config.Config) {
+func CartRoute(r *gin.Engine, cfg config.Config, cartController *controller.CartController) {
 	r.Use(func(c *gin.Context) {
 		fmt.Println("Request Path:", c.Request.Method, c.Request.URL.Path)
 		c.Next()
@@ -20,9 +20,12 @@ func CartRoute(r *gin.Engine, cfg config.Config) {
 
 	cartRoutes.POST("/create", controller.CreateNewCartHandler)
 	cartRoutes.POST("/item/create", controller.CreateCartItemHandler)
+	cartRoutes.PUT("/item/:id/check", controller.CheckCartItemHandler)
 	cartRoutes.GET("/all", controller.GetAllCartHandler)
 	cartRoutes.GET("/:id", controller.GetCartDetailHandler)
 	cartRoutes.GET("/:id/items", controller.GetCartItemsHandler)
+	cartRoutes.POST("/:id/complete", cartController.CompleteShoppingHandler)
+	cartRoutes.GET("/:id/budget", controller.GetCartBudgetHandler)
 	cartRoutes.PUT("/update", controller.UpdateCartHandler)
 	cartRoutes.DELETE("/delete/:id", controller.DeleteCartHandler)
 }
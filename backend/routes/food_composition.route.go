@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/andi-frame/TeamName_KulkasKu/backend/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func FoodCompositionRoute(r *gin.Engine) {
+	router := r.Group("/food-composition")
+
+	router.GET("/search", controller.SearchFoodCompositionsHandler)
+	router.GET("/:code", controller.GetFoodCompositionHandler)
+}
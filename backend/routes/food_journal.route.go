@@ -8,20 +8,26 @@ import (
 	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
 )
 
-func FoodJournalRoutes(router *gin.Engine, cfg config.Config) {
+func FoodJournalRoutes(router *gin.Engine, cfg config.Config, foodJournalController *controller.FoodJournalController) {
 	foodJournalGroup := router.Group("/food-journal")
 	foodJournalGroup.Use(middleware.JWTMiddleware())
 	{
 		foodJournalGroup.POST("/analyze-text", controller.AnalyzeFoodFromTextHandler)
-		foodJournalGroup.POST("/analyze-image", controller.AnalyzeFoodFromImageHandler)
+		foodJournalGroup.POST("/analyze-image", foodJournalController.AnalyzeFoodFromImageHandler)
+		foodJournalGroup.POST("/analyze-meal", foodJournalController.AnalyzeMealHandler)
 
 		foodJournalGroup.POST("/create", controller.CreateNewFoodJournalHandler)
 		foodJournalGroup.GET("/all", controller.GetAllFoodJournalHandler)
 		foodJournalGroup.GET("/today", controller.GetTodayFoodJournalHandler)
 		foodJournalGroup.GET("/dashboard", controller.GetFoodJournalDashboardHandler)
-		// foodJournalGroup.GET("/search", controller.GetFilteredFoodJournalHandler)
-		// foodJournalGroup.GET("/meal-type", controller.GetFoodJournalByMealTypeHandler)
+		foodJournalGroup.GET("/progress", controller.GetNutritionProgressHandler)
+		foodJournalGroup.GET("/insights/feelings", controller.GetFeelingInsightsHandler)
+		foodJournalGroup.GET("/search", controller.SearchFoodJournalHandler)
+		foodJournalGroup.GET("/meal-type", controller.GetFoodJournalByMealTypeHandler)
 		foodJournalGroup.GET("/:id", controller.GetFoodJournalByIDHandler)
+		foodJournalGroup.GET("/:id/history", controller.GetFoodJournalHistoryHandler)
+		foodJournalGroup.PUT("/:id/foods", controller.EditDetectedFoodsHandler)
+		foodJournalGroup.POST("/:id/reanalyze", controller.ReanalyzeFoodJournalHandler)
 		foodJournalGroup.PUT("/update", controller.UpdateFoodJournalHandler)
 		foodJournalGroup.DELETE("/delete/:id", controller.DeleteFoodJournalHandler)
 	}
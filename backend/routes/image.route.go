@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/andi-frame/TeamName_KulkasKu/backend/controller"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func ImageRoute(r *gin.Engine, imageController *controller.ImageController) {
+	imageRoutes := r.Group("/images")
+	imageRoutes.Use(middleware.JWTMiddleware())
+
+	imageRoutes.GET("", imageController.GetOwnerImagesHandler)
+	imageRoutes.GET("/:id", imageController.GetImageHandler)
+
+	// Signed links from the local storage driver, the signature is the authorization
+	r.GET("/files/*key", imageController.ServeFileHandler)
+}
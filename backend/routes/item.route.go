@@ -11,11 +11,21 @@ func ItemRoute(r *gin.Engine, itemController *controller.ItemController) {
 	itemRoutes.Use(middleware.JWTMiddleware())
 
 	itemRoutes.POST("/create", itemController.CreateNewItemHandler)
+	itemRoutes.POST("/scan", itemController.ScanItemHandler)
+	itemRoutes.POST("/scan/batch", itemController.ScanItemBatchHandler)
 	itemRoutes.GET("/all", itemController.GetAllItemHandler)
 	itemRoutes.GET("/expired", itemController.GetAllExpiredItemHandler)
 	itemRoutes.GET("/expired/search", itemController.GetSearchedExpiredItemHandler)
 	itemRoutes.GET("/fresh", itemController.GetAllFreshItemHandler)
 	itemRoutes.GET("/fresh/search", itemController.GetSearchedFreshItemHandler)
+	itemRoutes.GET("/products", itemController.GetInventoryProductsHandler)
+	itemRoutes.GET("/products/:id/batches", itemController.GetProductBatchesHandler)
+	itemRoutes.GET("/products/:id/prices", itemController.GetProductPriceTrendHandler)
+	itemRoutes.POST("/products/:id/consume", itemController.ConsumeProductHandler)
+	itemRoutes.PUT("/products/:id/threshold", itemController.SetRestockRuleHandler)
+	itemRoutes.DELETE("/products/:id/threshold", itemController.DeleteRestockRuleHandler)
+	itemRoutes.GET("/low-stock", itemController.GetLowStockItemsHandler)
 	itemRoutes.PUT("/update", itemController.UpdateItemHandler)
+	itemRoutes.PUT("/open/:id", itemController.OpenItemHandler)
 	itemRoutes.DELETE("/delete/:id", itemController.DeleteItemHandler)
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/controller"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+)
+
+func MealTemplateRoute(router *gin.Engine) {
+	mealTemplateGroup := router.Group("/meal-templates")
+	mealTemplateGroup.Use(middleware.JWTMiddleware())
+	{
+		mealTemplateGroup.GET("", controller.GetMealTemplatesHandler)
+		mealTemplateGroup.POST("", controller.CreateMealTemplateHandler)
+		mealTemplateGroup.GET("/:id", controller.GetMealTemplateByIDHandler)
+		mealTemplateGroup.PUT("/:id", controller.UpdateMealTemplateHandler)
+		mealTemplateGroup.DELETE("/:id", controller.DeleteMealTemplateHandler)
+		mealTemplateGroup.POST("/:id/log", controller.LogMealTemplateHandler)
+	}
+}
@@ -4,12 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/controller"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
 )
 
 func PredictionRoute(r *gin.Engine, controller *controller.PredictionController) {
 	router := r.Group("/predict")
 
-	router.POST("/image", controller.PredictItemHandler)
+	router.POST("/image", middleware.OptionalJWTMiddleware(), controller.PredictItemHandler)
 
 	router.GET("/health", controller.HealthCheckHandler)
 }
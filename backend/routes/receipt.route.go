@@ -2,18 +2,31 @@ package routes
 
 import (
 	"github.com/andi-frame/TeamName_KulkasKu/backend/controller"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
 	"github.com/gin-gonic/gin"
 )
 
-func ReceiptRoute(r *gin.Engine, geminiService *service.GeminiService) {
+func ReceiptRoute(r *gin.Engine, geminiService *service.GeminiService, ocrProvider service.OCRProvider, recipeService *service.RecipeService, imageService *service.ImageService) {
 	router := r.Group("/receipt")
 
-	receiptService, err := service.NewReceiptService(geminiService)
+	receiptService, err := service.NewReceiptService(geminiService, ocrProvider)
 	if err != nil {
 		panic("Failed to create receipt service: " + err.Error())
 	}
-	receiptController := controller.NewReceiptController(receiptService)
+	receiptController := controller.NewReceiptController(receiptService, recipeService, imageService)
 
-	router.POST("/scan", receiptController.AnalyzeReceiptHandler)
+	// Anonymous scans still work, the photo is only kept for logged-in users
+	router.POST("/scan", middleware.OptionalJWTMiddleware(), receiptController.AnalyzeReceiptHandler)
+	router.POST("/scan/text", receiptController.AnalyzeReceiptTextHandler)
+
+	importRoutes := router.Group("/import")
+	importRoutes.Use(middleware.JWTMiddleware())
+	importRoutes.POST("/draft", receiptController.CreateImportDraftHandler)
+	importRoutes.POST("/confirm", receiptController.ConfirmImportHandler)
+
+	historyRoutes := router.Group("/history")
+	historyRoutes.Use(middleware.JWTMiddleware())
+	historyRoutes.GET("", receiptController.GetReceiptHistoryHandler)
+	historyRoutes.GET("/:id", receiptController.GetReceiptDetailHandler)
 }
\ No newline at end of file
@@ -2,18 +2,16 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/andi-frame/TeamName_KulkasKu/backend/config"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/controller"
-	"github.com/andi-frame/TeamName_KulkasKu/backend/service"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
 )
 
-func ProductRoute(r *gin.Engine, cfg config.Config) {
+func ProductRoute(r *gin.Engine, productController *controller.ProductController) {
 	router := r.Group("/product-info")
 
-	productService := service.NewProductService()
-	productController := controller.NewProductController(productService)
-
 	router.POST("/:barcode", productController.GetProductInfoByBarcodeHandler)
+	router.PUT("/:barcode", middleware.JWTMiddleware(), productController.SubmitProductHandler)
+	router.GET("/search", productController.SearchProductsHandler)
 	
 	router.GET("/health", productController.HealthCheckHandler)
 }
\ No newline at end of file
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/andi-frame/TeamName_KulkasKu/backend/controller"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func SpendingRoute(r *gin.Engine) {
+	spendingRoutes := r.Group("/spending")
+	spendingRoutes.Use(middleware.JWTMiddleware())
+
+	spendingRoutes.GET("/report", controller.GetSpendingReportHandler)
+	spendingRoutes.GET("/budget", controller.GetBudgetStatusHandler)
+}
@@ -12,4 +12,6 @@ func UserPreferenceRoute(r *gin.Engine) {
 
 	userPreferenceRoutes.POST("/onboarding", controller.OnboardingHandler)
 	userPreferenceRoutes.PUT("/onboarding", controller.UpdateOnboardingDataHandler)
+	userPreferenceRoutes.GET("/nutrition-targets", controller.GetNutritionTargetsHandler)
+	userPreferenceRoutes.PUT("/nutrition-targets", controller.UpdateNutritionTargetsHandler)
 }
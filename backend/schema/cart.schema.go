@@ -14,17 +14,26 @@ type Cart struct {
 	User      User      `gorm:"foreignKey:UserID;references:ID"`
 	Name      string
 	Desc      *string
+	IsRestock bool `gorm:"default:false"`
 }
 
 type CartItem struct {
 	BaseModel
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
-	CartID     uuid.UUID `gorm:"type:uuid"`
-	Cart       Cart      `gorm:"foreignKey:CartID;references:ID"`
+	CartID     uuid.UUID  `gorm:"type:uuid"`
+	Cart       Cart       `gorm:"foreignKey:CartID;references:ID"`
+	ProductID  *uuid.UUID `gorm:"type:uuid;index"`
 	Name       string
 	Type       string
 	Amount     float64
 	AmountType string
 	Desc       *string
+
+	// Shopping state, PricePaid is the actual total paid for the line
+	IsChecked   bool `gorm:"default:false"`
+	IsPurchased bool `gorm:"default:false;index"`
+	PricePaid   *float64
+	PurchasedAt *time.Time
+	ItemID      *uuid.UUID `gorm:"type:uuid"`
 }
@@ -0,0 +1,96 @@
+package schema
+
+import "time"
+
+// Controlled vocabulary for how a user felt around a meal. Moods describe the general state,
+// symptoms are physical complaints that may follow a meal.
+const (
+	FeelingEnergized = "energized"
+	FeelingHappy     = "happy"
+	FeelingSatisfied = "satisfied"
+	FeelingCalm      = "calm"
+	FeelingNeutral   = "neutral"
+	FeelingHungry    = "hungry"
+	FeelingStressed  = "stressed"
+	FeelingSad       = "sad"
+	FeelingIrritable = "irritable"
+
+	SymptomBloating    = "bloating"
+	SymptomFatigue     = "fatigue"
+	SymptomSleepy      = "sleepy"
+	SymptomHeadache    = "headache"
+	SymptomNausea      = "nausea"
+	SymptomHeartburn   = "heartburn"
+	SymptomStomachache = "stomachache"
+)
+
+const (
+	FeelingKindMood    = "mood"
+	FeelingKindSymptom = "symptom"
+)
+
+// Intensity runs from 1 (barely) to 5 (severe), 3 when the text does not say
+const (
+	MinFeelingIntensity     = 1
+	DefaultFeelingIntensity = 3
+	MaxFeelingIntensity     = 5
+)
+
+// Feeling is one vocabulary term with its intensity
+type Feeling struct {
+	Code      string `json:"code"`
+	Intensity int    `json:"intensity"`
+}
+
+// FeelingScores is FeelingBefore and FeelingAfter mapped onto the vocabulary. Codes are empty when the
+// text had no known term. Symptoms are the complaints after the meal.
+type FeelingScores struct {
+	Before   Feeling   `json:"before" gorm:"embedded;embeddedPrefix:before_"`
+	After    Feeling   `json:"after" gorm:"embedded;embeddedPrefix:after_"`
+	Symptoms []Feeling `json:"symptoms" gorm:"serializer:json"`
+}
+
+// FeelingFrequency is how often a feeling followed a meal in the insight range
+type FeelingFrequency struct {
+	Code         string  `json:"code"`
+	Kind         string  `json:"kind"`
+	Meals        int     `json:"meals"`
+	Rate         float64 `json:"rate"` // share of meals with a recorded after-feeling
+	AvgIntensity float64 `json:"avg_intensity"`
+}
+
+// Kinds of meal property an association is about
+const (
+	FactorFood     = "food"
+	FactorMealType = "meal_type"
+	FactorMealTime = "meal_time"
+	FactorNutrient = "nutrient" // the meal is in the user's top quarter for the nutrient
+	FactorBefore   = "feeling_before"
+)
+
+// FeelingAssociation compares how often a feeling followed meals with and without a property.
+// PValue is a one-sided Fisher exact test, QValue the Benjamini-Hochberg adjusted value over all pairs tested.
+type FeelingAssociation struct {
+	Feeling      string  `json:"feeling"`
+	Factor       string  `json:"factor"`
+	Value        string  `json:"value"`
+	MealsWith    int     `json:"meals_with"`    // meals with the property
+	FeelingWith  int     `json:"feeling_with"`  // of those, followed by the feeling
+	RateWith     float64 `json:"rate_with"`     // FeelingWith / MealsWith
+	RateWithout  float64 `json:"rate_without"`  // the same for meals without the property
+	RelativeRisk float64 `json:"relative_risk"` // RateWith / RateWithout, smoothed so it stays finite
+	AvgIntensity float64 `json:"avg_intensity"` // of the feeling after meals with the property
+	PValue       float64 `json:"p_value"`
+	QValue       float64 `json:"q_value"`
+	Notable      bool    `json:"notable"`
+}
+
+type FeelingInsights struct {
+	StartDate    time.Time            `json:"start_date"`
+	EndDate      time.Time            `json:"end_date"`
+	Meals        int                  `json:"meals"`
+	MealsRated   int                  `json:"meals_rated"` // with a recognised after-feeling or symptom
+	Feelings     []FeelingFrequency   `json:"feelings"`
+	Associations []FeelingAssociation `json:"associations"`
+	Note         string               `json:"note,omitempty"`
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Where a food composition entry's values come from
+const (
+	CompositionSourceTKPI   = "tkpi"   // Tabel Komposisi Pangan Indonesia
+	CompositionSourceRecipe = "recipe" // typical home recipe, for dishes the table lacks
+)
+
+// FoodComposition is a reference food with its nutrients per 100 g edible portion
+type FoodComposition struct {
+	BaseModel
+	Code       string   `json:"code" gorm:"uniqueIndex;not null"`
+	Name       string   `json:"name" gorm:"not null"`
+	Aliases    []string `json:"aliases" gorm:"serializer:json"`
+	Category   string   `json:"category"`
+	Calories   float64  `json:"calories"`
+	Protein    float64  `json:"protein"`
+	Fat        float64  `json:"fat"`
+	Carbs      float64  `json:"carbs"`
+	Fiber      float64  `json:"fiber"`
+	Sodium     float64  `json:"sodium"` // mg
+	Source     string   `json:"source"`
+	SearchText string   `json:"-" gorm:"not null"` // name and aliases, trigram indexed
+}
+
+// BeforeSave keeps the search text in step with the name and aliases
+func (f *FoodComposition) BeforeSave(tx *gorm.DB) error {
+	f.SearchText = strings.ToLower(strings.Join(append([]string{f.Name}, f.Aliases...), " | "))
+	return nil
+}
+
+// FoodCompositionResult is a search hit, Score is 1 for an exact name or alias and otherwise the best
+// trigram similarity of the query to the name or one alias, 0-1
+type FoodCompositionResult struct {
+	FoodComposition
+	Score float64 `json:"score"`
+}
+
+// CompositionMatch records which table entry a detected food was grounded on
+type CompositionMatch struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Source string  `json:"source"`
+	Score  float64 `json:"score"`
+}
@@ -18,10 +18,12 @@ type FoodJournal struct {
 	Description       string            `json:"description"`
 	FeelingBefore     string            `json:"feeling_before"`
 	FeelingAfter      string            `json:"feeling_after"`
+	Feelings          FeelingScores     `json:"feelings" gorm:"embedded;embeddedPrefix:feeling_"` // normalized, see NormalizeFeelings
 	InputType         string            `json:"input_type"`
 	RawInput          string            `json:"raw_input"`
 	ProcessedInput    string            `json:"processed_input"`
 	ImageURL          string            `json:"image_url"`
+	ImageID           *uuid.UUID        `json:"image_id" gorm:"type:uuid"` // stored upload, see StoredImage
 	AINutrition       AINutrition       `json:"ai_nutrition" gorm:"embedded;embeddedPrefix:ai_"`
 	AIFeedback        string            `json:"ai_feedback"`
 	AIRecommendations AIRecommendations `json:"ai_recommendations" gorm:"embedded;embeddedPrefix:rec_"`
@@ -44,14 +46,85 @@ type FoodAnalysis struct {
 	TotalNutrition AINutrition    `json:"total_nutrition"`
 	AnalysisText   string         `json:"analysis_text"`
 	Confidence     float64        `json:"confidence"`
+	Photos         []MealPhoto    `json:"photos,omitempty"` // set by multi-photo analysis
+
+	// Bounds of TotalNutrition from the foods' weight ranges, and the size reference they were estimated with
+	NutritionRange *NutritionRange   `json:"nutrition_range,omitempty"`
+	Reference      *PortionReference `json:"reference,omitempty"`
+}
+
+type NutritionRange struct {
+	Min AINutrition `json:"min"`
+	Max AINutrition `json:"max"`
+}
+
+// Objects of known size a photo can be calibrated with
+const (
+	ReferencePlate   = "plate"
+	ReferenceHand    = "hand"
+	ReferenceSpoon   = "spoon"
+	ReferencePackage = "package"
+)
+
+// PortionReference is an object in the photo whose size helps estimate the portions
+type PortionReference struct {
+	Kind    string  `json:"kind"`
+	Size    float64 `json:"size,omitempty"`    // plate diameter in cm
+	Barcode string  `json:"barcode,omitempty"` // the packaged product in the photo
+	Note    string  `json:"note,omitempty"`
 }
 
 type DetectedFood struct {
 	Name        string      `json:"name"`
 	Portion     string      `json:"portion"`
 	Weight      float64     `json:"weight"`
+	WeightMin   float64     `json:"weight_min,omitempty"` // plausible portion range in grams
+	WeightMax   float64     `json:"weight_max,omitempty"`
 	Nutrition   AINutrition `json:"nutrition"`
 	Description string      `json:"description"`
+	Source      string      `json:"source,omitempty"`  // ai, product or manual
+	Barcode     string      `json:"barcode,omitempty"` // set for product components
+
+	// Which inputs of a multi-photo analysis the food was found in
+	Attribution *FoodAttribution `json:"attribution,omitempty"`
+	// The food composition table entry whose values replaced the AI estimate
+	Composition *CompositionMatch `json:"composition,omitempty"`
+}
+
+// FoodAttribution points a detected food back to the photos and text it came from
+type FoodAttribution struct {
+	Photos      []int  `json:"photos"`         // MealPhoto numbers
+	Description bool   `json:"description"`    // named in the user's text
+	Note        string `json:"note,omitempty"` // e.g. "nutrition read from the label"
+}
+
+// Kinds of photo in a multi-photo meal analysis
+const (
+	MealPhotoPlate = "plate"
+	MealPhotoMenu  = "menu"
+	MealPhotoLabel = "label" // nutrition facts label
+	MealPhotoOther = "other"
+)
+
+// MealPhoto is one photo sent to a multi-photo meal analysis
+type MealPhoto struct {
+	Number  int        `json:"number"` // 1-based, as referenced by FoodAttribution
+	Kind    string     `json:"kind"`
+	ImageID *uuid.UUID `json:"image_id,omitempty"`
+}
+
+// Where a detected food's nutrition came from
+const (
+	FoodSourceAI      = "ai"
+	FoodSourceProduct = "product"
+	FoodSourceManual  = "manual" // entered by the user for a meal template
+)
+
+// ProductPortion is a packaged product from the catalog eaten as part of a meal
+type ProductPortion struct {
+	Barcode   string  `json:"barcode"`
+	ProductID string  `json:"product_id"`
+	Quantity  float64 `json:"quantity"` // grams or ml eaten, defaults to the whole package
 }
 
 type AIRecommendations struct {
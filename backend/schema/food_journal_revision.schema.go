@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Why a journal entry's analysis changed
+const (
+	RevisionReasonEditFoods = "edit_foods"
+	RevisionReasonReanalyze = "reanalyze"
+)
+
+// FoodJournalRevision keeps the analysis an entry had before an edit or re-analysis replaced it
+type FoodJournalRevision struct {
+	BaseModel
+	CreatedAt         time.Time         `json:"created_at"`
+	FoodJournalID     uuid.UUID         `json:"food_journal_id" gorm:"type:uuid;index"`
+	UserID            uuid.UUID         `json:"user_id" gorm:"type:uuid;index"`
+	Reason            string            `json:"reason"`
+	Description       string            `json:"description"`
+	FoodAnalysis      string            `json:"-" gorm:"type:jsonb"`
+	AINutrition       AINutrition       `json:"ai_nutrition" gorm:"embedded;embeddedPrefix:ai_"`
+	AIFeedback        string            `json:"ai_feedback"`
+	AIRecommendations AIRecommendations `json:"ai_recommendations" gorm:"embedded;embeddedPrefix:rec_"`
+}
+
+// DetectedFoodEdit changes one food of an entry, addressed by its position in detected_foods.
+// A new weight rescales the food's nutrition proportionally.
+type DetectedFoodEdit struct {
+	Index   int     `json:"index" binding:"min=0"`
+	Portion string  `json:"portion"`
+	Weight  float64 `json:"weight" binding:"omitempty,gt=0"`
+	Remove  bool    `json:"remove"`
+}
+
+type DetectedFoodEditRequest struct {
+	Foods []DetectedFoodEdit `json:"foods" binding:"required,min=1,dive"`
+}
+
+// ReanalyzeRequest runs a fresh AI analysis, from a corrected description or the entry's own text
+type ReanalyzeRequest struct {
+	Description string `json:"description"`
+}
@@ -0,0 +1,21 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InventoryProduct is the logical item a user keeps in the fridge, each Item row is one batch of it
+type InventoryProduct struct {
+	BaseModel
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_inventory_product_user_name"`
+	User           User      `gorm:"foreignKey:UserID;references:ID"`
+	Name           string
+	NormalizedName string `gorm:"uniqueIndex:idx_inventory_product_user_name"`
+	Type           string
+	AmountType     string
+	Batches        []Item `gorm:"foreignKey:ProductID"`
+}
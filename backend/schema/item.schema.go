@@ -11,8 +11,9 @@ type Item struct {
 	BaseModel
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
-	UserID     uuid.UUID `gorm:"type:uuid"`
-	User       User      `gorm:"foreignKey:UserID;references:ID"`
+	UserID     uuid.UUID  `gorm:"type:uuid"`
+	User       User       `gorm:"foreignKey:UserID;references:ID"`
+	ProductID  *uuid.UUID `gorm:"type:uuid;index"`
 	Name       string
 	Type       string
 	Amount     float64
@@ -20,6 +21,11 @@ type Item struct {
 	Desc       *string
 	StartDate  time.Time
 	ExpDate    time.Time
+	ImageID    *uuid.UUID `gorm:"type:uuid"` // photo the item was predicted from
+
+	// Opened state, effective expiry is the earlier of ExpDate and the after-opening shelf life
+	OpenedAt         *time.Time
+	EffectiveExpDate *time.Time `gorm:"index"`
 }
 
 func (i *Item) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MealTemplate is a saved meal that can be logged again without a new analysis
+type MealTemplate struct {
+	BaseModel
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	UserID          uuid.UUID     `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name            string        `json:"name" gorm:"not null"`
+	MealType        string        `json:"meal_type"`
+	Description     string        `json:"description"`
+	SourceJournalID *uuid.UUID    `json:"source_journal_id" gorm:"type:uuid"` // entry it was saved from, nil when composed
+	AINutrition     AINutrition   `json:"ai_nutrition" gorm:"embedded;embeddedPrefix:ai_"`
+	FoodAnalysis    string        `json:"-" gorm:"type:jsonb"`
+	Analysis        *FoodAnalysis `json:"food_analysis" gorm:"-"`
+	UseCount        int           `json:"use_count" gorm:"default:0"`
+	LastUsedAt      *time.Time    `json:"last_used_at"`
+}
+
+// MealTemplateCreate saves an existing entry's meal when JournalID is set, otherwise composes one from
+// Foods, whose nutrition is looked up in the composition table when only a weight is given, and ProductItems
+type MealTemplateCreate struct {
+	JournalID    string           `json:"journal_id"`
+	Name         string           `json:"name"`
+	MealType     string           `json:"meal_type"`
+	Description  string           `json:"description"`
+	Foods        []DetectedFood   `json:"foods"`
+	ProductItems []ProductPortion `json:"product_items"`
+}
+
+type MealTemplateUpdate struct {
+	Name        *string `json:"name"`
+	MealType    *string `json:"meal_type"`
+	Description *string `json:"description"`
+}
+
+// MealTemplateLog logs a template as a journal entry. EatenAt is RFC 3339 or a local "2006-01-02T15:04",
+// empty means now, and MealType defaults to the template's.
+type MealTemplateLog struct {
+	EatenAt                string    `json:"eaten_at"`
+	MealType               string    `json:"meal_type"`
+	FeelingBefore          string    `json:"feeling_before"`
+	FeelingAfter           string    `json:"feeling_after"`
+	FeelingBeforeIntensity int       `json:"feeling_before_intensity"`
+	FeelingAfterIntensity  int       `json:"feeling_after_intensity"`
+	Symptoms               []Feeling `json:"symptoms"`
+}
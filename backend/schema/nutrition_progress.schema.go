@@ -0,0 +1,56 @@
+package schema
+
+import "time"
+
+// NutritionBucket is the intake of one day or week. Weekly buckets hold the average per logged day,
+// so they compare directly with daily targets.
+type NutritionBucket struct {
+	PeriodStart     time.Time          `json:"period_start"`
+	DaysLogged      int                `json:"days_logged"`
+	Meals           int                `json:"meals"`
+	Calories        float64            `json:"calories"`
+	Protein         float64            `json:"protein"`
+	Carbs           float64            `json:"carbs"`
+	Fat             float64            `json:"fat"`
+	Sugar           float64            `json:"sugar"`
+	Fiber           float64            `json:"fiber"`
+	Sodium          float64            `json:"sodium"`
+	RollingCalories float64            `json:"rolling_calories"` // average over the last 7 days or 4 weeks with logs
+	RollingProtein  float64            `json:"rolling_protein"`
+	RollingCarbs    float64            `json:"rolling_carbs"`
+	RollingFat      float64            `json:"rolling_fat"`
+	WithinTarget    bool               `json:"within_target"`
+	PercentOfTarget map[string]float64 `json:"percent_of_target" gorm:"-"`
+}
+
+type MealTypeNutrition struct {
+	MealType        string  `json:"meal_type"`
+	Meals           int     `json:"meals"`
+	Calories        float64 `json:"calories"`
+	Protein         float64 `json:"protein"`
+	Carbs           float64 `json:"carbs"`
+	Fat             float64 `json:"fat"`
+	Sugar           float64 `json:"sugar"`
+	Fiber           float64 `json:"fiber"`
+	Sodium          float64 `json:"sodium"`
+	CaloriesShare   float64 `json:"calories_share"` // percent of all calories in the range
+	AvgMealCalories float64 `json:"avg_meal_calories"`
+}
+
+// NutritionStreak counts consecutive logged days within target, the current streak ends today or yesterday
+type NutritionStreak struct {
+	Current     int `json:"current"`
+	Longest     int `json:"longest"`
+	DaysInRange int `json:"days_within_target"`
+}
+
+type NutritionProgress struct {
+	Period    string              `json:"period"`
+	StartDate time.Time           `json:"start_date"`
+	EndDate   time.Time           `json:"end_date"`
+	Targets   *NutritionTargets   `json:"targets"`
+	Average   AINutrition         `json:"average"` // per logged day over the whole range
+	Buckets   []NutritionBucket   `json:"buckets"`
+	MealTypes []MealTypeNutrition `json:"meal_types"`
+	Streak    NutritionStreak     `json:"streak"`
+}
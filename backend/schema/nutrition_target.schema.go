@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NutritionTargetOverride holds the daily targets a user set by hand. Nil fields use the computed target.
+type NutritionTargetOverride struct {
+	BaseModel
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID `gorm:"type:uuid;uniqueIndex"`
+	User      User      `gorm:"foreignKey:UserID;references:ID"`
+
+	Calories *float64
+	Protein  *float64
+	Carbs    *float64
+	Fat      *float64
+	Sugar    *float64
+	Fiber    *float64
+	Sodium   *float64
+}
+
+// NutritionTargets are the daily needs of a user. Sugar and sodium are upper limits, the rest are goals.
+type NutritionTargets struct {
+	BMR        float64  `json:"bmr"`
+	TDEE       float64  `json:"tdee"`
+	Calories   float64  `json:"calories"`
+	Protein    float64  `json:"protein"`
+	Carbs      float64  `json:"carbs"`
+	Fat        float64  `json:"fat"`
+	Sugar      float64  `json:"sugar"`
+	Fiber      float64  `json:"fiber"`
+	Sodium     float64  `json:"sodium"`
+	Estimated  bool     `json:"estimated"`  // body data was incomplete and defaults were used
+	Overridden []string `json:"overridden"` // targets taken from the user's overrides
+	Notes      []string `json:"notes"`
+}
+
+// NutritionTargetRequest sets the user's overrides, null or missing fields go back to the computed value
+type NutritionTargetRequest struct {
+	Calories *float64 `json:"calories" binding:"omitempty,gt=0"`
+	Protein  *float64 `json:"protein" binding:"omitempty,gt=0"`
+	Carbs    *float64 `json:"carbs" binding:"omitempty,gt=0"`
+	Fat      *float64 `json:"fat" binding:"omitempty,gt=0"`
+	Sugar    *float64 `json:"sugar" binding:"omitempty,gt=0"`
+	Fiber    *float64 `json:"fiber" binding:"omitempty,gt=0"`
+	Sodium   *float64 `json:"sodium" binding:"omitempty,gt=0"`
+}
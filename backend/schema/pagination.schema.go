@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageCursor marks where the next page starts: the sort keys and ID of the last row returned
+type PageCursor struct {
+	Keys []time.Time `json:"k"`
+	ID   uuid.UUID   `json:"id"`
+}
+
+type PageRequest struct {
+	Limit  int
+	Cursor *PageCursor
+}
+
+// PageInfo goes next to "data" in every paginated list response
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// FoodJournalSearch filters the journal. Nutrient ranges are keyed by nutrient name, e.g. "calories".
+type FoodJournalSearch struct {
+	Query       string
+	MealType    string
+	Start       *time.Time // inclusive instant
+	End         *time.Time // exclusive instant
+	MinNutrient map[string]float64
+	MaxNutrient map[string]float64
+}
+
+// Which batches an ItemSearch covers, empty for every batch
+const (
+	ItemStateFresh   = "fresh"
+	ItemStateExpired = "expired"
+)
+
+// ItemSearch filters the fridge batches
+type ItemSearch struct {
+	State string
+	Name  string
+	Type  string
+	Start *time.Time // earliest start date
+	Exp   *time.Time // latest printed expiry
+}
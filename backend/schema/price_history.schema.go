@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceRecord is one observed price of a product, normalized to the product's canonical unit
+type PriceRecord struct {
+	BaseModel
+	CreatedAt      time.Time  `json:"created_at"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	ProductID      *uuid.UUID `json:"product_id" gorm:"type:uuid;index"`
+	Name           string     `json:"name"`
+	NormalizedName string     `json:"normalized_name" gorm:"index"`
+	StoreName      string     `json:"store_name"`
+	Unit           string     `json:"unit"`
+	UnitPrice      float64    `json:"unit_price"`
+	Source         string     `json:"source"` // receipt or cart
+	SourceID       uuid.UUID  `json:"source_id" gorm:"type:uuid"`
+	RecordedAt     time.Time  `json:"recorded_at" gorm:"index"`
+}
+
+// StorePrice summarizes what one store charged for a product
+type StorePrice struct {
+	StoreName    string    `json:"store_name"`
+	LatestPrice  float64   `json:"latest_price"`
+	MinPrice     float64   `json:"min_price"`
+	MaxPrice     float64   `json:"max_price"`
+	AvgPrice     float64   `json:"avg_price"`
+	Observations int       `json:"observations"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+type PriceTrend struct {
+	ProductID     string        `json:"product_id"`
+	Name          string        `json:"name"`
+	Unit          string        `json:"unit"`
+	LatestPrice   float64       `json:"latest_price"`
+	CheapestStore string        `json:"cheapest_store"`
+	CheapestPrice float64       `json:"cheapest_price"`
+	Points        []PriceRecord `json:"points"`
+	Stores        []StorePrice  `json:"stores"`
+}
+
+// LatestPrice is the most recent price of a product at one store
+type LatestPrice struct {
+	NormalizedName string    `json:"normalized_name"`
+	StoreName      string    `json:"store_name"`
+	Unit           string    `json:"unit"`
+	UnitPrice      float64   `json:"unit_price"`
+	RecordedAt     time.Time `json:"recorded_at"`
+}
+
+type CartLineEstimate struct {
+	CartItemID    string   `json:"cart_item_id"`
+	Name          string   `json:"name"`
+	Amount        float64  `json:"amount"`
+	AmountType    string   `json:"amount_type"`
+	LatestPrice   *float64 `json:"latest_price"`
+	LatestStore   string   `json:"latest_store"`
+	CheapestPrice *float64 `json:"cheapest_price"`
+	CheapestStore string   `json:"cheapest_store"`
+	EstimatedCost float64  `json:"estimated_cost"`
+	CheapestCost  float64  `json:"cheapest_cost"`
+}
@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a catalog product came from
+const (
+	ProductSourceOpenFoodFacts = "openfoodfacts"
+	ProductSourceUser          = "user"
+)
+
+// Product is a barcode product in the local catalog, cached from Open Food Facts or submitted by users
+type Product struct {
+	BaseModel
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	Barcode         string            `json:"barcode" gorm:"uniqueIndex;not null"`
+	Name            string            `json:"name" gorm:"not null"`
+	Brand           string            `json:"brand"`
+	Quantity        string            `json:"quantity"`
+	PackageAmount   float64           `json:"package_amount"`
+	AmountType      string            `json:"amount_type"`
+	Categories      []string          `json:"categories" gorm:"serializer:json"`
+	ItemType        string            `json:"item_type"`
+	Nutriments      ProductNutriments `json:"nutriments" gorm:"embedded;embeddedPrefix:nutri_"`
+	Allergens       []string          `json:"allergens" gorm:"serializer:json"`
+	IngredientsText string            `json:"ingredients_text"`
+	ImageURL        string            `json:"image_url"`
+	Source          string            `json:"source" gorm:"default:openfoodfacts"`
+	SubmittedBy     *uuid.UUID        `json:"submitted_by" gorm:"type:uuid"`
+}
+
+// ProductNutriments are per 100 g, or 100 ml for drinks
+type ProductNutriments struct {
+	EnergyKcal    float64 `json:"energy_kcal"`
+	Proteins      float64 `json:"proteins"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Sugars        float64 `json:"sugars"`
+	Fat           float64 `json:"fat"`
+	SaturatedFat  float64 `json:"saturated_fat"`
+	Fiber         float64 `json:"fiber"`
+	Salt          float64 `json:"salt"`
+	Sodium        float64 `json:"sodium"`
+}
+
+// ProductSubmission is a user-contributed product. For a barcode the catalog already has it only
+// fills in the empty fields, it cannot correct the others.
+type ProductSubmission struct {
+	Name            string            `json:"name" binding:"required"`
+	Brand           string            `json:"brand"`
+	Quantity        string            `json:"quantity"`
+	Categories      []string          `json:"categories"`
+	ItemType        string            `json:"item_type"`
+	Nutriments      ProductNutriments `json:"nutriments"`
+	Allergens       []string          `json:"allergens"`
+	IngredientsText string            `json:"ingredients_text"`
+	ImageURL        string            `json:"image_url"`
+}
@@ -1,20 +1,121 @@
 package schema
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Receipt is a scanned shopping receipt kept for history and spending reports
+type Receipt struct {
+	BaseModel
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	UserID        uuid.UUID     `json:"user_id" gorm:"type:uuid;index"`
+	User          User          `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	StoreName     string        `json:"store_name"`
+	PurchaseDate  time.Time     `json:"purchase_date" gorm:"index"`
+	Currency      string        `json:"currency" gorm:"default:IDR"`
+	Subtotal      float64       `json:"subtotal"`
+	Tax           float64       `json:"tax"`
+	Total         float64       `json:"total"`
+	PaymentMethod string        `json:"payment_method"`
+	ImageID       *uuid.UUID    `json:"image_id" gorm:"type:uuid"`
+	Confidence    float64       `json:"confidence"`
+	LinesTotal    float64       `json:"lines_total"`
+	IsReconciled  bool          `json:"is_reconciled"`
+	Discrepancy   float64       `json:"discrepancy"`
+	IsDraft       bool          `json:"is_draft" gorm:"default:false;index"` // scanned but not imported yet, left out of history, spending and prices
+	Lines         []ReceiptLine `json:"lines,omitempty" gorm:"foreignKey:ReceiptID;constraint:OnDelete:CASCADE"`
+}
+
+type ReceiptLine struct {
+	BaseModel
+	ReceiptID  uuid.UUID `json:"receipt_id" gorm:"type:uuid;index"`
+	Name       string    `json:"name"`
+	Quantity   int       `json:"quantity"`
+	UnitPrice  float64   `json:"unit_price"`
+	LineTotal  float64   `json:"line_total"`
+	Type       string    `json:"type"`
+	Unit       string    `json:"unit"`
+	Confidence float64   `json:"confidence"`
+}
+
 type ReceiptItem struct {
 	Name       string  `json:"name"`
 	Quantity   int     `json:"quantity"`
 	Price      float64 `json:"price"`
 	Confidence float64 `json:"confidence"`
+	Type       string  `json:"type,omitempty"`
+	Unit       string  `json:"unit,omitempty"`
 }
 
 type ReceiptAnalysisResponse struct {
 	Success bool         `json:"success"`
 	Data    *ReceiptData `json:"data,omitempty"`
 	Error   string       `json:"error,omitempty"`
+	Image   *StoredImage `json:"image,omitempty"` // the stored photo, when the user is logged in
 }
 
 type ReceiptData struct {
 	Items          []ReceiptItem `json:"items"`
+	StoreName      string        `json:"store_name,omitempty"`
+	PurchaseDate   string        `json:"purchase_date,omitempty"` // yyyy-mm-dd
+	Currency       string        `json:"currency,omitempty"`
+	Subtotal       float64       `json:"subtotal,omitempty"`
+	Tax            float64       `json:"tax,omitempty"`
+	Total          float64       `json:"total,omitempty"`
+	PaymentMethod  string        `json:"payment_method,omitempty"`
 	Confidence     float64       `json:"confidence"`
 	ProcessingTime string        `json:"processing_time,omitempty"`
+	Source         string        `json:"source,omitempty"` // gemini or local
+}
+
+// Where a receipt analysis came from
+const (
+	ReceiptSourceGemini = "gemini"
+	ReceiptSourceLocal  = "local"
+)
+
+// ReceiptTextRequest carries OCR text of a receipt for the local parser
+type ReceiptTextRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// ReceiptDraftItem is a scanned line with the fridge item it would become
+type ReceiptDraftItem struct {
+	Name               string     `json:"name"`
+	Quantity           int        `json:"quantity"`
+	Price              float64    `json:"price"`
+	Confidence         float64    `json:"confidence"`
+	Type               string     `json:"type"`
+	Amount             float64    `json:"amount"`
+	AmountType         string     `json:"amount_type"`
+	StartDate          string     `json:"start_date"` // yyyy-mm-dd
+	ExpDate            string     `json:"exp_date"`   // yyyy-mm-dd
+	MatchedProductID   *uuid.UUID `json:"matched_product_id"`
+	MatchedProductName string     `json:"matched_product_name,omitempty"`
+	MatchScore         float64    `json:"match_score"`
+}
+
+type ReceiptDraft struct {
+	ReceiptID  *uuid.UUID         `json:"receipt_id"`
+	Items      []ReceiptDraftItem `json:"items"`
+	Confidence float64            `json:"confidence"`
+}
+
+type ReceiptConfirmItem struct {
+	Name       string     `json:"name" binding:"required"`
+	Type       string     `json:"type"`
+	Amount     float64    `json:"amount"`
+	AmountType string     `json:"amount_type"`
+	StartDate  string     `json:"start_date"` // yyyy-mm-dd, defaults to today
+	ExpDate    string     `json:"exp_date"`   // yyyy-mm-dd, defaults to shelf-life estimate
+	ProductID  *uuid.UUID `json:"product_id"`
+	Accepted   bool       `json:"accepted"`
+}
+
+type ReceiptConfirmRequest struct {
+	ReceiptID *uuid.UUID           `json:"receipt_id"` // the draft's receipt, imported into the history
+	Items     []ReceiptConfirmItem `json:"items" binding:"required,dive"`
 }
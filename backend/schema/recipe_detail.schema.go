@@ -78,6 +78,7 @@ type RecipeDetail struct {
 	CookingTool     []any            `json:"cooking_tool"`
 	TipsAndTrick    []any            `json:"tips_and_trick"`
 	PurchaseDetail  []any            `json:"purchase_detail"`
+	OverBudget      bool             `json:"over_budget"`
 }
 
 type RecipeDetailResponse struct {
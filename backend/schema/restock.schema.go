@@ -0,0 +1,20 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RestockRule keeps at least MinQuantity of a product in stock, topping it up to RestockQuantity
+type RestockRule struct {
+	BaseModel
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	UserID          uuid.UUID        `gorm:"type:uuid;index"`
+	User            User             `gorm:"foreignKey:UserID;references:ID"`
+	ProductID       uuid.UUID        `gorm:"type:uuid;uniqueIndex"`
+	Product         InventoryProduct `gorm:"foreignKey:ProductID;references:ID"`
+	MinQuantity     float64
+	RestockQuantity float64
+}
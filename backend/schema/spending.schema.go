@@ -0,0 +1,52 @@
+package schema
+
+import "time"
+
+type SpendingBucket struct {
+	PeriodStart time.Time `json:"period_start"`
+	Total       float64   `json:"total"`
+	Budget      float64   `json:"budget"`
+	OverBudget  bool      `json:"over_budget"`
+}
+
+type SpendingGroup struct {
+	Name  string  `json:"name"`
+	Total float64 `json:"total"`
+}
+
+type SpendingReport struct {
+	Period          string           `json:"period"`
+	StartDate       time.Time        `json:"start_date"`
+	EndDate         time.Time        `json:"end_date"`
+	Total           float64          `json:"total"`
+	DailyBudget     float64          `json:"daily_budget"`
+	Budget          float64          `json:"budget"`
+	BudgetRemaining float64          `json:"budget_remaining"`
+	OverBudget      bool             `json:"over_budget"`
+	Buckets         []SpendingBucket `json:"buckets"`
+	ByStore         []SpendingGroup  `json:"by_store"`
+	ByCategory      []SpendingGroup  `json:"by_category"`
+}
+
+type BudgetStatus struct {
+	Period     string    `json:"period"`
+	StartDate  time.Time `json:"start_date"`
+	Spent      float64   `json:"spent"`
+	Budget     float64   `json:"budget"`
+	Remaining  float64   `json:"remaining"`
+	OverBudget bool      `json:"over_budget"`
+}
+
+// CartBudgetEstimate compares the estimated cost of a shopping plan with what is left of the budget.
+// EstimatedTotal uses the latest known prices, CheapestTotal the cheapest store seen per line.
+type CartBudgetEstimate struct {
+	CartID          string             `json:"cart_id"`
+	EstimatedTotal  float64            `json:"estimated_total"`
+	CheapestTotal   float64            `json:"cheapest_total"`
+	PotentialSaving float64            `json:"potential_saving"`
+	PricedLines     int                `json:"priced_lines"`
+	UnpricedLines   int                `json:"unpriced_lines"`
+	BudgetRemaining float64            `json:"budget_remaining"`
+	OverBudget      bool               `json:"over_budget"`
+	Lines           []CartLineEstimate `json:"lines"`
+}
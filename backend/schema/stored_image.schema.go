@@ -0,0 +1,34 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What an uploaded image belongs to
+const (
+	ImageOwnerFoodJournal = "food_journal"
+	ImageOwnerReceipt     = "receipt"
+	ImageOwnerItem        = "item"
+)
+
+// StoredImage is an uploaded photo kept in object storage, linked to the record it was taken for
+type StoredImage struct {
+	BaseModel
+	CreatedAt    time.Time  `json:"created_at"`
+	UserID       uuid.UUID  `json:"user_id" gorm:"type:uuid;index"`
+	OwnerType    string     `json:"owner_type" gorm:"index:idx_stored_image_owner"` // empty until linked
+	OwnerID      *uuid.UUID `json:"owner_id" gorm:"type:uuid;index:idx_stored_image_owner"`
+	Key          string     `json:"-"`
+	ThumbnailKey string     `json:"-"`
+	ContentType  string     `json:"content_type"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	Size         int64      `json:"size"`
+
+	// Signed links, filled when the image is returned
+	URL          string     `json:"url,omitempty" gorm:"-"`
+	ThumbnailURL string     `json:"thumbnail_url,omitempty" gorm:"-"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" gorm:"-"`
+}
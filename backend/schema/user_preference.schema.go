@@ -33,6 +33,17 @@ type UserPreference struct {
 	FridgeCapacity int
 	FridgeModel    string
 
+	// Budget-aware mode keeps generated recipes within DailyFoodCost
+	BudgetAwareMode bool `gorm:"default:false"`
+
+	// Body data for energy needs, Sex is "male" or "female"
+	Height float64
+	Weight float64
+	Sex    string
+
+	// IANA time zone that decides where the user's days start, e.g. Asia/Makassar for WITA
+	Timezone string `gorm:"default:Asia/Jakarta"`
+
 	// Relationships for array fields
 	PreferredTags []UserPreferenceTag `gorm:"foreignKey:UserPreferenceID"`
 }
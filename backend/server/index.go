@@ -39,26 +39,48 @@ func (s *Server) RegisterRoutes() http.Handler {
 	if err != nil {
 		log.Fatalf("Failed to create Gemini service: %v", err)
 	}
+	ocrProvider := service.NewOCRProvider(cfg.OCRServiceURL)
 	recipeService := service.NewRecipeService(geminiService, database.DB)
 	activityService := service.NewActivityService()
 
+	// Barcode lookups go through the local catalog, PRODUCT_LOOKUP=local skips Open Food Facts
+	productService := service.NewProductService()
+	if cfg.ProductLookup == "local" {
+		productService = service.NewStubProductService(nil)
+	}
+	catalogService := service.NewCatalogService(productService)
+
+	// Uploaded photos, STORAGE_DRIVER=s3 keeps them in an S3-compatible bucket
+	imageService, err := service.NewImageService(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create image storage: %v", err)
+	}
+
 	// Controllers
-	predictionController := controller.NewPredictionController(geminiService)
+	predictionController := controller.NewPredictionController(geminiService, imageService)
 	recipeController := controller.NewRecipeController(recipeService)
 	activityController := controller.NewActivityController(activityService)
-	itemController := controller.NewItemController(recipeService)
+	itemController := controller.NewItemController(recipeService, catalogService)
+	productController := controller.NewProductController(catalogService)
+	cartController := controller.NewCartController(recipeService)
+	foodJournalController := controller.NewFoodJournalController(imageService)
+	imageController := controller.NewImageController(imageService)
 
 	// Routes
 	routes.AuthRoute(r, cfg)
 	routes.PredictionRoute(r, predictionController)
-	routes.ProductRoute(r, cfg)
-	routes.ReceiptRoute(r, geminiService)
+	routes.ProductRoute(r, productController)
+	routes.ReceiptRoute(r, geminiService, ocrProvider, recipeService, imageService)
 	routes.RecipeRoute(r, recipeController)
 	routes.ItemRoute(r, itemController)
-	routes.CartRoute(r, cfg)
+	routes.CartRoute(r, cfg, cartController)
+	routes.SpendingRoute(r)
 	routes.UserPreferenceRoute(r)
 	routes.ActivityRoute(r, activityController)
-	routes.FoodJournalRoutes(r, cfg)
+	routes.FoodJournalRoutes(r, cfg, foodJournalController)
+	routes.MealTemplateRoute(r)
+	routes.ImageRoute(r, imageController)
+	routes.FoodCompositionRoute(r)
 
 	return r
 }
@@ -1,19 +1,38 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrCartNotOwned      = errors.New("cart does not belong to user")
+	ErrNothingToPurchase = errors.New("no checked items to purchase")
+)
+
 type CartService struct{}
 
 func (s *CartService) CreateCart(cart *schema.Cart) error {
 	return repository.CreateCart(cart)
 }
 
-func (s *CartService) GetAllCartsByUser(userID uuid.UUID) ([]schema.Cart, error) {
-	return repository.GetAllCartsByUser(userID)
+// GetAllCartsByUser returns one page of the user's carts, newest first
+func (s *CartService) GetAllCartsByUser(userID uuid.UUID, page schema.PageRequest) ([]schema.Cart, schema.PageInfo, error) {
+	carts, err := repository.GetAllCartsByUser(userID, page)
+	if err != nil {
+		return nil, schema.PageInfo{}, err
+	}
+
+	carts, info := utils.TrimPage(carts, page.Limit, func(cart schema.Cart) schema.PageCursor {
+		return schema.PageCursor{Keys: []time.Time{cart.CreatedAt}, ID: cart.ID}
+	})
+	return carts, info, nil
 }
 
 func (s *CartService) GetCartDetail(cartID uuid.UUID) (schema.Cart, error) {
@@ -27,3 +46,71 @@ func (s *CartService) UpdateCart(cart *schema.Cart) error {
 func (s *CartService) DeleteCart(cartID uuid.UUID) error {
 	return repository.DeleteCart(cartID)
 }
+
+func (s *CartService) CheckCartItem(cartItemID uuid.UUID, userID uuid.UUID, checked bool, pricePaid *float64) error {
+	cartItem, err := repository.GetCartItemByID(cartItemID)
+	if err != nil {
+		return err
+	}
+	if cartItem.Cart.UserID != userID {
+		return ErrCartNotOwned
+	}
+	if cartItem.IsPurchased {
+		return errors.New("cart item has already been purchased")
+	}
+	if pricePaid != nil && *pricePaid < 0 {
+		return errors.New("price paid cannot be negative")
+	}
+
+	return repository.UpdateCartItemCheck(cartItemID, checked, pricePaid)
+}
+
+// CompleteShopping turns every checked line of the cart into a fresh fridge batch bought today
+func (s *CartService) CompleteShopping(cartID uuid.UUID, userID uuid.UUID) ([]schema.Item, error) {
+	cart, err := repository.GetCartDetail(cartID)
+	if err != nil {
+		return nil, err
+	}
+	if cart.UserID != userID {
+		return nil, ErrCartNotOwned
+	}
+
+	cartItems, err := repository.GetCheckedCartItems(cartID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cartItems) == 0 {
+		return nil, ErrNothingToPurchase
+	}
+
+	now := time.Now()
+	today := LocalDate(now, UserLocation(userID))
+
+	items := make([]schema.Item, 0, len(cartItems))
+	for _, cartItem := range cartItems {
+		items = append(items, schema.Item{
+			UserID:     userID,
+			ProductID:  cartItem.ProductID,
+			Name:       cartItem.Name,
+			Type:       cartItem.Type,
+			Amount:     cartItem.Amount,
+			AmountType: cartItem.AmountType,
+			Desc:       cartItem.Desc,
+			StartDate:  today,
+			ExpDate:    EstimateExpDate(cartItem.Name, cartItem.Type, today),
+		})
+	}
+
+	cartItems, items, err = repository.PurchaseCartItems(cartItems, items, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		// A concurrent checkout purchased every line first
+		return nil, ErrNothingToPurchase
+	}
+	if err := recordCartPrices(userID, cartItems, items, now); err != nil {
+		fmt.Printf("Warning: failed to record cart prices: %v\n", err)
+	}
+	return items, nil
+}
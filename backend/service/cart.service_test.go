@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+func TestPurchaseCartItemsSkipsPurchasedLines(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "")
+
+	cart := schema.Cart{UserID: userID, Name: "Belanja"}
+	if err := repository.CreateCart(&cart); err != nil {
+		t.Fatal(err)
+	}
+	line := schema.CartItem{CartID: cart.ID, Name: "Telur", Type: "Telur", Amount: 10, AmountType: "butir", IsChecked: true}
+	if err := repository.CreateCartItem(&line); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two checkouts that both read the line before either purchased it
+	today := time.Now().Truncate(24 * time.Hour)
+	batch := func() []schema.Item {
+		return []schema.Item{{UserID: userID, Name: "Telur", Type: "Telur", Amount: 10, AmountType: "butir", StartDate: today, ExpDate: today}}
+	}
+	lines := []schema.CartItem{line}
+	if _, items, err := repository.PurchaseCartItems(lines, batch(), time.Now()); err != nil || len(items) != 1 {
+		t.Fatalf("first checkout bought %d items, %v", len(items), err)
+	}
+	if _, items, err := repository.PurchaseCartItems(lines, batch(), time.Now()); err != nil || len(items) != 0 {
+		t.Fatalf("second checkout bought %d items, %v, want none", len(items), err)
+	}
+
+	var batches int64
+	database.DB.Model(&schema.Item{}).Where("user_id = ?", userID).Count(&batches)
+	if batches != 1 {
+		t.Errorf("%d fridge batches for one cart line", batches)
+	}
+	if _, err := (&CartService{}).CompleteShopping(cart.ID, userID); err != ErrNothingToPurchase {
+		t.Errorf("repeat checkout: err = %v, want ErrNothingToPurchase", err)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+var testDatabaseOnce sync.Once
+
+// useTestDatabase points database.DB at TEST_DATABASE_URL, a disposable Postgres database, and skips the
+// test when it is not set. Tests create their own users and barcodes, so they can share the database.
+func useTestDatabase(t *testing.T) {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	testDatabaseOnce.Do(func() {
+		os.Setenv("DATABASE_URL", url)
+		database.Init()
+	})
+}
+
+// createTestUser stores a user with the given timezone, empty for the default
+func createTestUser(t *testing.T, timezone string) uuid.UUID {
+	t.Helper()
+	user := schema.User{Name: "Test", Email: uuid.NewString() + "@test.local"}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	pref := schema.UserPreference{UserID: user.ID, Timezone: timezone}
+	if err := database.DB.Create(&pref).Error; err != nil {
+		t.Fatalf("create preference: %v", err)
+	}
+	return user.ID
+}
+
+// testBarcode is an EAN-13 shaped barcode no other test run uses
+func testBarcode() string {
+	return fmt.Sprintf("899%010d", rand.Int63n(1e10))
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+var ErrInvalidFeeling = errors.New("invalid feeling")
+
+// feelingTerm is a vocabulary code with the words users write for it, in Indonesian and English
+type feelingTerm struct {
+	code     string
+	kind     string
+	keywords []string
+}
+
+var feelingVocabulary = []feelingTerm{
+	// Symptoms first, "sakit kepala" must not be read as a mood
+	{schema.SymptomBloating, schema.FeelingKindSymptom, []string{"kembung", "begah", "bloated", "bloating", "perut penuh gas", "gassy"}},
+	{schema.SymptomFatigue, schema.FeelingKindSymptom, []string{"lelah", "capek", "capai", "cape", "lemas", "lemes", "letih", "lesu", "tired", "fatigue", "fatigued", "exhausted"}},
+	{schema.SymptomSleepy, schema.FeelingKindSymptom, []string{"ngantuk", "mengantuk", "sleepy", "drowsy"}},
+	{schema.SymptomHeadache, schema.FeelingKindSymptom, []string{"pusing", "sakit kepala", "pening", "migrain", "headache", "migraine", "dizzy"}},
+	{schema.SymptomNausea, schema.FeelingKindSymptom, []string{"mual", "eneg", "enek", "nausea", "nauseous", "queasy"}},
+	{schema.SymptomHeartburn, schema.FeelingKindSymptom, []string{"maag", "perih", "asam lambung", "heartburn", "reflux"}},
+	{schema.SymptomStomachache, schema.FeelingKindSymptom, []string{"sakit perut", "mulas", "mules", "diare", "stomachache", "stomach ache", "cramps", "diarrhea"}},
+
+	{schema.FeelingEnergized, schema.FeelingKindMood, []string{"berenergi", "bertenaga", "segar", "seger", "semangat", "bugar", "energized", "energetic", "refreshed"}},
+	{schema.FeelingHappy, schema.FeelingKindMood, []string{"senang", "bahagia", "gembira", "happy", "glad", "good", "baik"}},
+	{schema.FeelingSatisfied, schema.FeelingKindMood, []string{"kenyang", "puas", "satisfied", "full", "content"}},
+	{schema.FeelingCalm, schema.FeelingKindMood, []string{"tenang", "rileks", "santai", "calm", "relaxed"}},
+	{schema.FeelingNeutral, schema.FeelingKindMood, []string{"biasa saja", "biasa aja", "biasa", "normal", "netral", "oke", "ok", "neutral", "fine"}},
+	{schema.FeelingHungry, schema.FeelingKindMood, []string{"lapar", "laper", "hungry", "starving"}},
+	{schema.FeelingStressed, schema.FeelingKindMood, []string{"stres", "stress", "stressed", "cemas", "gelisah", "tegang", "anxious"}},
+	{schema.FeelingSad, schema.FeelingKindMood, []string{"sedih", "galau", "murung", "sad", "down"}},
+	{schema.FeelingIrritable, schema.FeelingKindMood, []string{"kesal", "kesel", "marah", "bete", "emosi", "irritable", "cranky", "annoyed"}},
+}
+
+// What a negated term means instead, "kurang semangat" is fatigue rather than no feeling
+var feelingOpposites = map[string]string{
+	schema.FeelingEnergized: schema.SymptomFatigue,
+	schema.FeelingCalm:      schema.FeelingStressed,
+}
+
+// Words around a term that change its meaning or strength
+var (
+	feelingNegations = []string{"tidak", "tak", "gak", "ga", "nggak", "ngga", "enggak", "engga", "kurang", "bukan", "no", "not", "tanpa", "without", "never"}
+	slightModifiers  = []string{"sedikit", "dikit", "agak", "rada", "slightly", "bit", "little", "mildly"}
+	strongModifiers  = []string{"banget", "bgt", "sekali", "sangat", "amat", "terlalu", "very", "really", "so", "too"}
+	severeModifiers  = []string{"parah", "severe", "severely", "extremely", "terrible", "awful"}
+)
+
+// FeelingKind tells whether code is a mood or a symptom, empty when it is not in the vocabulary
+func FeelingKind(code string) string {
+	for _, term := range feelingVocabulary {
+		if term.code == code {
+			return term.kind
+		}
+	}
+	return ""
+}
+
+// ParseFeelings finds the vocabulary terms in a free-text feeling, in the order they are written.
+// Negated terms ("tidak kembung") are skipped or read as their opposite ("kurang semangat" is fatigue),
+// and the word next to a term sets its intensity ("agak pusing" is 2, "pusing banget" 4, "pusing
+// parah" 5). Modifiers only reach within a clause, "perut ga enak, kembung" still is bloating.
+func ParseFeelings(text string) []schema.Feeling {
+	clauses := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return strings.ContainsRune(",.;!?\n", r)
+	})
+
+	var feelings []schema.Feeling
+	for _, clause := range clauses {
+		for _, feeling := range parseFeelingClause(clause) {
+			if !slices.ContainsFunc(feelings, func(f schema.Feeling) bool { return f.Code == feeling.Code }) {
+				feelings = append(feelings, feeling)
+			}
+		}
+	}
+	return feelings
+}
+
+func parseFeelingClause(clause string) []schema.Feeling {
+	words := strings.FieldsFunc(clause, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var feelings []schema.Feeling
+	for i := 0; i < len(words); i++ {
+		term, length := matchFeelingTerm(words[i:])
+		if length == 0 {
+			continue
+		}
+		end := i + length
+
+		var previous, beforePrevious, next string
+		if i > 0 {
+			previous = words[i-1]
+		}
+		if i > 1 {
+			beforePrevious = words[i-2]
+		}
+		if end < len(words) {
+			next = words[end]
+		}
+		i = end - 1
+
+		code := term.code
+		intensity := feelingIntensity(previous, next)
+		switch {
+		case isNegation(previous):
+			opposite, ok := feelingOpposites[term.code]
+			if !ok {
+				continue
+			}
+			code = opposite
+		case isNegation(beforePrevious):
+			// "tidak terlalu pusing" still is a headache, a mild one, "tidak terlalu semangat" mild fatigue
+			if intensity != 4 {
+				continue
+			}
+			if opposite, ok := feelingOpposites[term.code]; ok {
+				code = opposite
+			}
+			intensity = 2
+		}
+		feelings = append(feelings, schema.Feeling{Code: code, Intensity: intensity})
+	}
+	return feelings
+}
+
+// matchFeelingTerm returns the term the words start with and how many words it spans, the longest keyword wins
+func matchFeelingTerm(words []string) (feelingTerm, int) {
+	var best feelingTerm
+	bestLength := 0
+	for _, term := range feelingVocabulary {
+		for _, keyword := range term.keywords {
+			parts := strings.Fields(keyword)
+			if len(parts) <= bestLength || len(parts) > len(words) {
+				continue
+			}
+			if slices.Equal(words[:len(parts)], parts) {
+				best, bestLength = term, len(parts)
+			}
+		}
+	}
+	return best, bestLength
+}
+
+func isNegation(word string) bool {
+	return slices.Contains(feelingNegations, word)
+}
+
+// feelingIntensity reads the modifier right before or after a term, "agak pusing" or "pusing banget"
+func feelingIntensity(previous, next string) int {
+	switch {
+	case slices.Contains(severeModifiers, previous), slices.Contains(severeModifiers, next):
+		return 5
+	case slices.Contains(strongModifiers, previous), slices.Contains(strongModifiers, next):
+		return 4
+	case slices.Contains(slightModifiers, previous), slices.Contains(slightModifiers, next):
+		return 2
+	}
+	return schema.DefaultFeelingIntensity
+}
+
+// FeelingInput is what the user reported around a meal. Intensities of 0 are read from the text,
+// Symptoms are picked from the vocabulary and add to those found in feelingAfter.
+type FeelingInput struct {
+	Before          string
+	After           string
+	BeforeIntensity int
+	AfterIntensity  int
+	Symptoms        []schema.Feeling
+}
+
+// NormalizeFeelings maps the free-text feelings onto the vocabulary. The first term of each text is
+// its main feeling, every symptom after the meal is also listed in Symptoms.
+func NormalizeFeelings(input FeelingInput) (schema.FeelingScores, error) {
+	scores := schema.FeelingScores{Symptoms: []schema.Feeling{}}
+
+	for _, intensity := range []int{input.BeforeIntensity, input.AfterIntensity} {
+		if intensity != 0 && (intensity < schema.MinFeelingIntensity || intensity > schema.MaxFeelingIntensity) {
+			return scores, fmt.Errorf("%w: intensity must be between %d and %d", ErrInvalidFeeling, schema.MinFeelingIntensity, schema.MaxFeelingIntensity)
+		}
+	}
+
+	if before := ParseFeelings(input.Before); len(before) > 0 {
+		scores.Before = before[0]
+		if input.BeforeIntensity != 0 {
+			scores.Before.Intensity = input.BeforeIntensity
+		}
+	}
+
+	after := ParseFeelings(input.After)
+	if len(after) > 0 {
+		scores.After = after[0]
+		if input.AfterIntensity != 0 {
+			scores.After.Intensity = input.AfterIntensity
+		}
+		after[0] = scores.After
+	}
+	for _, feeling := range after {
+		if FeelingKind(feeling.Code) == schema.FeelingKindSymptom {
+			scores.Symptoms = append(scores.Symptoms, feeling)
+		}
+	}
+
+	// Picked symptoms are more deliberate than the text and win on intensity
+	for _, symptom := range input.Symptoms {
+		if FeelingKind(symptom.Code) != schema.FeelingKindSymptom {
+			return scores, fmt.Errorf("%w: unknown symptom %q", ErrInvalidFeeling, symptom.Code)
+		}
+		if symptom.Intensity == 0 {
+			symptom.Intensity = schema.DefaultFeelingIntensity
+		}
+		if symptom.Intensity < schema.MinFeelingIntensity || symptom.Intensity > schema.MaxFeelingIntensity {
+			return scores, fmt.Errorf("%w: intensity must be between %d and %d", ErrInvalidFeeling, schema.MinFeelingIntensity, schema.MaxFeelingIntensity)
+		}
+
+		index := slices.IndexFunc(scores.Symptoms, func(f schema.Feeling) bool { return f.Code == symptom.Code })
+		if index >= 0 {
+			scores.Symptoms[index] = symptom
+		} else {
+			scores.Symptoms = append(scores.Symptoms, symptom)
+		}
+	}
+	if index := slices.IndexFunc(scores.Symptoms, func(f schema.Feeling) bool { return f.Code == scores.After.Code }); index >= 0 {
+		scores.After = scores.Symptoms[index]
+	} else if scores.After.Code == "" && len(scores.Symptoms) > 0 {
+		scores.After = scores.Symptoms[0]
+	}
+	return scores, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+func TestParseFeelings(t *testing.T) {
+	tests := []struct {
+		text string
+		want []schema.Feeling
+	}{
+		{"kembung", []schema.Feeling{{Code: schema.SymptomBloating, Intensity: 3}}},
+		{"agak pusing", []schema.Feeling{{Code: schema.SymptomHeadache, Intensity: 2}}},
+		{"pusing banget", []schema.Feeling{{Code: schema.SymptomHeadache, Intensity: 4}}},
+		{"pusing parah", []schema.Feeling{{Code: schema.SymptomHeadache, Intensity: 5}}},
+		// Multi-word symptoms are not read as a mood
+		{"sakit kepala", []schema.Feeling{{Code: schema.SymptomHeadache, Intensity: 3}}},
+		{"kenyang tapi ngantuk", []schema.Feeling{{Code: schema.FeelingSatisfied, Intensity: 3}, {Code: schema.SymptomSleepy, Intensity: 3}}},
+		{"tidak kembung", nil},
+		{"tidak terlalu pusing", []schema.Feeling{{Code: schema.SymptomHeadache, Intensity: 2}}},
+		// A negation does not reach past the end of its clause
+		{"perut ga enak, kembung", []schema.Feeling{{Code: schema.SymptomBloating, Intensity: 3}}},
+		{"ga mual. pusing", []schema.Feeling{{Code: schema.SymptomHeadache, Intensity: 3}}},
+		// Negated moods with an opposite are read as the opposite
+		{"kurang semangat", []schema.Feeling{{Code: schema.SymptomFatigue, Intensity: 3}}},
+		{"kurang tenang", []schema.Feeling{{Code: schema.FeelingStressed, Intensity: 3}}},
+		{"tidak terlalu semangat", []schema.Feeling{{Code: schema.SymptomFatigue, Intensity: 2}}},
+		{"ga senang", nil},
+		{"capek, lelah banget", []schema.Feeling{{Code: schema.SymptomFatigue, Intensity: 3}}},
+		{"Tired and very bloated", []schema.Feeling{{Code: schema.SymptomFatigue, Intensity: 3}, {Code: schema.SymptomBloating, Intensity: 4}}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseFeelings(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseFeelings(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeFeelings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input FeelingInput
+		want  schema.FeelingScores
+	}{
+		{
+			name:  "moods only",
+			input: FeelingInput{Before: "lapar", After: "kenyang dan senang"},
+			want: schema.FeelingScores{
+				Before:   schema.Feeling{Code: schema.FeelingHungry, Intensity: 3},
+				After:    schema.Feeling{Code: schema.FeelingSatisfied, Intensity: 3},
+				Symptoms: []schema.Feeling{},
+			},
+		},
+		{
+			name:  "given intensity wins over the text",
+			input: FeelingInput{Before: "agak lapar", After: "kembung", BeforeIntensity: 5, AfterIntensity: 1},
+			want: schema.FeelingScores{
+				Before:   schema.Feeling{Code: schema.FeelingHungry, Intensity: 5},
+				After:    schema.Feeling{Code: schema.SymptomBloating, Intensity: 1},
+				Symptoms: []schema.Feeling{{Code: schema.SymptomBloating, Intensity: 1}},
+			},
+		},
+		{
+			name: "picked symptoms join the text and win on intensity",
+			input: FeelingInput{After: "kembung, agak pusing", Symptoms: []schema.Feeling{
+				{Code: schema.SymptomHeadache, Intensity: 4},
+				{Code: schema.SymptomNausea},
+			}},
+			want: schema.FeelingScores{
+				After: schema.Feeling{Code: schema.SymptomBloating, Intensity: 3},
+				Symptoms: []schema.Feeling{
+					{Code: schema.SymptomBloating, Intensity: 3},
+					{Code: schema.SymptomHeadache, Intensity: 4},
+					{Code: schema.SymptomNausea, Intensity: 3},
+				},
+			},
+		},
+		{
+			name:  "picked symptom is the main feeling when the text has none",
+			input: FeelingInput{After: "hmm", Symptoms: []schema.Feeling{{Code: schema.SymptomHeartburn, Intensity: 2}}},
+			want: schema.FeelingScores{
+				After:    schema.Feeling{Code: schema.SymptomHeartburn, Intensity: 2},
+				Symptoms: []schema.Feeling{{Code: schema.SymptomHeartburn, Intensity: 2}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeFeelings(tt.input)
+			if err != nil {
+				t.Fatalf("normalize: %v", err)
+			}
+			if got.Before != tt.want.Before || got.After != tt.want.After || !slices.Equal(got.Symptoms, tt.want.Symptoms) {
+				t.Errorf("scores = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeFeelingsRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input FeelingInput
+	}{
+		{"intensity above the scale", FeelingInput{After: "kembung", AfterIntensity: 6}},
+		{"negative intensity", FeelingInput{Before: "lapar", BeforeIntensity: -1}},
+		{"mood picked as a symptom", FeelingInput{Symptoms: []schema.Feeling{{Code: schema.FeelingHappy}}}},
+		{"unknown symptom", FeelingInput{Symptoms: []schema.Feeling{{Code: "itchy"}}}},
+		{"symptom intensity above the scale", FeelingInput{Symptoms: []schema.Feeling{{Code: schema.SymptomNausea, Intensity: 9}}}},
+	}
+	for _, tt := range tests {
+		if _, err := NormalizeFeelings(tt.input); !errors.Is(err, ErrInvalidFeeling) {
+			t.Errorf("%s: err = %v, want ErrInvalidFeeling", tt.name, err)
+		}
+	}
+}
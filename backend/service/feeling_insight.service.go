@@ -0,0 +1,320 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	maxInsightDays = 365
+	// Fewer rated meals than this give no associations, only frequencies
+	minRatedMeals = 10
+	// A pair is only tested once the feeling followed at least this many meals with the property
+	minCooccurrence = 3
+	// Nutrient thresholds need this many analyzed meals to mean anything
+	minNutrientMeals = 8
+	maxAssociations  = 30
+	// Associations with a Benjamini-Hochberg q-value up to this are notable
+	notableQValue = 0.10
+)
+
+type insightFactor struct {
+	factor string
+	value  string
+}
+
+// ratedMeal is a journal entry with a recorded after-feeling, reduced to what the insights compare
+type ratedMeal struct {
+	factors  map[insightFactor]bool
+	feelings map[string]int // code to intensity
+}
+
+var insightNutrients = []struct {
+	name  string
+	value func(schema.AINutrition) float64
+}{
+	{"calories", func(n schema.AINutrition) float64 { return n.Calories }},
+	{"protein", func(n schema.AINutrition) float64 { return n.Protein }},
+	{"carbs", func(n schema.AINutrition) float64 { return n.Carbs }},
+	{"fat", func(n schema.AINutrition) float64 { return n.Fat }},
+	{"sugar", func(n schema.AINutrition) float64 { return n.Sugar }},
+	{"fiber", func(n schema.AINutrition) float64 { return n.Fiber }},
+	{"sodium", func(n schema.AINutrition) float64 { return n.Sodium }},
+}
+
+// GetFeelingInsights relates the feelings and symptoms after meals in the user's calendar days
+// [startDate, endDate] to what was eaten, when, and how much of each nutrient. Each pair is a 2x2
+// comparison of meals with and without the property, tested for the feeling being more common with it.
+func GetFeelingInsights(userID uuid.UUID, startDate, endDate time.Time) (*schema.FeelingInsights, error) {
+	if endDate.Before(startDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	if endDate.Sub(startDate) >= maxInsightDays*24*time.Hour {
+		return nil, fmt.Errorf("date range must be at most %d days", maxInsightDays)
+	}
+
+	loc := UserLocation(userID)
+	start, _ := LocalDayBounds(startDate, loc)
+	_, end := LocalDayBounds(endDate, loc)
+
+	journals, err := repository.GetFoodJournalsBetween(userID.String(), start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get food journals: %w", err)
+	}
+
+	insights := &schema.FeelingInsights{
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Meals:        len(journals),
+		Feelings:     []schema.FeelingFrequency{},
+		Associations: []schema.FeelingAssociation{},
+	}
+
+	var rated []schema.FoodJournal
+	var meals []ratedMeal
+	for _, journal := range journals {
+		feelings := journalFeelings(journal)
+		if len(feelings) == 0 {
+			continue
+		}
+		rated = append(rated, journal)
+		meals = append(meals, ratedMeal{factors: mealFactors(journal, loc), feelings: feelings})
+	}
+	insights.MealsRated = len(meals)
+	addNutrientFactors(rated, meals)
+
+	insights.Feelings = feelingFrequencies(meals)
+	if len(meals) < minRatedMeals {
+		insights.Note = fmt.Sprintf("Catat perasaan setelah makan di minimal %d makanan untuk melihat pola, baru %d tercatat", minRatedMeals, len(meals))
+		return insights, nil
+	}
+
+	insights.Associations = feelingAssociations(meals)
+	insights.Note = "Pola ini menunjukkan keterkaitan, bukan penyebab. Konsultasikan keluhan yang berulang dengan tenaga kesehatan."
+	return insights, nil
+}
+
+// journalFeelings is the after-feeling and symptoms of an entry. Entries logged before the vocabulary
+// existed are normalized from their text.
+func journalFeelings(journal schema.FoodJournal) map[string]int {
+	scores := journal.Feelings
+	if scores.Before.Code == "" && scores.After.Code == "" && len(scores.Symptoms) == 0 {
+		scores, _ = NormalizeFeelings(FeelingInput{Before: journal.FeelingBefore, After: journal.FeelingAfter})
+	}
+
+	feelings := map[string]int{}
+	if scores.After.Code != "" {
+		feelings[scores.After.Code] = scores.After.Intensity
+	}
+	for _, symptom := range scores.Symptoms {
+		feelings[symptom.Code] = symptom.Intensity
+	}
+	return feelings
+}
+
+func mealFactors(journal schema.FoodJournal, loc *time.Location) map[insightFactor]bool {
+	factors := map[insightFactor]bool{
+		{schema.FactorMealTime, mealTimeOfDay(journal.CreatedAt.In(loc).Hour())}: true,
+	}
+	if journal.MealType != "" {
+		factors[insightFactor{schema.FactorMealType, journal.MealType}] = true
+	}
+
+	if before := journal.Feelings.Before.Code; before != "" {
+		factors[insightFactor{schema.FactorBefore, before}] = true
+	} else if parsed := ParseFeelings(journal.FeelingBefore); len(parsed) > 0 {
+		factors[insightFactor{schema.FactorBefore, parsed[0].Code}] = true
+	}
+
+	if journal.FoodAnalysis != "" {
+		var analysis schema.FoodAnalysis
+		if err := json.Unmarshal([]byte(journal.FoodAnalysis), &analysis); err != nil {
+			fmt.Printf("Warning: failed to parse food analysis of journal %s: %v\n", journal.ID, err)
+		}
+		for _, food := range analysis.DetectedFoods {
+			// Grounded foods share the composition name, so "nasi putih" and "nasi" count together
+			name := utils.NormalizeName(food.Name)
+			if food.Composition != nil {
+				name = utils.NormalizeName(food.Composition.Name)
+			}
+			if name != "" {
+				factors[insightFactor{schema.FactorFood, name}] = true
+			}
+		}
+	}
+	return factors
+}
+
+// mealTimeOfDay buckets the local hour a meal was eaten
+func mealTimeOfDay(hour int) string {
+	switch {
+	case hour >= 5 && hour < 11:
+		return "pagi"
+	case hour >= 11 && hour < 15:
+		return "siang"
+	case hour >= 15 && hour < 18:
+		return "sore"
+	case hour >= 18 && hour < 22:
+		return "malam"
+	}
+	return "larut_malam"
+}
+
+// addNutrientFactors marks meals above the 75th percentile of the user's own analyzed meals for each nutrient
+func addNutrientFactors(journals []schema.FoodJournal, meals []ratedMeal) {
+	for _, nutrient := range insightNutrients {
+		var values []float64
+		for _, journal := range journals {
+			if journal.AINutrition.Calories > 0 {
+				values = append(values, nutrient.value(journal.AINutrition))
+			}
+		}
+		if len(values) < minNutrientMeals {
+			return
+		}
+
+		sort.Float64s(values)
+		threshold := values[(len(values)*3)/4]
+		if threshold <= 0 {
+			continue
+		}
+		for i, journal := range journals {
+			if journal.AINutrition.Calories > 0 && nutrient.value(journal.AINutrition) >= threshold {
+				meals[i].factors[insightFactor{schema.FactorNutrient, "tinggi_" + nutrient.name}] = true
+			}
+		}
+	}
+}
+
+func feelingFrequencies(meals []ratedMeal) []schema.FeelingFrequency {
+	counts := map[string]int{}
+	intensities := map[string]int{}
+	for _, meal := range meals {
+		for code, intensity := range meal.feelings {
+			counts[code]++
+			intensities[code] += intensity
+		}
+	}
+
+	frequencies := make([]schema.FeelingFrequency, 0, len(counts))
+	for code, count := range counts {
+		frequencies = append(frequencies, schema.FeelingFrequency{
+			Code:         code,
+			Kind:         FeelingKind(code),
+			Meals:        count,
+			Rate:         roundInsight(float64(count) / float64(len(meals))),
+			AvgIntensity: roundInsight(float64(intensities[code]) / float64(count)),
+		})
+	}
+	sort.Slice(frequencies, func(i, j int) bool {
+		if frequencies[i].Meals != frequencies[j].Meals {
+			return frequencies[i].Meals > frequencies[j].Meals
+		}
+		return frequencies[i].Code < frequencies[j].Code
+	})
+	return frequencies
+}
+
+// feelingAssociations tests every feeling against every meal property seen with it often enough.
+// Q-values are adjusted over all tested pairs, so a long history does not turn noise into findings.
+func feelingAssociations(meals []ratedMeal) []schema.FeelingAssociation {
+	factorMeals := map[insightFactor]int{}
+	for _, meal := range meals {
+		for factor := range meal.factors {
+			factorMeals[factor]++
+		}
+	}
+
+	var associations []schema.FeelingAssociation
+	for _, frequency := range feelingFrequencies(meals) {
+		for factor, with := range factorMeals {
+			// A property of every meal has nothing to compare against
+			if with == len(meals) {
+				continue
+			}
+
+			var feelingWith, feelingWithout, intensity int
+			for _, meal := range meals {
+				level, felt := meal.feelings[frequency.Code]
+				if !felt {
+					continue
+				}
+				if meal.factors[factor] {
+					feelingWith++
+					intensity += level
+				} else {
+					feelingWithout++
+				}
+			}
+			if feelingWith < minCooccurrence {
+				continue
+			}
+
+			without := len(meals) - with
+			rateWith := float64(feelingWith) / float64(with)
+			rateWithout := float64(feelingWithout) / float64(without)
+			relativeRisk := ((float64(feelingWith) + 0.5) / float64(with+1)) / ((float64(feelingWithout) + 0.5) / float64(without+1))
+
+			associations = append(associations, schema.FeelingAssociation{
+				Feeling:      frequency.Code,
+				Factor:       factor.factor,
+				Value:        factor.value,
+				MealsWith:    with,
+				FeelingWith:  feelingWith,
+				RateWith:     roundInsight(rateWith),
+				RateWithout:  roundInsight(rateWithout),
+				RelativeRisk: roundInsight(relativeRisk),
+				AvgIntensity: roundInsight(float64(intensity) / float64(feelingWith)),
+				PValue:       utils.FisherExactGreater(feelingWith, with-feelingWith, feelingWithout, without-feelingWithout),
+			})
+		}
+	}
+
+	pValues := make([]float64, len(associations))
+	for i := range associations {
+		pValues[i] = associations[i].PValue
+	}
+	for i, q := range utils.BenjaminiHochberg(pValues) {
+		associations[i].QValue = roundPValue(q)
+		associations[i].Notable = q <= notableQValue && associations[i].RelativeRisk > 1
+		associations[i].PValue = roundPValue(associations[i].PValue)
+	}
+
+	sort.Slice(associations, func(i, j int) bool {
+		a, b := associations[i], associations[j]
+		if a.PValue != b.PValue {
+			return a.PValue < b.PValue
+		}
+		if a.FeelingWith != b.FeelingWith {
+			return a.FeelingWith > b.FeelingWith
+		}
+		if a.Feeling != b.Feeling {
+			return a.Feeling < b.Feeling
+		}
+		return a.Factor+a.Value < b.Factor+b.Value
+	})
+	if len(associations) > maxAssociations {
+		associations = associations[:maxAssociations]
+	}
+	if associations == nil {
+		associations = []schema.FeelingAssociation{}
+	}
+	return associations
+}
+
+func roundInsight(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func roundPValue(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+// bloatingAfterSantan is twelve meals, santan in the first four. Bloating follows three of those and
+// one other meal, happiness six meals without santan.
+func bloatingAfterSantan() []ratedMeal {
+	meals := make([]ratedMeal, 12)
+	for i := range meals {
+		meals[i] = ratedMeal{factors: map[insightFactor]bool{{schema.FactorFood, "nasi"}: true}, feelings: map[string]int{}}
+	}
+	for i := 0; i < 4; i++ {
+		meals[i].factors[insightFactor{schema.FactorFood, "santan"}] = true
+	}
+	meals[8].factors[insightFactor{schema.FactorFood, "pedas"}] = true
+	meals[9].factors[insightFactor{schema.FactorFood, "pedas"}] = true
+
+	for _, i := range []int{0, 1, 2} {
+		meals[i].feelings[schema.SymptomBloating] = 4
+	}
+	meals[8].feelings[schema.SymptomBloating] = 2
+	for _, i := range []int{4, 5, 6, 7, 9, 10} {
+		meals[i].feelings[schema.FeelingHappy] = 3
+	}
+	return meals
+}
+
+func TestFeelingAssociations(t *testing.T) {
+	// Nasi is in every meal and pedas never shares three meals with a feeling, so one pair is tested
+	associations := feelingAssociations(bloatingAfterSantan())
+	if len(associations) != 1 {
+		t.Fatalf("got %d associations, want only bloating after santan: %+v", len(associations), associations)
+	}
+
+	want := schema.FeelingAssociation{
+		Feeling:      schema.SymptomBloating,
+		Factor:       schema.FactorFood,
+		Value:        "santan",
+		MealsWith:    4,
+		FeelingWith:  3,
+		RateWith:     0.75,
+		RateWithout:  0.13,   // 1 of 8
+		RelativeRisk: 4.2,    // (3.5/5) / (1.5/9)
+		AvgIntensity: 4,      // only the santan meals count
+		PValue:       0.0667, // Fisher 33/495
+		QValue:       0.0667, // a single test is not adjusted
+		Notable:      true,
+	}
+	if associations[0] != want {
+		t.Errorf("association = %+v, want %+v", associations[0], want)
+	}
+}
+
+func TestFeelingAssociationsAdjustForManyTests(t *testing.T) {
+	// Goreng shares three of its five meals with happiness, p = 396/792
+	meals := bloatingAfterSantan()
+	for _, i := range []int{0, 4, 5, 8, 9} {
+		meals[i].factors[insightFactor{schema.FactorFood, "goreng"}] = true
+	}
+
+	associations := feelingAssociations(meals)
+	if len(associations) != 2 {
+		t.Fatalf("got %d associations, want santan and goreng: %+v", len(associations), associations)
+	}
+
+	tests := []struct {
+		value          string
+		pValue, qValue float64
+		notable        bool
+	}{
+		// The second test doubles the santan q-value past the notable cut-off
+		{"santan", 0.0667, 0.1333, false},
+		{"goreng", 0.5, 0.5, false},
+	}
+	for i, tt := range tests {
+		got := associations[i]
+		if got.Value != tt.value || got.PValue != tt.pValue || got.QValue != tt.qValue || got.Notable != tt.notable {
+			t.Errorf("association %d = %s p %v q %v notable %v, want %s p %v q %v notable %v",
+				i, got.Value, got.PValue, got.QValue, got.Notable, tt.value, tt.pValue, tt.qValue, tt.notable)
+		}
+	}
+}
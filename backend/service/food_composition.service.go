@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+)
+
+const (
+	defaultCompositionSearchLimit = 10
+	maxCompositionSearchLimit     = 50
+
+	compositionSearchScore = 0.3 // loose, the user picks from the list
+	compositionMatchScore  = 0.7 // strict, "jus alpukat" must not become raw avocado
+	// Candidates loaded for ranking, word similarity finds "nasi" in every rice dish
+	compositionCandidates = 100
+)
+
+// SearchFoodCompositions looks foods up in the composition table by approximate name
+func SearchFoodCompositions(query string, limit int) ([]schema.FoodCompositionResult, error) {
+	query = utils.NormalizeName(query)
+	if len(query) < 2 {
+		return nil, errors.New("search query must be at least 2 characters")
+	}
+	if limit <= 0 {
+		limit = defaultCompositionSearchLimit
+	}
+	if limit > maxCompositionSearchLimit {
+		limit = maxCompositionSearchLimit
+	}
+	candidates, err := repository.SearchFoodCompositions(query, compositionSearchScore, compositionCandidates)
+	if err != nil {
+		return nil, err
+	}
+	results := RankFoodCompositions(query, candidates)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// MatchFoodComposition finds the table entry a detected food name refers to, nil when none is close enough
+func MatchFoodComposition(name string) (*schema.FoodCompositionResult, error) {
+	name = utils.NormalizeName(name)
+	if len(name) < 2 {
+		return nil, nil
+	}
+	candidates, err := repository.SearchFoodCompositions(name, compositionSearchScore, compositionCandidates)
+	if err != nil {
+		return nil, err
+	}
+	return bestFoodComposition(name, candidates), nil
+}
+
+// bestFoodComposition is the entry the whole name refers to. Word similarity alone is not enough:
+// "nasi" is a stretch of "nasi kuning", but only "nasi putih" is plain rice.
+func bestFoodComposition(name string, foods []schema.FoodComposition) *schema.FoodCompositionResult {
+	results := RankFoodCompositions(name, foods)
+	if len(results) == 0 || results[0].Score < compositionMatchScore {
+		return nil
+	}
+	return &results[0]
+}
+
+// RankFoodCompositions orders entries for a normalized query: an exact name or alias first, then by
+// the best trigram similarity to the name or one alias, then the shorter name
+func RankFoodCompositions(query string, foods []schema.FoodComposition) []schema.FoodCompositionResult {
+	results := make([]schema.FoodCompositionResult, 0, len(foods))
+	for _, food := range foods {
+		results = append(results, schema.FoodCompositionResult{FoodComposition: food, Score: compositionScore(query, food)})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if len(results[i].Name) != len(results[j].Name) {
+			return len(results[i].Name) < len(results[j].Name)
+		}
+		return results[i].Code < results[j].Code
+	})
+	return results
+}
+
+func compositionScore(query string, food schema.FoodComposition) float64 {
+	best := 0.0
+	for _, phrase := range append([]string{food.Name}, food.Aliases...) {
+		phrase = utils.NormalizeName(phrase)
+		if phrase == query {
+			return 1
+		}
+		// Below an exact match, however many trigrams are shared
+		best = max(best, min(utils.TrigramSimilarity(query, phrase), 0.99))
+	}
+	return math.Round(best*100) / 100
+}
+
+// GroundDetectedFoods replaces the model's nutrient estimates with composition table values for the
+// foods the table knows, scaled to the estimated weight. The table has no sugar column, so sugar
+// keeps the model's estimate. Foods from a product or a photographed nutrition label are already
+// exact and are left alone.
+func GroundDetectedFoods(analysis *schema.FoodAnalysis) {
+	if analysis == nil {
+		return
+	}
+
+	grounded := false
+	for i := range analysis.DetectedFoods {
+		food := &analysis.DetectedFoods[i]
+		if food.Source == schema.FoodSourceProduct || food.Weight <= 0 || readFromLabel(food, analysis.Photos) {
+			continue
+		}
+
+		match, err := MatchFoodComposition(food.Name)
+		if err != nil {
+			// Most likely pg_trgm is missing, no other food will match either
+			fmt.Printf("Warning: food composition lookup failed, keeping AI nutrition: %v\n", err)
+			return
+		}
+		if match == nil {
+			food.Composition = nil
+			continue
+		}
+
+		food.Nutrition = compositionNutrition(&match.FoodComposition, food.Weight, food.Nutrition)
+		food.Composition = &schema.CompositionMatch{
+			Code:   match.Code,
+			Name:   match.Name,
+			Source: match.Source,
+			Score:  match.Score,
+		}
+		grounded = true
+	}
+
+	if grounded {
+		analysis.TotalNutrition = sumDetectedFoods(analysis.DetectedFoods, analysis.Confidence)
+		analysis.Confidence = analysis.TotalNutrition.Confidence
+		refreshNutritionRange(analysis)
+	}
+}
+
+func readFromLabel(food *schema.DetectedFood, photos []schema.MealPhoto) bool {
+	if food.Attribution == nil {
+		return false
+	}
+	for _, photo := range photos {
+		if photo.Kind == schema.MealPhotoLabel && slices.Contains(food.Attribution.Photos, photo.Number) {
+			return true
+		}
+	}
+	return false
+}
+
+func compositionNutrition(food *schema.FoodComposition, grams float64, estimate schema.AINutrition) schema.AINutrition {
+	factor := grams / 100
+	return schema.AINutrition{
+		Calories:   food.Calories * factor,
+		Protein:    food.Protein * factor,
+		Carbs:      food.Carbs * factor,
+		Fat:        food.Fat * factor,
+		Sugar:      estimate.Sugar,
+		Fiber:      food.Fiber * factor,
+		Sodium:     food.Sodium * factor,
+		Confidence: estimate.Confidence,
+	}
+}
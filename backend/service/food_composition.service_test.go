@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+)
+
+func TestRankFoodCompositions(t *testing.T) {
+	seed := database.FoodCompositionSeed()
+	tests := []struct {
+		query string
+		code  string
+	}{
+		// Plain words mean the plain food, not the first dish that starts with them
+		{"nasi", "SR001"}, // Nasi putih, not Nasi kuning
+		{"kopi", "MN002"}, // Kopi hitam, not Kopi susu
+		{"mie", "SR017"},  // Mi telur rebus, not Mi ayam
+		{"teh", "MN005"},  // Teh tawar, not Teh manis
+		{"es teh manis", "MN001"},
+		{"nasgor", "SR005"},
+		{"nasi goreng", "SR005"},
+		{"nasi gorng", "SR005"},
+		{"mie goreng", "SR011"},
+		{"gado gado", "SY014"},
+		{"telur", "TL001"},
+	}
+	for _, tt := range tests {
+		results := RankFoodCompositions(tt.query, seed)
+		if results[0].Code != tt.code {
+			t.Errorf("%q ranks %s %s (%.2f) first, want %s", tt.query, results[0].Code, results[0].Name, results[0].Score, tt.code)
+		}
+	}
+}
+
+func TestBestFoodComposition(t *testing.T) {
+	seed := database.FoodCompositionSeed()
+	tests := []struct {
+		name string
+		code string // empty when the food must keep the AI estimate
+	}{
+		{"nasi", "SR001"},
+		{"kopi", "MN002"},
+		{"mie", "SR017"},
+		{"teh", "MN005"},
+		{"ayam goreng", "DG002"},
+		{"kopi susu", "MN003"},
+		{"es kopi susu", "MN003"},
+		{"nasi goreng", "SR005"},
+		// A stretch of the name is not the food
+		{"jus alpukat", ""},
+		{"nasi goreng seafood", ""},
+		{"mie ayam bakso", ""},
+		{"pizza", ""},
+	}
+	for _, tt := range tests {
+		match := bestFoodComposition(tt.name, seed)
+		switch {
+		case tt.code == "" && match != nil:
+			t.Errorf("%q grounded on %s %s (%.2f), want no match", tt.name, match.Code, match.Name, match.Score)
+		case tt.code != "" && match == nil:
+			t.Errorf("%q has no match, want %s", tt.name, tt.code)
+		case tt.code != "" && match.Code != tt.code:
+			t.Errorf("%q grounded on %s %s, want %s", tt.name, match.Code, match.Name, tt.code)
+		}
+	}
+}
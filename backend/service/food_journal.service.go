@@ -9,6 +9,7 @@ import (
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 	"github.com/google/uuid"
 )
 
@@ -21,30 +22,29 @@ type FoodJournalInput struct {
 	FeelingBefore  string
 	FeelingAfter   string
 	ImageURL       string
+	ImageIDs       []uuid.UUID // photos stored by analyze-image or analyze-meal, the first is the cover
 	InputType      string
 	RawInput       string
 	ProcessedInput string
 	FoodAnalysis   string
+	ProductItems   []schema.ProductPortion // packaged products, nutrition from their labels
 	CreatedAt      string
+
+	// From the feeling pickers, 0 reads the intensity from the text
+	FeelingBeforeIntensity int
+	FeelingAfterIntensity  int
+	Symptoms               []schema.Feeling
 }
 
 func CreateNewFoodJournal(input FoodJournalInput) error {
 	var createdAt time.Time
 	var err error
 
+	// Back-dated entries keep the current time of day in the user's zone
+	loc := UserLocation(input.UserID)
 	if input.CreatedAt == "" {
-		loc, err := time.LoadLocation("Asia/Jakarta")
-		if err != nil {
-			createdAt = time.Now()
-		} else {
-			createdAt = time.Now().In(loc)
-		}
+		createdAt = time.Now().In(loc)
 	} else {
-		loc, err := time.LoadLocation("Asia/Jakarta")
-		if err != nil {
-			loc = time.UTC
-		}
-
 		dateOnly, err := time.Parse("2006-01-02", input.CreatedAt)
 		if err != nil {
 			return errors.New("invalid created at date format")
@@ -64,11 +64,28 @@ func CreateNewFoodJournal(input FoodJournalInput) error {
 		)
 	}
 
-	geminiService, err := NewGeminiService()
+	feelings, err := NormalizeFeelings(FeelingInput{
+		Before:          input.FeelingBefore,
+		After:           input.FeelingAfter,
+		BeforeIntensity: input.FeelingBeforeIntensity,
+		AfterIntensity:  input.FeelingAfterIntensity,
+		Symptoms:        input.Symptoms,
+	})
 	if err != nil {
+		return err
+	}
+
+	// Products-only meals need no AI for their nutrition
+	geminiService, err := NewGeminiService()
+	if err != nil && input.InputType != "product" {
 		return fmt.Errorf("failed to initialize Gemini service: %w", err)
 	}
 
+	foods, err := productFoods(input.ProductItems)
+	if err != nil {
+		return err
+	}
+
 	var foodAnalysis *schema.FoodAnalysis
 	var foodAnalysisJSON string
 
@@ -78,7 +95,7 @@ func CreateNewFoodJournal(input FoodJournalInput) error {
 		if err != nil {
 			return fmt.Errorf("failed to parse provided food analysis: %w", err)
 		}
-	} else {
+	} else if input.InputType != "product" {
 		switch input.InputType {
 		case "text":
 			analysisInput := input.ProcessedInput
@@ -101,14 +118,19 @@ func CreateNewFoodJournal(input FoodJournalInput) error {
 				}
 			}
 		}
+		GroundDetectedFoods(foodAnalysis)
+	}
 
-		if foodAnalysis != nil {
-			analysisBytes, err := json.Marshal(foodAnalysis)
-			if err != nil {
-				return fmt.Errorf("failed to marshal food analysis: %w", err)
-			}
-			foodAnalysisJSON = string(analysisBytes)
+	if len(foods) > 0 {
+		foodAnalysis = mergeProductFoods(foodAnalysis, foods)
+		foodAnalysisJSON = ""
+	}
+	if foodAnalysis != nil && foodAnalysisJSON == "" {
+		analysisBytes, err := json.Marshal(foodAnalysis)
+		if err != nil {
+			return fmt.Errorf("failed to marshal food analysis: %w", err)
 		}
+		foodAnalysisJSON = string(analysisBytes)
 	}
 
 	mealName := input.MealName
@@ -127,6 +149,7 @@ func CreateNewFoodJournal(input FoodJournalInput) error {
 		Description:    input.Description,
 		FeelingBefore:  input.FeelingBefore,
 		FeelingAfter:   input.FeelingAfter,
+		Feelings:       feelings,
 		InputType:      input.InputType,
 		RawInput:       input.RawInput,
 		ProcessedInput: input.ProcessedInput,
@@ -136,19 +159,53 @@ func CreateNewFoodJournal(input FoodJournalInput) error {
 		UpdatedAt:      createdAt,
 	}
 
+	// The id is needed up front to link the photo
+	foodJournal.ID = uuid.New()
+	for _, imageID := range input.ImageIDs {
+		if err := CheckImageLinkable(imageID, input.UserID, schema.ImageOwnerFoodJournal, foodJournal.ID); err != nil {
+			return fmt.Errorf("invalid image: %w", err)
+		}
+	}
+	if len(input.ImageIDs) > 0 {
+		foodJournal.ImageID = &input.ImageIDs[0]
+	}
+
 	if foodAnalysis != nil {
 		foodJournal.AINutrition = foodAnalysis.TotalNutrition
 		foodJournal.AIFeedback = foodAnalysis.AnalysisText
 
-		recommendations, err := geminiService.GenerateRecommendations(foodAnalysis, input.MealType, input.FeelingBefore, input.FeelingAfter)
-		if err != nil {
-			fmt.Printf("Warning: failed to generate recommendations: %v\n", err)
-		} else {
-			foodJournal.AIRecommendations = *recommendations
+		if geminiService != nil {
+			recommendations, err := geminiService.GenerateRecommendations(foodAnalysis, input.MealType, input.FeelingBefore, input.FeelingAfter)
+			if err != nil {
+				fmt.Printf("Warning: failed to generate recommendations: %v\n", err)
+			} else {
+				foodJournal.AIRecommendations = *recommendations
+			}
 		}
 	}
 
-	return repository.CreateNewFoodJournal(foodJournal, input.UserID.String())
+	if err := repository.CreateNewFoodJournal(foodJournal, input.UserID.String()); err != nil {
+		return err
+	}
+	for _, imageID := range input.ImageIDs {
+		if err := repository.LinkStoredImage(imageID, schema.ImageOwnerFoodJournal, foodJournal.ID); err != nil {
+			fmt.Printf("Warning: failed to link image to food journal: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// SearchFoodJournal returns one page of the user's journal entries that match search, newest first
+func SearchFoodJournal(userID uuid.UUID, search schema.FoodJournalSearch, page schema.PageRequest) ([]schema.FoodJournal, schema.PageInfo, error) {
+	journals, err := repository.SearchFoodJournal(userID.String(), search, page)
+	if err != nil {
+		return nil, schema.PageInfo{}, err
+	}
+
+	journals, info := utils.TrimPage(journals, page.Limit, func(journal schema.FoodJournal) schema.PageCursor {
+		return schema.PageCursor{Keys: []time.Time{journal.CreatedAt}, ID: journal.ID}
+	})
+	return journals, info, nil
 }
 
 func UpdateFoodJournal(inputJournal schema.FoodJournal) error {
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFoodJournalNotOwned = errors.New("food journal does not belong to user")
+	ErrNoFoodAnalysis      = errors.New("food journal has no detected foods to edit")
+	ErrNothingToAnalyze    = errors.New("description is required to re-analyze this entry")
+	ErrInvalidFoodEdit     = errors.New("invalid food edit")
+)
+
+// EditDetectedFoods changes the portions of an entry's detected foods. Nutrition scales with the new
+// weight, then the meal totals and recommendations are rebuilt.
+func EditDetectedFoods(journalID string, userID uuid.UUID, edits []schema.DetectedFoodEdit) (*schema.FoodJournal, error) {
+	journal, analysis, err := getOwnedJournalAnalysis(journalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if analysis == nil || len(analysis.DetectedFoods) == 0 {
+		return nil, ErrNoFoodAnalysis
+	}
+
+	removed := make(map[int]bool)
+	for _, edit := range edits {
+		if edit.Index < 0 || edit.Index >= len(analysis.DetectedFoods) {
+			return nil, fmt.Errorf("%w: food index %d out of range", ErrInvalidFoodEdit, edit.Index)
+		}
+		food := &analysis.DetectedFoods[edit.Index]
+
+		if edit.Remove {
+			removed[edit.Index] = true
+			continue
+		}
+
+		if edit.Weight > 0 && edit.Weight != food.Weight {
+			if food.Weight <= 0 {
+				return nil, fmt.Errorf("%w: %s has no weight to scale from", ErrInvalidFoodEdit, food.Name)
+			}
+			food.Nutrition = scaleNutrition(food.Nutrition, edit.Weight/food.Weight)
+			food.Weight = edit.Weight
+			// The user weighed it, the estimate's range no longer applies
+			if food.WeightMin > 0 || food.WeightMax > 0 {
+				food.WeightMin, food.WeightMax = edit.Weight, edit.Weight
+			}
+			if edit.Portion == "" {
+				food.Portion = fmt.Sprintf("%.0f g", edit.Weight)
+			}
+		}
+		if edit.Portion != "" {
+			food.Portion = edit.Portion
+		}
+	}
+
+	foods := make([]schema.DetectedFood, 0, len(analysis.DetectedFoods))
+	for i, food := range analysis.DetectedFoods {
+		if !removed[i] {
+			foods = append(foods, food)
+		}
+	}
+	if len(foods) == 0 {
+		return nil, fmt.Errorf("%w: at least one food must remain, delete the entry instead", ErrInvalidFoodEdit)
+	}
+
+	analysis.DetectedFoods = foods
+	analysis.TotalNutrition = sumDetectedFoods(foods, analysis.Confidence)
+	analysis.Confidence = analysis.TotalNutrition.Confidence
+	refreshNutritionRange(analysis)
+
+	geminiService, err := NewGeminiService()
+	if err != nil {
+		fmt.Printf("Warning: recommendations cleared, Gemini unavailable: %v\n", err)
+		geminiService = nil
+	}
+	return journal, saveJournalAnalysis(journal, analysis, schema.RevisionReasonEditFoods, geminiService)
+}
+
+// ReanalyzeFoodJournal runs a fresh AI analysis of the entry, from a corrected description when given.
+// Foods logged from product labels are kept as they are.
+func ReanalyzeFoodJournal(journalID string, userID uuid.UUID, description string) (*schema.FoodJournal, error) {
+	journal, previous, err := getOwnedJournalAnalysis(journalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if description != "" {
+		journal.Description = description
+	} else if journal.ProcessedInput != "" {
+		description = journal.ProcessedInput
+	} else {
+		description = journal.Description
+	}
+	if description == "" {
+		return nil, ErrNothingToAnalyze
+	}
+
+	geminiService, err := NewGeminiService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Gemini service: %w", err)
+	}
+
+	analysis, err := geminiService.AnalyzeFoodFromText(description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze food from text: %w", err)
+	}
+	GroundDetectedFoods(analysis)
+
+	if previous != nil {
+		var labelFoods []schema.DetectedFood
+		for _, food := range previous.DetectedFoods {
+			if food.Source == schema.FoodSourceProduct {
+				labelFoods = append(labelFoods, food)
+			}
+		}
+		analysis = mergeProductFoods(analysis, labelFoods)
+	}
+
+	return journal, saveJournalAnalysis(journal, analysis, schema.RevisionReasonReanalyze, geminiService)
+}
+
+// GetFoodJournalHistory lists the earlier analyses of an entry, newest first
+// GetFoodJournalHistory returns one page of an entry's earlier versions, newest first
+func GetFoodJournalHistory(journalID string, userID uuid.UUID, page schema.PageRequest) ([]schema.FoodJournalRevision, schema.PageInfo, error) {
+	journal, err := repository.GetFoodJournalByID(journalID)
+	if err != nil {
+		return nil, schema.PageInfo{}, err
+	}
+	if journal.UserID != userID {
+		return nil, schema.PageInfo{}, ErrFoodJournalNotOwned
+	}
+
+	revisions, err := repository.GetFoodJournalRevisions(journalID, page)
+	if err != nil {
+		return nil, schema.PageInfo{}, err
+	}
+
+	revisions, info := utils.TrimPage(revisions, page.Limit, func(revision schema.FoodJournalRevision) schema.PageCursor {
+		return schema.PageCursor{Keys: []time.Time{revision.CreatedAt}, ID: revision.ID}
+	})
+	return revisions, info, nil
+}
+
+func getOwnedJournalAnalysis(journalID string, userID uuid.UUID) (*schema.FoodJournal, *schema.FoodAnalysis, error) {
+	journal, err := repository.GetFoodJournalByID(journalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if journal.UserID != userID {
+		return nil, nil, ErrFoodJournalNotOwned
+	}
+
+	if journal.FoodAnalysis == "" {
+		return journal, nil, nil
+	}
+	var analysis schema.FoodAnalysis
+	if err := json.Unmarshal([]byte(journal.FoodAnalysis), &analysis); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse stored food analysis: %w", err)
+	}
+	return journal, &analysis, nil
+}
+
+// saveJournalAnalysis replaces the entry's analysis, nutrition, feedback and recommendations together,
+// keeping the previous state as a revision. Without Gemini the recommendations are cleared rather than
+// left describing the old analysis.
+func saveJournalAnalysis(journal *schema.FoodJournal, analysis *schema.FoodAnalysis, reason string, geminiService *GeminiService) error {
+	revision := &schema.FoodJournalRevision{
+		FoodJournalID:     journal.ID,
+		UserID:            journal.UserID,
+		Reason:            reason,
+		Description:       journal.Description,
+		FoodAnalysis:      journal.FoodAnalysis,
+		AINutrition:       journal.AINutrition,
+		AIFeedback:        journal.AIFeedback,
+		AIRecommendations: journal.AIRecommendations,
+	}
+	// jsonb does not accept an empty string
+	if revision.FoodAnalysis == "" {
+		revision.FoodAnalysis = "null"
+	}
+
+	analysisBytes, err := json.Marshal(analysis)
+	if err != nil {
+		return fmt.Errorf("failed to marshal food analysis: %w", err)
+	}
+
+	journal.FoodAnalysis = string(analysisBytes)
+	journal.AINutrition = analysis.TotalNutrition
+	journal.AIFeedback = analysis.AnalysisText
+	journal.UpdatedAt = time.Now()
+
+	// Advice written for the old analysis no longer applies, it is only kept in the revision
+	journal.AIRecommendations = schema.AIRecommendations{}
+	if geminiService != nil {
+		recommendations, err := geminiService.GenerateRecommendations(analysis, journal.MealType, journal.FeelingBefore, journal.FeelingAfter)
+		if err != nil {
+			fmt.Printf("Warning: failed to generate recommendations, cleared: %v\n", err)
+		} else {
+			journal.AIRecommendations = *recommendations
+		}
+	}
+
+	return repository.UpdateFoodJournalAnalysis(*journal, revision)
+}
+
+func scaleNutrition(n schema.AINutrition, factor float64) schema.AINutrition {
+	return schema.AINutrition{
+		Calories:   n.Calories * factor,
+		Protein:    n.Protein * factor,
+		Carbs:      n.Carbs * factor,
+		Fat:        n.Fat * factor,
+		Sugar:      n.Sugar * factor,
+		Fiber:      n.Fiber * factor,
+		Sodium:     n.Sodium * factor,
+		Confidence: n.Confidence,
+	}
+}
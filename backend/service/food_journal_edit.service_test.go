@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+func TestEditDetectedFoodsWithoutGemini(t *testing.T) {
+	useTestDatabase(t)
+	t.Setenv("GEMINI_API_KEY", "")
+	userID := createTestUser(t, "")
+
+	analysis, err := json.Marshal(schema.FoodAnalysis{
+		DetectedFoods: []schema.DetectedFood{
+			{Name: "Nasi putih", Portion: "1 piring", Weight: 200, Nutrition: schema.AINutrition{Calories: 260, Carbs: 56}},
+		},
+		TotalNutrition: schema.AINutrition{Calories: 260, Carbs: 56},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recommendations := schema.AIRecommendations{NextMealSuggestion: "Tambahkan sayur", NutritionTips: "Karbohidrat cukup"}
+	journal := schema.FoodJournal{
+		UserID:            userID,
+		MealName:          "Makan siang",
+		AINutrition:       schema.AINutrition{Calories: 260, Carbs: 56},
+		AIRecommendations: recommendations,
+		FoodAnalysis:      string(analysis),
+	}
+	if err := database.DB.Create(&journal).Error; err != nil {
+		t.Fatalf("create journal: %v", err)
+	}
+
+	edited, err := EditDetectedFoods(journal.ID.String(), userID, []schema.DetectedFoodEdit{{Index: 0, Weight: 100}})
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if edited.AINutrition.Calories != 130 {
+		t.Errorf("calories = %v, want the halved portion", edited.AINutrition.Calories)
+	}
+
+	stored, err := repository.GetFoodJournalByID(journal.ID.String())
+	if err != nil {
+		t.Fatalf("get journal: %v", err)
+	}
+	if stored.AIRecommendations != (schema.AIRecommendations{}) {
+		t.Errorf("recommendations = %+v, want them cleared for the new analysis", stored.AIRecommendations)
+	}
+
+	revisions, _, err := GetFoodJournalHistory(journal.ID.String(), userID, schema.PageRequest{Limit: schema.DefaultPageLimit})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(revisions) != 1 {
+		t.Fatalf("got %d revisions, want 1", len(revisions))
+	}
+	if revisions[0].AIRecommendations != recommendations || revisions[0].AINutrition.Calories != 260 {
+		t.Errorf("revision = %+v, want the previous nutrition and recommendations", revisions[0])
+	}
+}
@@ -6,11 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"os"
 	"strings"
 
+	"github.com/andi-frame/TeamName_KulkasKu/backend/constants"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 )
@@ -32,12 +32,8 @@ func NewGeminiService() (*GeminiService, error) {
 	}, nil
 }
 
-func (s *GeminiService) PredictItem(file multipart.File, header *multipart.FileHeader) (*schema.PredictResponse, error) {
-	imgBytes, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read image file: %w", err)
-	}
-
+// PredictItem expects an image already prepared by utils.PrepareImage
+func (s *GeminiService) PredictItem(imgBytes []byte, mimeType string) (*schema.PredictResponse, error) {
 	imgBase64 := base64.StdEncoding.EncodeToString(imgBytes)
 
 	reqBody := &geminiRequest{
@@ -49,7 +45,7 @@ func (s *GeminiService) PredictItem(file multipart.File, header *multipart.FileH
 					},
 					{
 						InlineData: &geminiInlineData{
-							MimeType: header.Header.Get("Content-Type"),
+							MimeType: mimeType,
 							Data:     imgBase64,
 						},
 					},
@@ -206,14 +202,11 @@ func (s *GeminiService) AnalyzeFoodFromText(description string) (*schema.FoodAna
 	return &foodAnalysis, nil
 }
 
-func (s *GeminiService) AnalyzeFoodFromImage(file multipart.File, header *multipart.FileHeader) (*schema.FoodAnalysis, error) {
-	imgBytes, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read image file: %w", err)
-	}
-
+// AnalyzeFoodFromImage expects an image already prepared by utils.PrepareImage, referenceHint
+// describes an object of known size in the photo and may be empty
+func (s *GeminiService) AnalyzeFoodFromImage(imgBytes []byte, mimeType string, referenceHint string) (*schema.FoodAnalysis, error) {
 	imgBase64 := base64.StdEncoding.EncodeToString(imgBytes)
-	prompt := createFoodAnalysisPrompt("", "image")
+	prompt := createFoodAnalysisPrompt("", "image") + createPortionPrompt(referenceHint)
 
 	reqBody := &geminiRequest{
 		Contents: []geminiContent{
@@ -224,7 +217,7 @@ func (s *GeminiService) AnalyzeFoodFromImage(file multipart.File, header *multip
 					},
 					{
 						InlineData: &geminiInlineData{
-							MimeType: header.Header.Get("Content-Type"),
+							MimeType: mimeType,
 							Data:     imgBase64,
 						},
 					},
@@ -278,6 +271,80 @@ func (s *GeminiService) AnalyzeFoodFromImage(file multipart.File, header *multip
 	return &foodAnalysis, nil
 }
 
+// MealImage is one prepared photo of a meal, Kind is one of the schema.MealPhoto kinds
+type MealImage struct {
+	Data     []byte
+	MimeType string
+	Kind     string
+}
+
+// AnalyzeMealFromImages sends all photos and the description in one multimodal request
+func (s *GeminiService) AnalyzeMealFromImages(images []MealImage, description string, referenceHint string) (*schema.FoodAnalysis, error) {
+	input := "Tidak ada deskripsi tambahan."
+	if description != "" {
+		input = "Deskripsi dari pengguna: " + description
+	}
+
+	parts := []geminiPart{{Text: createFoodAnalysisPrompt(input, "meal") + createPortionPrompt(referenceHint)}}
+	for i, image := range images {
+		parts = append(parts,
+			geminiPart{Text: fmt.Sprintf("Foto %d (%s):", i+1, image.Kind)},
+			geminiPart{InlineData: &geminiInlineData{
+				MimeType: image.MimeType,
+				Data:     base64.StdEncoding.EncodeToString(image.Data),
+			}},
+		)
+	}
+
+	reqBody := &geminiRequest{
+		Contents: []geminiContent{{Parts: parts}},
+		GenerationConfig: &geminiGenerationConfig{
+			ResponseMIMEType: "application/json",
+		},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", constants.GeminiAPIBaseURL, constants.GeminiModel, s.apiKey)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(constants.ContentTypeHeader, constants.ApplicationJSON)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Gemini API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Gemini API returned non-200 status code: %d %s", resp.StatusCode, string(body))
+	}
+
+	var geminiResp geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode Gemini API response: %w", err)
+	}
+
+	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("unexpected Gemini API response format")
+	}
+
+	cleanedJSON := utils.CleanGeminiResponse(geminiResp.Candidates[0].Content.Parts[0].Text)
+
+	var foodAnalysis schema.FoodAnalysis
+	if err := json.Unmarshal([]byte(cleanedJSON), &foodAnalysis); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal food analysis response: %w", err)
+	}
+
+	return &foodAnalysis, nil
+}
+
 func (s *GeminiService) GenerateRecommendations(foodAnalysis *schema.FoodAnalysis, mealType string, feelingBefore string, feelingAfter string) (*schema.AIRecommendations, error) {
 	prompt := createRecommendationPrompt(foodAnalysis, mealType, feelingBefore, feelingAfter)
 
@@ -355,11 +422,35 @@ Petunjuk analisis:
 		return fmt.Sprintf("%s\n\nDeskripsi makanan: %s", basePrompt, input)
 	case "image":
 		return fmt.Sprintf("%s\n\nAnalisis gambar makanan yang diberikan.", basePrompt)
+	case "meal":
+		return fmt.Sprintf(`%s
+
+Beberapa foto dari satu kali makan diberikan, masing-masing diawali label "Foto N (jenis)".
+Jenis foto: plate = makanan di piring (bisa dari sudut berbeda), menu = daftar menu atau struk, label = label informasi nilai gizi kemasan.
+- Gabungkan semua foto menjadi SATU analisis, makanan yang sama dari sudut berbeda hanya dihitung sekali
+- Gunakan foto menu untuk memastikan nama makanan dan foto label untuk nilai gizi per sajian
+- Tambahkan field "attribution" pada setiap item detected_foods:
+  "attribution": {"photos": [nomor foto tempat makanan terlihat], "description": true jika disebut dalam deskripsi, "note": "sumber nilai gizi, contoh: dari label foto 3"}
+%s`, basePrompt, input)
 	default:
 		return fmt.Sprintf("%s\n\nInput: %s", basePrompt, input)
 	}
 }
 
+// createPortionPrompt asks for a weight range per food, sized against the reference object when there is one
+func createPortionPrompt(referenceHint string) string {
+	prompt := `
+
+Estimasi porsi:
+- Tambahkan field "weight_min" dan "weight_max" (float, gram) pada setiap item detected_foods sebagai rentang berat yang masuk akal, "weight" adalah estimasi terbaik di antaranya
+- Nilai nutrition tetap dihitung untuk "weight"`
+	if referenceHint != "" {
+		prompt += fmt.Sprintf(`
+- Di foto terdapat benda acuan ukuran: %s. Bandingkan ukuran makanan dengan benda ini untuk memperkirakan volume dan berat, lalu persempit rentang weight_min sampai weight_max`, referenceHint)
+	}
+	return prompt
+}
+
 func createRecommendationPrompt(foodAnalysis *schema.FoodAnalysis, mealType string, feelingBefore string, feelingAfter string) string {
 	var nutritionInfo string
 	var foodInfo string
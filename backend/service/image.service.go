@@ -0,0 +1,248 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/config"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImageNotOwned      = errors.New("image does not belong to user")
+	ErrImageAlreadyLinked = errors.New("image is already linked to another record")
+)
+
+type ImageService struct {
+	storage      ObjectStorage
+	urlTTL       time.Duration
+	maxFileSize  int64
+	maxDimension int
+}
+
+func NewImageService(cfg config.Config) (*ImageService, error) {
+	storage, err := NewObjectStorage(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageService{
+		storage:      storage,
+		urlTTL:       cfg.Storage.URLTTL,
+		maxFileSize:  cfg.MaxFileSize,
+		maxDimension: cfg.ImageMaxDim,
+	}, nil
+}
+
+// ImageUpload is a validated upload, Original is what gets stored and Prepared what the AI sees
+type ImageUpload struct {
+	Filename string
+	Original []byte
+	Prepared *utils.ProcessedImage
+}
+
+// ReadUpload checks the uploaded file by its content and prepares it for AI calls.
+// Rejected files return a *utils.ImageError.
+func (s *ImageService) ReadUpload(file multipart.File, header *multipart.FileHeader) (*ImageUpload, error) {
+	if header.Size > s.maxFileSize {
+		return nil, utils.ErrImageTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(file, s.maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image file: %w", err)
+	}
+	if int64(len(data)) > s.maxFileSize {
+		return nil, utils.ErrImageTooLarge
+	}
+
+	prepared, err := utils.PrepareImage(data, s.maxDimension)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageUpload{
+		Filename: header.Filename,
+		Original: data,
+		Prepared: prepared,
+	}, nil
+}
+
+// Storage is the backend the images are kept in
+func (s *ImageService) Storage() ObjectStorage {
+	return s.storage
+}
+
+// StoreImage saves an upright, metadata-free copy of the photo and a thumbnail, not yet linked to anything.
+// HEIC and HEIF cannot be decoded here, they are stored as uploaded and serve as their own thumbnail.
+func (s *ImageService) StoreImage(userID uuid.UUID, data []byte) (*schema.StoredImage, error) {
+	contentType, err := utils.DetectImageType(data)
+	if err != nil {
+		return nil, err
+	}
+	if utils.IsHEIF(contentType) {
+		return s.storeHEIF(userID, data, contentType)
+	}
+
+	processed, err := utils.NormalizeImage(data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	thumbnail, err := utils.Thumbnail(processed.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail: %w", err)
+	}
+
+	image := &schema.StoredImage{
+		UserID:      userID,
+		ContentType: processed.ContentType,
+		Width:       processed.Width,
+		Height:      processed.Height,
+		Size:        int64(len(processed.Data)),
+	}
+	image.ID = uuid.New()
+	image.Key = fmt.Sprintf("images/%s/%s%s", userID, image.ID, imageExtension(processed.ContentType))
+	image.ThumbnailKey = fmt.Sprintf("images/%s/%s_thumb.jpg", userID, image.ID)
+
+	if err := s.storage.Put(image.Key, processed.Data, processed.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+	if err := s.storage.Put(image.ThumbnailKey, thumbnail.Data, thumbnail.ContentType); err != nil {
+		s.deleteObjects(image)
+		return nil, fmt.Errorf("failed to upload thumbnail: %w", err)
+	}
+	if err := repository.CreateStoredImage(image); err != nil {
+		s.deleteObjects(image)
+		return nil, fmt.Errorf("failed to save image: %w", err)
+	}
+
+	return image, s.SignImage(image)
+}
+
+func (s *ImageService) storeHEIF(userID uuid.UUID, data []byte, contentType string) (*schema.StoredImage, error) {
+	width, height := utils.HEIFDimensions(data)
+	if width == 0 || height == 0 {
+		return nil, utils.ErrImageCorrupt
+	}
+
+	image := &schema.StoredImage{
+		UserID:      userID,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+		Size:        int64(len(data)),
+	}
+	image.ID = uuid.New()
+	image.Key = fmt.Sprintf("images/%s/%s%s", userID, image.ID, imageExtension(contentType))
+	image.ThumbnailKey = image.Key
+
+	if err := s.storage.Put(image.Key, data, contentType); err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+	if err := repository.CreateStoredImage(image); err != nil {
+		s.deleteObjects(image)
+		return nil, fmt.Errorf("failed to save image: %w", err)
+	}
+
+	return image, s.SignImage(image)
+}
+
+// GetImage returns one of the user's images with fresh signed links
+func (s *ImageService) GetImage(imageID string, userID uuid.UUID) (*schema.StoredImage, error) {
+	image, err := repository.GetStoredImageByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if image.UserID != userID {
+		return nil, ErrImageNotOwned
+	}
+	return image, s.SignImage(image)
+}
+
+// GetOwnerImages returns the images linked to a journal entry, receipt or item
+func (s *ImageService) GetOwnerImages(userID uuid.UUID, ownerType string, ownerID uuid.UUID) ([]schema.StoredImage, error) {
+	images, err := repository.GetStoredImagesByOwner(userID, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range images {
+		if err := s.SignImage(&images[i]); err != nil {
+			return nil, err
+		}
+	}
+	return images, nil
+}
+
+// SignImage fills the time-limited links of the image and its thumbnail
+func (s *ImageService) SignImage(image *schema.StoredImage) error {
+	url, err := s.storage.SignedURL(image.Key, s.urlTTL)
+	if err != nil {
+		return err
+	}
+	thumbnailURL, err := s.storage.SignedURL(image.ThumbnailKey, s.urlTTL)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.urlTTL)
+	image.URL = url
+	image.ThumbnailURL = thumbnailURL
+	image.ExpiresAt = &expiresAt
+	return nil
+}
+
+func (s *ImageService) deleteObjects(image *schema.StoredImage) {
+	keys := []string{image.Key}
+	if image.ThumbnailKey != image.Key {
+		keys = append(keys, image.ThumbnailKey)
+	}
+	for _, key := range keys {
+		if err := s.storage.Delete(key); err != nil {
+			fmt.Printf("Warning: failed to delete stored object %s: %v\n", key, err)
+		}
+	}
+}
+
+// CheckImageLinkable makes sure the user uploaded the image and it is free, or already on this owner
+func CheckImageLinkable(imageID, userID uuid.UUID, ownerType string, ownerID uuid.UUID) error {
+	image, err := repository.GetStoredImageByID(imageID.String())
+	if err != nil {
+		return err
+	}
+	if image.UserID != userID {
+		return ErrImageNotOwned
+	}
+	if image.OwnerID != nil && (image.OwnerType != ownerType || *image.OwnerID != ownerID) {
+		return ErrImageAlreadyLinked
+	}
+	return nil
+}
+
+// LinkImage attaches an uploaded image to the journal entry, receipt or item it was taken for
+func LinkImage(imageID, userID uuid.UUID, ownerType string, ownerID uuid.UUID) error {
+	if err := CheckImageLinkable(imageID, userID, ownerType, ownerID); err != nil {
+		return err
+	}
+	return repository.LinkStoredImage(imageID, ownerType, ownerID)
+}
+
+func imageExtension(contentType string) string {
+	return "." + strings.TrimPrefix(contentType, "image/")
+}
+
+// StoredContentType is the content type of a stored HEIC or HEIF file by its key, empty for the
+// other images whose extensions Go already knows
+func StoredContentType(key string) string {
+	switch path.Ext(key) {
+	case ".heic":
+		return "image/heic"
+	case ".heif":
+		return "image/heif"
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+)
+
+func newTestImageService(t *testing.T) (*ImageService, *LocalStorage) {
+	t.Helper()
+	storage := newTestLocalStorage(t)
+	return &ImageService{storage: storage, urlTTL: time.Hour, maxFileSize: 10 << 20, maxDimension: 1024}, storage
+}
+
+func TestStoreImageKeepsHEIC(t *testing.T) {
+	useTestDatabase(t)
+	images, storage := newTestImageService(t)
+	heic := append([]byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic\x00\x00\x00\x14ispe\x00\x00\x00\x00"), 0, 0, 0x0f, 0xc0, 0, 0, 0x0b, 0xd0)
+
+	image, err := images.StoreImage(createTestUser(t, ""), heic)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if image.ContentType != "image/heic" || image.Width != 4032 || image.Height != 3024 {
+		t.Errorf("stored %s %dx%d, want the 4032x3024 HEIC", image.ContentType, image.Width, image.Height)
+	}
+	if !strings.HasSuffix(image.Key, ".heic") || image.ThumbnailKey != image.Key {
+		t.Errorf("keys %s and %s, want one .heic object as its own thumbnail", image.Key, image.ThumbnailKey)
+	}
+	if StoredContentType(image.Key) != "image/heic" {
+		t.Errorf("%s would be served as %q", image.Key, StoredContentType(image.Key))
+	}
+
+	path, err := storage.Path(image.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, heic) {
+		t.Errorf("stored file differs from the upload: %v", err)
+	}
+}
+
+func TestStoreImageReencodesWebP(t *testing.T) {
+	useTestDatabase(t)
+	images, storage := newTestImageService(t)
+	webp, err := os.ReadFile("../utils/testdata/video-001.lossy.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image, err := images.StoreImage(createTestUser(t, ""), webp)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if image.ContentType != "image/jpeg" || image.Width != 150 || image.Height != 103 {
+		t.Errorf("stored %s %dx%d, want a 150x103 JPEG", image.ContentType, image.Width, image.Height)
+	}
+
+	for _, key := range []string{image.Key, image.ThumbnailKey} {
+		path, err := storage.Path(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", key, err)
+		}
+		if contentType, _ := utils.DetectImageType(data); contentType != "image/jpeg" {
+			t.Errorf("%s is %q, want image/jpeg", key, contentType)
+		}
+	}
+}
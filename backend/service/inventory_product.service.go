@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+// ProductStock is a product with its batches folded into a single stock line
+type ProductStock struct {
+	ProductID      *uuid.UUID
+	Name           string
+	Type           string
+	AmountType     string
+	TotalAmount    float64
+	BatchCount     int
+	NearestExpDate *time.Time
+	Batches        []schema.Item
+}
+
+// GroupItemsByProduct folds batches into product stock lines, keeping the batch order given.
+// Items not yet linked to a product are grouped by normalized name.
+func GroupItemsByProduct(items []schema.Item) []ProductStock {
+	var groups []ProductStock
+	index := map[string]int{}
+
+	for _, item := range items {
+		key := "name:" + utils.NormalizeName(item.Name)
+		if item.ProductID != nil {
+			key = "id:" + item.ProductID.String()
+		}
+
+		i, ok := index[key]
+		if !ok {
+			groups = append(groups, ProductStock{
+				ProductID:  item.ProductID,
+				Name:       item.Name,
+				Type:       item.Type,
+				AmountType: item.AmountType,
+			})
+			i = len(groups) - 1
+			index[key] = i
+		}
+
+		expDate := itemExpDate(item)
+		group := &groups[i]
+		group.TotalAmount += item.Amount
+		group.BatchCount++
+		if group.NearestExpDate == nil || expDate.Before(*group.NearestExpDate) {
+			group.NearestExpDate = &expDate
+		}
+		group.Batches = append(group.Batches, item)
+	}
+
+	return groups
+}
+
+func GetInventoryProducts(userID uuid.UUID) ([]ProductStock, error) {
+	products, err := repository.GetAllInventoryProductByUserID(userID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var stocks []ProductStock
+	for _, product := range products {
+		if len(product.Batches) == 0 {
+			continue
+		}
+		grouped := GroupItemsByProduct(product.Batches)
+		stock := grouped[0]
+		stock.Name = product.Name
+		stock.Type = product.Type
+		stock.AmountType = product.AmountType
+		stocks = append(stocks, stock)
+	}
+
+	sort.SliceStable(stocks, func(i, j int) bool {
+		return stocks[i].NearestExpDate.Before(*stocks[j].NearestExpDate)
+	})
+
+	return stocks, nil
+}
+
+func GetProductBatches(productID string, userID uuid.UUID) ([]schema.Item, error) {
+	product, err := repository.GetInventoryProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.UserID != userID {
+		return nil, ErrItemNotOwned
+	}
+
+	return repository.GetBatchesByProductID(productID)
+}
+
+// ConsumeProduct takes amount out of a product, earliest-expiring batch first
+func ConsumeProduct(productID string, userID uuid.UUID, amount float64) ([]schema.Item, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+
+	product, err := repository.GetInventoryProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.UserID != userID {
+		return nil, ErrItemNotOwned
+	}
+
+	return repository.ConsumeProductFIFO(productID, amount, UserClock(userID))
+}
+
+// MatchInventoryProduct finds the product a free-text name most likely refers to.
+// Exact normalized names score 1, otherwise the score is the share of overlapping words.
+func MatchInventoryProduct(name string, products []schema.InventoryProduct) (*schema.InventoryProduct, float64) {
+	const minScore = 0.5
+
+	normalized := utils.NormalizeName(name)
+	words := strings.Fields(normalized)
+
+	var best *schema.InventoryProduct
+	var bestScore float64
+	for i := range products {
+		product := &products[i]
+		if product.NormalizedName == normalized {
+			return product, 1
+		}
+
+		score := wordOverlap(words, strings.Fields(product.NormalizedName))
+		if score > bestScore {
+			best, bestScore = product, score
+		}
+	}
+
+	if bestScore < minScore {
+		return nil, 0
+	}
+	return best, bestScore
+}
+
+func wordOverlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, word := range a {
+		set[word] = true
+	}
+
+	shared := 0
+	for _, word := range b {
+		if set[word] {
+			shared++
+		}
+	}
+
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	return float64(shared) / float64(longest)
+}
+
+func itemExpDate(item schema.Item) time.Time {
+	if item.EffectiveExpDate != nil {
+		return *item.EffectiveExpDate
+	}
+	return item.ExpDate
+}
@@ -3,14 +3,18 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
 
 	"github.com/google/uuid"
 )
 
+var ErrItemNotOwned = errors.New("item does not belong to user")
+
 type ItemInput struct {
 	ID         uuid.UUID
 	UserID     uuid.UUID
@@ -21,6 +25,7 @@ type ItemInput struct {
 	Desc       string
 	StartDate  string // yyyy-mm-dd
 	ExpDate    string // yyyy-mm-dd
+	ImageID    *uuid.UUID
 }
 
 func CreateNewItem(input ItemInput) error {
@@ -39,8 +44,15 @@ func CreateNewItem(input ItemInput) error {
 		desc = &input.Desc
 	}
 
+	// Same-named purchases become batches of one product
+	product, err := repository.FindOrCreateInventoryProduct(input.UserID, input.Name, input.Type, input.AmountType)
+	if err != nil {
+		return err
+	}
+
 	item := schema.Item{
 		UserID:     input.UserID,
+		ProductID:  &product.ID,
 		Name:       input.Name,
 		Type:       input.Type,
 		Amount:     input.Amount,
@@ -48,15 +60,114 @@ func CreateNewItem(input ItemInput) error {
 		Desc:       desc,
 		StartDate:  startDate,
 		ExpDate:    expDate,
+		ImageID:    input.ImageID,
 	}
 
-	return repository.CreateNewItem(item, input.UserID.String())
+	// The id is needed up front to link the photo
+	item.ID = uuid.New()
+	if input.ImageID != nil {
+		if err := CheckImageLinkable(*input.ImageID, input.UserID, schema.ImageOwnerItem, item.ID); err != nil {
+			return fmt.Errorf("invalid image: %w", err)
+		}
+	}
+
+	if err := repository.CreateNewItem(item, input.UserID.String()); err != nil {
+		return err
+	}
+	if input.ImageID != nil {
+		if err := repository.LinkStoredImage(*input.ImageID, schema.ImageOwnerItem, item.ID); err != nil {
+			fmt.Printf("Warning: failed to link image to item: %v\n", err)
+		}
+	}
+	return nil
 }
 
-func UpdateItem(inputItem schema.Item) error {
+func UpdateItem(inputItem schema.Item, userID uuid.UUID) error {
+	existing, err := repository.GetItemByID(inputItem.ID.String())
+	if err != nil {
+		return err
+	}
+	if existing.UserID != userID {
+		return ErrItemNotOwned
+	}
+
+	// Renaming a batch moves it to the product with the new name
+	inputItem.ProductID = existing.ProductID
+	if existing.ProductID == nil || utils.NormalizeName(existing.Name) != utils.NormalizeName(inputItem.Name) {
+		product, err := repository.FindOrCreateInventoryProduct(existing.UserID, inputItem.Name, inputItem.Type, inputItem.AmountType)
+		if err != nil {
+			return err
+		}
+		inputItem.ProductID = &product.ID
+	}
+
+	// Keep the opened state and recompute its expiry against the new dates/type
+	if existing.OpenedAt != nil {
+		effectiveExpDate := EffectiveExpDate(inputItem.ExpDate, inputItem.Type, existing.OpenedAt)
+		inputItem.EffectiveExpDate = &effectiveExpDate
+	}
+
 	return repository.UpdateItem(inputItem)
 }
 
-func DeleteItem(itemID string) error {
+// OpenItem marks an item as opened and shortens its expiry to the after-opening shelf life
+func OpenItem(itemID string, userID uuid.UUID, openedAtStr string) (*schema.Item, error) {
+	item, err := repository.GetItemByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, ErrItemNotOwned
+	}
+
+	openedAt := time.Now()
+	if openedAtStr != "" {
+		openedAt, err = time.Parse("2006-01-02", openedAtStr)
+		if err != nil {
+			return nil, errors.New("invalid opened at date format")
+		}
+	}
+
+	effectiveExpDate := EffectiveExpDate(item.ExpDate, item.Type, &openedAt)
+	if err := repository.UpdateItemOpenedState(itemID, &openedAt, &effectiveExpDate); err != nil {
+		return nil, err
+	}
+
+	item.OpenedAt = &openedAt
+	item.EffectiveExpDate = &effectiveExpDate
+	return item, nil
+}
+
+func DeleteItem(itemID string, userID uuid.UUID) error {
+	item, err := repository.GetItemByID(itemID)
+	if err != nil {
+		return err
+	}
+	if item.UserID != userID {
+		return ErrItemNotOwned
+	}
 	return repository.DeleteItem(itemID)
 }
+
+// SearchItems returns one page of the user's batches matching search, with the expiry clock taken
+// from the user's timezone
+func SearchItems(userID uuid.UUID, search schema.ItemSearch, page schema.PageRequest) ([]schema.Item, schema.PageInfo, error) {
+	items, err := repository.SearchItems(userID.String(), search, UserClock(userID), &page)
+	if err != nil {
+		return nil, schema.PageInfo{}, err
+	}
+
+	items, info := utils.TrimPage(items, page.Limit, func(item schema.Item) schema.PageCursor {
+		expiry := item.ExpDate
+		if item.EffectiveExpDate != nil {
+			expiry = *item.EffectiveExpDate
+		}
+		return schema.PageCursor{Keys: []time.Time{expiry}, ID: item.ID}
+	})
+	return items, info, nil
+}
+
+// SearchAllItems returns every batch matching search, for views that fold batches into products
+func SearchAllItems(userID uuid.UUID, search schema.ItemSearch) ([]schema.Item, error) {
+	return repository.SearchItems(userID.String(), search, UserClock(userID), nil)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+func TestLinkUnassignedItems(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "")
+
+	product, err := repository.FindOrCreateInventoryProduct(userID, "Susu UHT", "Susu", "liter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().Truncate(24 * time.Hour)
+	legacy := []schema.Item{
+		{UserID: userID, Name: "susu  uht", Type: "Susu", Amount: 1, AmountType: "liter", StartDate: today, ExpDate: today},
+		{UserID: userID, Name: "Telur", Type: "Telur", Amount: 10, AmountType: "butir", StartDate: today, ExpDate: today},
+	}
+	if err := database.DB.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.LinkUnassignedItems(); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+
+	milk, err := repository.GetItemByID(legacy[0].ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if milk.ProductID == nil || *milk.ProductID != product.ID {
+		t.Errorf("milk linked to %v, want the existing product %s", milk.ProductID, product.ID)
+	}
+	eggs, err := repository.GetItemByID(legacy[1].ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eggs.ProductID == nil || *eggs.ProductID == product.ID {
+		t.Errorf("eggs linked to %v, want a product of their own", eggs.ProductID)
+	}
+}
+
+func TestUpdateAndDeleteItemOfAnotherUser(t *testing.T) {
+	useTestDatabase(t)
+	ownerID := createTestUser(t, "")
+	otherID := createTestUser(t, "")
+
+	today := time.Now().Truncate(24 * time.Hour)
+	item := schema.Item{UserID: ownerID, Name: "Telur", Type: "Telur", Amount: 10, AmountType: "butir", StartDate: today, ExpDate: today}
+	if err := database.DB.Create(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	renamed := item
+	renamed.Name = "Telur Asin"
+	renamed.Amount = 1
+	if err := UpdateItem(renamed, otherID); !errors.Is(err, ErrItemNotOwned) {
+		t.Errorf("update by another user: err = %v, want ErrItemNotOwned", err)
+	}
+	if err := DeleteItem(item.ID.String(), otherID); !errors.Is(err, ErrItemNotOwned) {
+		t.Errorf("delete by another user: err = %v, want ErrItemNotOwned", err)
+	}
+
+	stored, err := repository.GetItemByID(item.ID.String())
+	if err != nil {
+		t.Fatalf("item gone after a foreign delete: %v", err)
+	}
+	if stored.Name != "Telur" || stored.Amount != 10 {
+		t.Errorf("item changed to %s x%v by a foreign update", stored.Name, stored.Amount)
+	}
+	var products int64
+	database.DB.Model(&schema.InventoryProduct{}).Where("user_id IN ?", []any{ownerID, otherID}).Where("name = ?", "Telur Asin").Count(&products)
+	if products != 0 {
+		t.Errorf("foreign update created %d inventory products", products)
+	}
+
+	if err := DeleteItem(item.ID.String(), ownerID); err != nil {
+		t.Errorf("delete by owner: %v", err)
+	}
+}
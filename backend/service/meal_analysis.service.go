@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+// Photos per meal analysis, more would push the request past Gemini's inline data limit
+const MaxMealPhotos = 5
+
+// MealPhotoKind reads a client's photo label, unknown labels count as other and empty ones as the plate
+func MealPhotoKind(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "":
+		return schema.MealPhotoPlate
+	case schema.MealPhotoPlate, schema.MealPhotoMenu, schema.MealPhotoLabel:
+		return kind
+	default:
+		return schema.MealPhotoOther
+	}
+}
+
+// AnalyzeMeal analyzes several photos of one meal plus an optional description in a single request.
+// photos describes the images in the same order and is kept on the analysis for attribution,
+// reference is an optional object of known size shown in the photos, described by referenceHint.
+func AnalyzeMeal(images []MealImage, photos []schema.MealPhoto, description string, reference *schema.PortionReference, referenceHint string) (*schema.FoodAnalysis, error) {
+	geminiService, err := NewGeminiService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Gemini service: %w", err)
+	}
+
+	analysis, err := geminiService.AnalyzeMealFromImages(images, description, referenceHint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze meal photos: %w", err)
+	}
+
+	for i := range analysis.DetectedFoods {
+		food := &analysis.DetectedFoods[i]
+		food.Source = schema.FoodSourceAI
+		food.Attribution = cleanAttribution(food.Attribution, len(images), description != "")
+	}
+	analysis.TotalNutrition = sumDetectedFoods(analysis.DetectedFoods, analysis.Confidence)
+	analysis.Photos = photos
+	GroundDetectedFoods(analysis)
+	ApplyPortionBounds(analysis, reference)
+
+	return analysis, nil
+}
+
+// cleanAttribution drops photo numbers the model made up and text credit when there was no text
+func cleanAttribution(attribution *schema.FoodAttribution, photoCount int, hasDescription bool) *schema.FoodAttribution {
+	cleaned := &schema.FoodAttribution{Photos: []int{}}
+	if attribution == nil {
+		return cleaned
+	}
+
+	for _, number := range attribution.Photos {
+		if number >= 1 && number <= photoCount && !slices.Contains(cleaned.Photos, number) {
+			cleaned.Photos = append(cleaned.Photos, number)
+		}
+	}
+	slices.Sort(cleaned.Photos)
+	cleaned.Description = attribution.Description && hasDescription
+	cleaned.Note = strings.TrimSpace(attribution.Note)
+	return cleaned
+}
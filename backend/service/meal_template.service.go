@@ -0,0 +1,280 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMealTemplateNotOwned = errors.New("meal template does not belong to user")
+	ErrInvalidMealTemplate  = errors.New("invalid meal template")
+)
+
+// Meals logged from a template cannot be further ahead than this, to allow for clock drift
+const templateFutureTolerance = 5 * time.Minute
+
+// CreateMealTemplate saves a meal for re-logging, copied from a journal entry or composed from foods and
+// products. No AI is involved: manual foods need their nutrition, or a weight of a food in the composition table.
+func CreateMealTemplate(userID uuid.UUID, input schema.MealTemplateCreate) (*schema.MealTemplate, error) {
+	template := &schema.MealTemplate{
+		UserID:      userID,
+		Name:        strings.TrimSpace(input.Name),
+		MealType:    input.MealType,
+		Description: input.Description,
+	}
+
+	var analysis *schema.FoodAnalysis
+	if input.JournalID != "" {
+		journal, journalAnalysis, err := getOwnedJournalAnalysis(input.JournalID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if journalAnalysis == nil || len(journalAnalysis.DetectedFoods) == 0 {
+			return nil, ErrNoFoodAnalysis
+		}
+		analysis = journalAnalysis
+		template.SourceJournalID = &journal.ID
+		if template.Name == "" {
+			template.Name = journal.MealName
+		}
+		if template.MealType == "" {
+			template.MealType = journal.MealType
+		}
+		if template.Description == "" {
+			template.Description = journal.Description
+		}
+	} else {
+		var err error
+		analysis, err = composeMealAnalysis(input.Foods, input.ProductItems)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if template.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidMealTemplate)
+	}
+
+	analysisBytes, err := json.Marshal(analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal food analysis: %w", err)
+	}
+	template.FoodAnalysis = string(analysisBytes)
+	template.AINutrition = analysis.TotalNutrition
+
+	if err := repository.CreateMealTemplate(template); err != nil {
+		return nil, err
+	}
+	template.Analysis = analysis
+	return template, nil
+}
+
+// composeMealAnalysis builds the analysis of a manually entered meal
+func composeMealAnalysis(entered []schema.DetectedFood, products []schema.ProductPortion) (*schema.FoodAnalysis, error) {
+	if len(entered) == 0 && len(products) == 0 {
+		return nil, fmt.Errorf("%w: add at least one food or product", ErrInvalidMealTemplate)
+	}
+
+	foods := make([]schema.DetectedFood, 0, len(entered))
+	var names []string
+	for _, food := range entered {
+		food.Name = strings.TrimSpace(food.Name)
+		if food.Name == "" {
+			return nil, fmt.Errorf("%w: every food needs a name", ErrInvalidMealTemplate)
+		}
+		food.Source = schema.FoodSourceManual
+		food.WeightMin, food.WeightMax = 0, 0
+		food.Attribution = nil
+		food.Composition = nil
+
+		if food.Nutrition.Calories <= 0 {
+			if food.Weight <= 0 {
+				return nil, fmt.Errorf("%w: %s needs its nutrition or a weight", ErrInvalidMealTemplate, food.Name)
+			}
+			match, err := MatchFoodComposition(food.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up %s: %w", food.Name, err)
+			}
+			if match == nil {
+				return nil, fmt.Errorf("%w: %s is not in the food composition table, enter its nutrition", ErrInvalidMealTemplate, food.Name)
+			}
+			food.Nutrition = compositionNutrition(&match.FoodComposition, food.Weight, food.Nutrition)
+			food.Composition = &schema.CompositionMatch{
+				Code:   match.Code,
+				Name:   match.Name,
+				Source: match.Source,
+				Score:  match.Score,
+			}
+		}
+		// The user vouches for what they entered
+		if food.Nutrition.Confidence <= 0 {
+			food.Nutrition.Confidence = 1
+		}
+		if food.Portion == "" && food.Weight > 0 {
+			food.Portion = fmt.Sprintf("%.0f g", food.Weight)
+		}
+
+		foods = append(foods, food)
+		names = append(names, fmt.Sprintf("%s (%s)", food.Name, food.Portion))
+	}
+
+	var analysis *schema.FoodAnalysis
+	if len(foods) > 0 {
+		analysis = &schema.FoodAnalysis{
+			DetectedFoods: foods,
+			AnalysisText:  "Disusun manual: " + strings.Join(names, ", ") + ".",
+		}
+		analysis.TotalNutrition = sumDetectedFoods(foods, 1)
+		analysis.Confidence = analysis.TotalNutrition.Confidence
+	}
+
+	productItems, err := productFoods(products)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMealTemplate, err)
+	}
+	return mergeProductFoods(analysis, productItems), nil
+}
+
+func GetMealTemplates(userID uuid.UUID) ([]schema.MealTemplate, error) {
+	templates, err := repository.GetMealTemplatesByUserID(userID.String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if err := parseTemplateAnalysis(&templates[i]); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+func GetMealTemplate(templateID string, userID uuid.UUID) (*schema.MealTemplate, error) {
+	template, err := repository.GetMealTemplateByID(templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, ErrMealTemplateNotOwned
+	}
+	return template, parseTemplateAnalysis(template)
+}
+
+func UpdateMealTemplate(templateID string, userID uuid.UUID, input schema.MealTemplateUpdate) (*schema.MealTemplate, error) {
+	template, err := GetMealTemplate(templateID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		if strings.TrimSpace(*input.Name) == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrInvalidMealTemplate)
+		}
+		template.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.MealType != nil {
+		template.MealType = *input.MealType
+	}
+	if input.Description != nil {
+		template.Description = *input.Description
+	}
+
+	return template, repository.UpdateMealTemplate(template)
+}
+
+func DeleteMealTemplate(templateID string, userID uuid.UUID) error {
+	if _, err := GetMealTemplate(templateID, userID); err != nil {
+		return err
+	}
+	return repository.DeleteMealTemplate(templateID)
+}
+
+// LogMealTemplate adds the template's meal to the journal at the given time. The saved analysis is
+// copied as it is, so there is no AI call and no new recommendations.
+func LogMealTemplate(templateID string, userID uuid.UUID, input schema.MealTemplateLog) (*schema.FoodJournal, error) {
+	template, err := GetMealTemplate(templateID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := UserLocation(userID)
+	now := time.Now().In(loc)
+	eatenAt := now
+	if input.EatenAt != "" {
+		eatenAt, err = parseEatenAt(input.EatenAt, loc)
+		if err != nil {
+			return nil, err
+		}
+		if eatenAt.After(now.Add(templateFutureTolerance)) {
+			return nil, fmt.Errorf("%w: eaten_at is in the future", ErrInvalidMealTemplate)
+		}
+	}
+
+	mealType := input.MealType
+	if mealType == "" {
+		mealType = template.MealType
+	}
+
+	feelings, err := NormalizeFeelings(FeelingInput{
+		Before:          input.FeelingBefore,
+		After:           input.FeelingAfter,
+		BeforeIntensity: input.FeelingBeforeIntensity,
+		AfterIntensity:  input.FeelingAfterIntensity,
+		Symptoms:        input.Symptoms,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	journal := &schema.FoodJournal{
+		UserID:        userID,
+		MealName:      template.Name,
+		MealType:      mealType,
+		Description:   template.Description,
+		FeelingBefore: input.FeelingBefore,
+		FeelingAfter:  input.FeelingAfter,
+		Feelings:      feelings,
+		InputType:     "template",
+		FoodAnalysis:  template.FoodAnalysis,
+		AINutrition:   template.AINutrition,
+		CreatedAt:     eatenAt,
+		UpdatedAt:     now,
+	}
+	journal.ID = uuid.New()
+	if template.Analysis != nil {
+		journal.AIFeedback = template.Analysis.AnalysisText
+	}
+
+	if err := repository.LogMealTemplate(journal, template.ID.String(), now); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+func parseEatenAt(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", value, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: eaten_at must be RFC 3339 or YYYY-MM-DDTHH:MM", ErrInvalidMealTemplate)
+}
+
+func parseTemplateAnalysis(template *schema.MealTemplate) error {
+	if template.FoodAnalysis == "" {
+		return nil
+	}
+	var analysis schema.FoodAnalysis
+	if err := json.Unmarshal([]byte(template.FoodAnalysis), &analysis); err != nil {
+		return fmt.Errorf("failed to parse stored food analysis: %w", err)
+	}
+	template.Analysis = &analysis
+	return nil
+}
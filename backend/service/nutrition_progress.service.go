@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+// Invalid requests, as opposed to failures reading the journal
+var (
+	ErrInvalidNutritionPeriod = errors.New("period must be one of day, week")
+	ErrInvalidDateRange       = errors.New("end date must not be before start date")
+)
+
+var validNutritionPeriods = map[string]bool{"day": true, "week": true}
+
+// Days count as within target when calories are this close to the target and the limits are kept
+const calorieTolerance = 0.10
+
+// GetNutritionProgress compares the intake in [startDate, endDate] with the user's targets,
+// bucketed by ?period=day|week
+func GetNutritionProgress(userID uuid.UUID, startDate, endDate time.Time, period string) (*schema.NutritionProgress, error) {
+	if period == "" {
+		period = "day"
+	}
+	if !validNutritionPeriods[period] {
+		return nil, ErrInvalidNutritionPeriod
+	}
+	if endDate.Before(startDate) {
+		return nil, ErrInvalidDateRange
+	}
+
+	// Dates are the user's calendar days, the end date is inclusive for callers and exclusive for queries
+	loc := UserLocation(userID)
+	start, _ := LocalDayBounds(startDate, loc)
+	_, end := LocalDayBounds(endDate, loc)
+
+	targets, err := GetNutritionTargets(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nutrition targets: %w", err)
+	}
+	limits := repository.NutritionLimits{
+		MinCalories: targets.Calories * (1 - calorieTolerance),
+		MaxCalories: targets.Calories * (1 + calorieTolerance),
+		MaxSugar:    targets.Sugar,
+		MaxSodium:   targets.Sodium,
+	}
+
+	buckets, err := repository.GetNutritionBuckets(userID, start, end, loc.String(), period, limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nutrition by period: %w", err)
+	}
+	mealTypes, err := repository.GetNutritionByMealType(userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nutrition by meal type: %w", err)
+	}
+	today := LocalDate(time.Now(), loc).Format("2006-01-02")
+	streak, err := repository.GetNutritionStreak(userID, start, end, today, loc.String(), limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nutrition streak: %w", err)
+	}
+
+	progress := &schema.NutritionProgress{
+		Period:    period,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Targets:   targets,
+		Buckets:   buckets,
+		MealTypes: mealTypes,
+		Streak:    streak,
+	}
+	if progress.Buckets == nil {
+		progress.Buckets = []schema.NutritionBucket{}
+	}
+	if progress.MealTypes == nil {
+		progress.MealTypes = []schema.MealTypeNutrition{}
+	}
+
+	// Buckets hold averages per logged day, weigh them back into one average
+	var daysLogged float64
+	for i := range progress.Buckets {
+		bucket := &progress.Buckets[i]
+		bucket.PercentOfTarget = percentOfTarget(targets, bucket)
+
+		days := float64(bucket.DaysLogged)
+		daysLogged += days
+		progress.Average.Calories += bucket.Calories * days
+		progress.Average.Protein += bucket.Protein * days
+		progress.Average.Carbs += bucket.Carbs * days
+		progress.Average.Fat += bucket.Fat * days
+		progress.Average.Sugar += bucket.Sugar * days
+		progress.Average.Fiber += bucket.Fiber * days
+		progress.Average.Sodium += bucket.Sodium * days
+	}
+	if daysLogged > 0 {
+		progress.Average.Calories /= daysLogged
+		progress.Average.Protein /= daysLogged
+		progress.Average.Carbs /= daysLogged
+		progress.Average.Fat /= daysLogged
+		progress.Average.Sugar /= daysLogged
+		progress.Average.Fiber /= daysLogged
+		progress.Average.Sodium /= daysLogged
+	}
+
+	return progress, nil
+}
+
+func percentOfTarget(targets *schema.NutritionTargets, bucket *schema.NutritionBucket) map[string]float64 {
+	percent := func(value, target float64) float64 {
+		if target <= 0 {
+			return 0
+		}
+		return value / target * 100
+	}
+
+	return map[string]float64{
+		"calories": percent(bucket.Calories, targets.Calories),
+		"protein":  percent(bucket.Protein, targets.Protein),
+		"carbs":    percent(bucket.Carbs, targets.Carbs),
+		"fat":      percent(bucket.Fat, targets.Fat),
+		"sugar":    percent(bucket.Sugar, targets.Sugar),
+		"fiber":    percent(bucket.Fiber, targets.Fiber),
+		"sodium":   percent(bucket.Sodium, targets.Sodium),
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+// Within target is 1800 to 2200 kcal with at most 50 g sugar and 2000 mg sodium
+var testNutritionLimits = repository.NutritionLimits{MinCalories: 1800, MaxCalories: 2200, MaxSugar: 50, MaxSodium: 2000}
+
+// logTestMeal journals a meal eaten at noon of the local day
+func logTestMeal(t *testing.T, userID uuid.UUID, day time.Time, loc *time.Location, nutrition schema.AINutrition) {
+	t.Helper()
+	journal := schema.FoodJournal{
+		UserID:       userID,
+		MealName:     "Nasi campur",
+		MealType:     "lunch",
+		AINutrition:  nutrition,
+		FoodAnalysis: "{}",
+		CreatedAt:    time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, loc),
+	}
+	if err := database.DB.Create(&journal).Error; err != nil {
+		t.Fatalf("create journal: %v", err)
+	}
+}
+
+func TestGetNutritionProgressRejects(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		start, end time.Time
+		period     string
+		want       error
+	}{
+		{"month period", day, day, "month", ErrInvalidNutritionPeriod},
+		{"end before start", day, day.AddDate(0, 0, -1), "day", ErrInvalidDateRange},
+	}
+
+	for _, tt := range tests {
+		if _, err := GetNutritionProgress(uuid.New(), tt.start, tt.end, tt.period); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPercentOfTarget(t *testing.T) {
+	targets := &schema.NutritionTargets{Calories: 2000, Protein: 80, Carbs: 250, Fat: 0, Sugar: 50, Fiber: 28, Sodium: 2000}
+	bucket := &schema.NutritionBucket{Calories: 2500, Protein: 40, Carbs: 250, Fat: 70, Sugar: 0, Fiber: 7, Sodium: 3000}
+
+	want := map[string]float64{"calories": 125, "protein": 50, "carbs": 100, "fat": 0, "sugar": 0, "fiber": 25, "sodium": 150}
+	got := percentOfTarget(targets, bucket)
+	for nutrient, percent := range want {
+		if got[nutrient] != percent {
+			t.Errorf("%s = %v%%, want %v%%", nutrient, got[nutrient], percent)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d nutrients, want %d", len(got), len(want))
+	}
+}
+
+func TestGetNutritionBuckets(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "Asia/Jakarta")
+	loc, _ := LoadTimezone("Asia/Jakarta")
+
+	// Monday 9 March has two meals within target, Tuesday is over, the next Tuesday under
+	monday := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	logTestMeal(t, userID, monday, loc, schema.AINutrition{Calories: 1000, Sugar: 10})
+	logTestMeal(t, userID, monday, loc, schema.AINutrition{Calories: 1000, Sugar: 10})
+	logTestMeal(t, userID, monday.AddDate(0, 0, 1), loc, schema.AINutrition{Calories: 3000})
+	logTestMeal(t, userID, monday.AddDate(0, 0, 8), loc, schema.AINutrition{Calories: 1500})
+
+	start, _ := LocalDayBounds(monday, loc)
+	_, end := LocalDayBounds(monday.AddDate(0, 0, 13), loc)
+
+	tests := []struct {
+		period string
+		want   []schema.NutritionBucket
+	}{
+		{"day", []schema.NutritionBucket{
+			{PeriodStart: monday, DaysLogged: 1, Meals: 2, Calories: 2000, RollingCalories: 2000, WithinTarget: true},
+			{PeriodStart: monday.AddDate(0, 0, 1), DaysLogged: 1, Meals: 1, Calories: 3000, RollingCalories: 2500},
+			// A week later the window of the six days before holds no other log
+			{PeriodStart: monday.AddDate(0, 0, 8), DaysLogged: 1, Meals: 1, Calories: 1500, RollingCalories: 1500},
+		}},
+		{"week", []schema.NutritionBucket{
+			{PeriodStart: monday, DaysLogged: 2, Meals: 3, Calories: 2500, RollingCalories: 2500},
+			{PeriodStart: monday.AddDate(0, 0, 7), DaysLogged: 1, Meals: 1, Calories: 1500, RollingCalories: 2000},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			buckets, err := repository.GetNutritionBuckets(userID, start, end, loc.String(), tt.period, testNutritionLimits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buckets) != len(tt.want) {
+				t.Fatalf("got %d buckets %+v, want %d", len(buckets), buckets, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := buckets[i]
+				if !got.PeriodStart.Equal(want.PeriodStart) || got.DaysLogged != want.DaysLogged || got.Meals != want.Meals ||
+					got.Calories != want.Calories || got.RollingCalories != want.RollingCalories || got.WithinTarget != want.WithinTarget {
+					t.Errorf("bucket %d = %s, %d days, %d meals, %v kcal, rolling %v, within %v, want %s, %d, %d, %v, %v, %v", i,
+						got.PeriodStart.Format("2006-01-02"), got.DaysLogged, got.Meals, got.Calories, got.RollingCalories, got.WithinTarget,
+						want.PeriodStart.Format("2006-01-02"), want.DaysLogged, want.Meals, want.Calories, want.RollingCalories, want.WithinTarget)
+				}
+			}
+		})
+	}
+
+	if _, err := repository.GetNutritionBuckets(userID, start, end, loc.String(), "month", testNutritionLimits); err == nil {
+		t.Error("month period: want error")
+	}
+}
+
+func TestGetNutritionStreak(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "Asia/Jakarta")
+	loc, _ := LoadTimezone("Asia/Jakarta")
+	today := LocalDate(time.Now(), loc)
+	daysAgo := func(days int) time.Time { return today.AddDate(0, 0, -days) }
+
+	within := schema.AINutrition{Calories: 2000, Sugar: 30, Sodium: 1500}
+	// Three days in a row, a day without any log, then two more up to yesterday.
+	// Today is over the sodium limit, it does not end the current run since it may still be logged.
+	for _, day := range []int{7, 6, 5, 3, 2, 1} {
+		logTestMeal(t, userID, daysAgo(day), loc, within)
+	}
+	// Eight days ago is within target except for the sugar, it does not join the run after it
+	logTestMeal(t, userID, daysAgo(8), loc, schema.AINutrition{Calories: 2000, Sugar: 80, Sodium: 1500})
+	logTestMeal(t, userID, today, loc, schema.AINutrition{Calories: 2000, Sugar: 30, Sodium: 2500})
+
+	start, _ := LocalDayBounds(daysAgo(10), loc)
+	_, end := LocalDayBounds(today, loc)
+	streak, err := repository.GetNutritionStreak(userID, start, end, today.Format("2006-01-02"), loc.String(), testNutritionLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streak.Current != 3 || streak.Longest != 3 || streak.DaysInRange != 6 {
+		t.Errorf("streak = %+v, want current 3, longest 3, 6 days within target", streak)
+	}
+
+	// The gap day splits the run: ending the range four days ago leaves the older run, which is not current
+	_, end = LocalDayBounds(daysAgo(4), loc)
+	streak, err = repository.GetNutritionStreak(userID, start, end, today.Format("2006-01-02"), loc.String(), testNutritionLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streak.Current != 0 || streak.Longest != 3 || streak.DaysInRange != 3 {
+		t.Errorf("streak to four days ago = %+v, want current 0, longest 3, 3 days within target", streak)
+	}
+}
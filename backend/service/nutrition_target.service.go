@@ -0,0 +1,241 @@
+package service
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+// Used when the profile has no usable body data
+const (
+	defaultCalories = 2000.0
+	defaultAge      = 30
+	defaultHeight   = 160.0 // cm
+	defaultBMI      = 22.0
+)
+
+var activityFactors = map[string]float64{
+	"sedentary":         1.2,
+	"lightly_active":    1.375,
+	"moderately_active": 1.55,
+	"very_active":       1.725,
+}
+
+// Clinical thresholds for the limits below
+const (
+	highFastingBloodSugar = 100 // mg/dL, prediabetes
+	highCholesterol       = 200 // mg/dL, borderline high total cholesterol
+	highSystolic          = 130 // mmHg, stage 1 hypertension
+	highDiastolic         = 80
+)
+
+// GetNutritionTargets computes the user's daily targets from the health profile and applies the user's overrides
+func GetNutritionTargets(userID uuid.UUID) (*schema.NutritionTargets, error) {
+	pref, err := repository.GetUserPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := repository.GetNutritionTargetOverride(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := ComputeNutritionTargets(pref)
+	applyNutritionOverride(targets, override)
+	return targets, nil
+}
+
+// SetNutritionTargetOverride replaces the user's overrides and returns the resulting targets
+func SetNutritionTargetOverride(userID uuid.UUID, req schema.NutritionTargetRequest) (*schema.NutritionTargets, error) {
+	override := &schema.NutritionTargetOverride{
+		UserID:   userID,
+		Calories: req.Calories,
+		Protein:  req.Protein,
+		Carbs:    req.Carbs,
+		Fat:      req.Fat,
+		Sugar:    req.Sugar,
+		Fiber:    req.Fiber,
+		Sodium:   req.Sodium,
+	}
+	if err := repository.SaveNutritionTargetOverride(override); err != nil {
+		return nil, err
+	}
+	return GetNutritionTargets(userID)
+}
+
+// ComputeNutritionTargets derives energy, macro and micronutrient targets from the profile.
+// Energy uses Mifflin-St Jeor, the macro split follows the Indonesian balanced diet guideline (AKG)
+// and the limits follow WHO advice, tightened for high blood sugar, cholesterol or blood pressure.
+func ComputeNutritionTargets(pref *schema.UserPreference) *schema.NutritionTargets {
+	targets := &schema.NutritionTargets{Overridden: []string{}, Notes: []string{}}
+
+	weight, height, age, estimated := bodyData(pref)
+	targets.Estimated = estimated
+	if estimated {
+		targets.Notes = append(targets.Notes, "Data tubuh belum lengkap, sebagian target memakai nilai perkiraan")
+	}
+
+	// Mifflin-St Jeor, halfway between the sexes when unknown
+	bmr := 10*weight + 6.25*height - 5*float64(age)
+	switch strings.ToLower(pref.Sex) {
+	case "male":
+		bmr += 5
+	case "female":
+		bmr -= 161
+	default:
+		bmr -= 78
+	}
+	targets.BMR = math.Round(bmr)
+
+	factor, ok := activityFactors[pref.DailyActivity]
+	if !ok {
+		factor = activityFactors["lightly_active"]
+	}
+	targets.TDEE = math.Round(bmr * factor)
+
+	// Without any body weight the TDEE is a guess, the goal adjusts the reference intake instead
+	calories := targets.TDEE
+	if pref.Weight <= 0 && pref.BMI <= 0 {
+		calories = defaultCalories
+	}
+	proteinPerKg := 0.8
+	switch pref.HealthTarget {
+	case "weight_loss":
+		calories -= 500
+		proteinPerKg = 1.2 // keeps muscle while in deficit
+		targets.Notes = append(targets.Notes, "Defisit 500 kcal untuk menurunkan berat badan")
+	case "weight_gain":
+		calories += 300
+		proteinPerKg = 1.2
+		targets.Notes = append(targets.Notes, "Surplus 300 kcal untuk menaikkan berat badan")
+	}
+	minCalories := 1500.0
+	if strings.ToLower(pref.Sex) == "female" {
+		minCalories = 1200
+	}
+	calories = math.Max(calories, minCalories)
+	targets.Calories = math.Round(calories)
+
+	// Fat 25% of energy, 20% with high cholesterol, protein by weight, carbs take the rest
+	fatShare := 0.25
+	fiberPer1000 := 14.0
+	if pref.Cholesterol >= highCholesterol {
+		fatShare = 0.20
+		fiberPer1000 = 17
+		targets.Notes = append(targets.Notes, "Kolesterol tinggi: lemak dibatasi 20% energi dan serat ditambah")
+	}
+
+	protein := math.Max(proteinPerKg*weight, 0.15*calories/4)
+	fat := fatShare * calories / 9
+	carbs := math.Max((calories-protein*4-fat*9)/4, 0)
+
+	// Free sugar under 10% of energy, 5% with high blood sugar
+	sugarShare := 0.10
+	if pref.BloodSugar >= highFastingBloodSugar {
+		sugarShare = 0.05
+		carbs = math.Min(carbs, 0.45*calories/4)
+		targets.Notes = append(targets.Notes, "Gula darah tinggi: gula dibatasi 5% energi dan karbohidrat maksimal 45% energi")
+	}
+
+	sodium := 2000.0
+	if systolic, diastolic, ok := parseBloodPressure(pref.BloodPressure); ok && (systolic >= highSystolic || diastolic >= highDiastolic) {
+		sodium = 1500
+		targets.Notes = append(targets.Notes, "Tekanan darah tinggi: natrium dibatasi 1500 mg")
+	}
+
+	targets.Protein = math.Round(protein)
+	targets.Fat = math.Round(fat)
+	targets.Carbs = math.Round(carbs)
+	targets.Sugar = math.Round(sugarShare * calories / 4)
+	targets.Fiber = math.Round(fiberPer1000 * calories / 1000)
+	targets.Sodium = sodium
+
+	return targets
+}
+
+// bodyData returns weight (kg), height (cm) and age, filling missing values from BMI or defaults
+func bodyData(pref *schema.UserPreference) (float64, float64, int, bool) {
+	estimated := false
+
+	height := pref.Height
+	if height <= 0 {
+		height = defaultHeight
+		estimated = true
+	}
+
+	weight := pref.Weight
+	if weight <= 0 {
+		bmi := pref.BMI
+		if bmi <= 0 {
+			bmi = defaultBMI
+		}
+		weight = bmi * (height / 100) * (height / 100)
+		estimated = true
+	}
+
+	age := pref.Age
+	if age <= 0 {
+		age = defaultAge
+		estimated = true
+	}
+
+	return weight, height, age, estimated
+}
+
+// parseBloodPressure reads a reading like "120/80"
+func parseBloodPressure(reading string) (int, int, bool) {
+	parts := strings.Split(reading, "/")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	systolic, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+	diastolic, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return systolic, diastolic, true
+}
+
+func applyNutritionOverride(targets *schema.NutritionTargets, override *schema.NutritionTargetOverride) {
+	if override == nil {
+		return
+	}
+
+	fields := []struct {
+		name   string
+		value  *float64
+		target *float64
+	}{
+		{"calories", override.Calories, &targets.Calories},
+		{"protein", override.Protein, &targets.Protein},
+		{"carbs", override.Carbs, &targets.Carbs},
+		{"fat", override.Fat, &targets.Fat},
+		{"sugar", override.Sugar, &targets.Sugar},
+		{"fiber", override.Fiber, &targets.Fiber},
+		{"sodium", override.Sodium, &targets.Sodium},
+	}
+	for _, field := range fields {
+		if field.value != nil {
+			*field.target = *field.value
+			targets.Overridden = append(targets.Overridden, field.name)
+		}
+	}
+}
+
+// RemainingNutrition is what is left of the targets after the given intake, never below zero
+func RemainingNutrition(targets *schema.NutritionTargets, intake schema.AINutrition) schema.AINutrition {
+	return schema.AINutrition{
+		Calories: math.Max(targets.Calories-intake.Calories, 0),
+		Protein:  math.Max(targets.Protein-intake.Protein, 0),
+		Carbs:    math.Max(targets.Carbs-intake.Carbs, 0),
+		Fat:      math.Max(targets.Fat-intake.Fat, 0),
+		Sugar:    math.Max(targets.Sugar-intake.Sugar, 0),
+		Fiber:    math.Max(targets.Fiber-intake.Fiber, 0),
+		Sodium:   math.Max(targets.Sodium-intake.Sodium, 0),
+	}
+}
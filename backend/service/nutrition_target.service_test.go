@@ -0,0 +1,118 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+// A 30 year old man of 70 kg and 175 cm, moderately active: BMR 1648.75, TDEE 2555.56
+func testPreference() *schema.UserPreference {
+	return &schema.UserPreference{Sex: "male", Weight: 70, Height: 175, Age: 30, DailyActivity: "moderately_active"}
+}
+
+func hasNote(targets *schema.NutritionTargets, prefix string) bool {
+	return slices.ContainsFunc(targets.Notes, func(note string) bool { return strings.HasPrefix(note, prefix) })
+}
+
+func TestComputeNutritionTargetsEnergy(t *testing.T) {
+	tests := []struct {
+		name      string
+		pref      *schema.UserPreference
+		bmr       float64
+		tdee      float64
+		calories  float64
+		estimated bool
+		note      string
+	}{
+		{"man", testPreference(), 1649, 2556, 2556, false, ""},
+		{"woman losing weight, held at the minimum", &schema.UserPreference{Sex: "female", Weight: 60, Height: 160, Age: 40, DailyActivity: "sedentary", HealthTarget: "weight_loss"}, 1239, 1487, 1200, false, "Defisit"},
+		{"gaining weight", func() *schema.UserPreference { p := testPreference(); p.HealthTarget = "weight_gain"; return p }(), 1649, 2556, 2856, false, "Surplus"},
+		// Unknown sex sits halfway, the weight is 67.375 kg from BMI 22 at 175 cm, lightly active by default
+		{"weight from BMI", &schema.UserPreference{Height: 175, Age: 30, BMI: 22}, 1540, 2117, 2117, true, "Data tubuh"},
+		{"no body data", &schema.UserPreference{}, 1335, 1836, 2000, true, "Data tubuh"},
+		// The deficit applies to the reference intake, the note must not promise one that was dropped
+		{"no body data losing weight", &schema.UserPreference{HealthTarget: "weight_loss"}, 1335, 1836, 1500, true, "Defisit"},
+		{"no body data gaining weight", &schema.UserPreference{HealthTarget: "weight_gain"}, 1335, 1836, 2300, true, "Surplus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets := ComputeNutritionTargets(tt.pref)
+			if targets.BMR != tt.bmr || targets.TDEE != tt.tdee || targets.Calories != tt.calories {
+				t.Errorf("BMR %v, TDEE %v, calories %v, want %v, %v, %v", targets.BMR, targets.TDEE, targets.Calories, tt.bmr, tt.tdee, tt.calories)
+			}
+			if targets.Estimated != tt.estimated {
+				t.Errorf("estimated = %v, want %v", targets.Estimated, tt.estimated)
+			}
+			if tt.note != "" && !hasNote(targets, tt.note) {
+				t.Errorf("notes %q, want one starting %q", targets.Notes, tt.note)
+			}
+		})
+	}
+}
+
+func TestComputeNutritionTargetsLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*schema.UserPreference)
+		want   schema.NutritionTargets // protein, carbs, fat, sugar, fiber, sodium
+		note   string
+	}{
+		{"healthy", func(*schema.UserPreference) {}, schema.NutritionTargets{Protein: 96, Carbs: 383, Fat: 71, Sugar: 64, Fiber: 36, Sodium: 2000}, ""},
+		{"cholesterol just below", func(p *schema.UserPreference) { p.Cholesterol = 199 }, schema.NutritionTargets{Protein: 96, Carbs: 383, Fat: 71, Sugar: 64, Fiber: 36, Sodium: 2000}, ""},
+		{"high cholesterol", func(p *schema.UserPreference) { p.Cholesterol = 200 }, schema.NutritionTargets{Protein: 96, Carbs: 415, Fat: 57, Sugar: 64, Fiber: 43, Sodium: 2000}, "Kolesterol"},
+		{"blood sugar just below", func(p *schema.UserPreference) { p.BloodSugar = 99 }, schema.NutritionTargets{Protein: 96, Carbs: 383, Fat: 71, Sugar: 64, Fiber: 36, Sodium: 2000}, ""},
+		{"high blood sugar", func(p *schema.UserPreference) { p.BloodSugar = 100 }, schema.NutritionTargets{Protein: 96, Carbs: 288, Fat: 71, Sugar: 32, Fiber: 36, Sodium: 2000}, "Gula darah"},
+		{"high systolic", func(p *schema.UserPreference) { p.BloodPressure = "130/79" }, schema.NutritionTargets{Protein: 96, Carbs: 383, Fat: 71, Sugar: 64, Fiber: 36, Sodium: 1500}, "Tekanan darah"},
+		{"high diastolic", func(p *schema.UserPreference) { p.BloodPressure = "129 / 80" }, schema.NutritionTargets{Protein: 96, Carbs: 383, Fat: 71, Sugar: 64, Fiber: 36, Sodium: 1500}, "Tekanan darah"},
+		{"normal blood pressure", func(p *schema.UserPreference) { p.BloodPressure = "129/79" }, schema.NutritionTargets{Protein: 96, Carbs: 383, Fat: 71, Sugar: 64, Fiber: 36, Sodium: 2000}, ""},
+		{"unreadable blood pressure", func(p *schema.UserPreference) { p.BloodPressure = "tinggi" }, schema.NutritionTargets{Protein: 96, Carbs: 383, Fat: 71, Sugar: 64, Fiber: 36, Sodium: 2000}, ""},
+		{"protein by weight in deficit", func(p *schema.UserPreference) { p.Weight = 110; p.HealthTarget = "weight_loss" }, schema.NutritionTargets{Protein: 132, Carbs: 370, Fat: 74, Sugar: 67, Fiber: 37, Sodium: 2000}, "Defisit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pref := testPreference()
+			tt.change(pref)
+			got := ComputeNutritionTargets(pref)
+			if got.Protein != tt.want.Protein || got.Carbs != tt.want.Carbs || got.Fat != tt.want.Fat ||
+				got.Sugar != tt.want.Sugar || got.Fiber != tt.want.Fiber || got.Sodium != tt.want.Sodium {
+				t.Errorf("protein %v carbs %v fat %v sugar %v fiber %v sodium %v, want %v %v %v %v %v %v",
+					got.Protein, got.Carbs, got.Fat, got.Sugar, got.Fiber, got.Sodium,
+					tt.want.Protein, tt.want.Carbs, tt.want.Fat, tt.want.Sugar, tt.want.Fiber, tt.want.Sodium)
+			}
+			if tt.note != "" && !hasNote(got, tt.note) {
+				t.Errorf("notes %q, want one starting %q", got.Notes, tt.note)
+			}
+			if tt.note == "" && len(got.Notes) != 0 {
+				t.Errorf("unexpected notes %q", got.Notes)
+			}
+		})
+	}
+}
+
+func TestApplyNutritionOverride(t *testing.T) {
+	calories, sodium := 1800.0, 1200.0
+	targets := ComputeNutritionTargets(testPreference())
+	applyNutritionOverride(targets, &schema.NutritionTargetOverride{Calories: &calories, Sodium: &sodium})
+
+	if targets.Calories != 1800 || targets.Sodium != 1200 {
+		t.Errorf("calories %v, sodium %v, want the overrides", targets.Calories, targets.Sodium)
+	}
+	// The rest stays computed from the profile, not rescaled to the overridden energy
+	if targets.Protein != 96 || targets.Fat != 71 {
+		t.Errorf("protein %v, fat %v changed", targets.Protein, targets.Fat)
+	}
+	if !slices.Equal(targets.Overridden, []string{"calories", "sodium"}) {
+		t.Errorf("overridden = %q", targets.Overridden)
+	}
+
+	untouched := ComputeNutritionTargets(testPreference())
+	applyNutritionOverride(untouched, nil)
+	if len(untouched.Overridden) != 0 || untouched.Calories != 2556 {
+		t.Errorf("no override changed the targets: %+v", untouched)
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/constants"
+)
+
+// OCRProvider turns a receipt photo into plain text for the local receipt parser
+type OCRProvider interface {
+	ExtractText(image []byte, mimeType string) (string, error)
+}
+
+// httpOCRProvider sends the image to an OCR server, e.g. a Tesseract wrapper, that answers
+// with {"text": "..."}
+type httpOCRProvider struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewOCRProvider returns an OCR provider for the given server, or nil when none is configured
+func NewOCRProvider(url string) OCRProvider {
+	if url == "" {
+		return nil
+	}
+	return &httpOCRProvider{
+		url:        url,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *httpOCRProvider) ExtractText(image []byte, mimeType string) (string, error) {
+	req, err := http.NewRequest("POST", p.url, bytes.NewReader(image))
+	if err != nil {
+		return "", fmt.Errorf("failed to create OCR request: %w", err)
+	}
+	req.Header.Set(constants.ContentTypeHeader, mimeType)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to OCR service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OCR service returned non-200 status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode OCR response: %w", err)
+	}
+	return result.Text, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+func TestCursorWithWrongKeysIsRejected(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "")
+	now := time.Now()
+
+	// A receipt cursor sent to the journal and the other way around
+	twoKeys := schema.PageRequest{Limit: 10, Cursor: &schema.PageCursor{Keys: []time.Time{now, now}, ID: uuid.New()}}
+	oneKey := schema.PageRequest{Limit: 10, Cursor: &schema.PageCursor{Keys: []time.Time{now}, ID: uuid.New()}}
+
+	if _, _, err := SearchFoodJournal(userID, schema.FoodJournalSearch{}, twoKeys); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("journal with two cursor keys: err = %v, want ErrInvalidCursor", err)
+	}
+
+	receipts, _ := NewReceiptService(nil, nil)
+	if _, _, err := receipts.GetReceiptHistory(userID, oneKey); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("receipts with one cursor key: err = %v, want ErrInvalidCursor", err)
+	}
+
+	if _, _, err := SearchItems(userID, schema.ItemSearch{}, twoKeys); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("items with two cursor keys: err = %v, want ErrInvalidCursor", err)
+	}
+
+	if _, _, err := (&CartService{}).GetAllCartsByUser(userID, twoKeys); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("carts with two cursor keys: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestItemPagesFollowExpiry(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "Asia/Jakarta")
+
+	today := UserToday(userID)
+	for _, days := range []int{3, 1, 2} {
+		expDate := today.AddDate(0, 0, days)
+		item := schema.Item{UserID: userID, Name: "Telur", Amount: 1, AmountType: "butir", StartDate: today, ExpDate: expDate}
+		if err := database.DB.Create(&item).Error; err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+	}
+
+	fresh := schema.ItemSearch{State: schema.ItemStateFresh}
+	first, info, err := SearchItems(userID, fresh, schema.PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if len(first) != 2 || !info.HasMore || info.NextCursor == "" {
+		t.Fatalf("first page has %d items and %+v, want 2 and a next cursor", len(first), info)
+	}
+	if !first[0].ExpDate.Equal(today.AddDate(0, 0, 1)) || !first[1].ExpDate.Equal(today.AddDate(0, 0, 2)) {
+		t.Errorf("first page expires %v and %v, want the two soonest in order", first[0].ExpDate, first[1].ExpDate)
+	}
+
+	page, err := utils.ParsePageRequest("2", info.NextCursor)
+	if err != nil {
+		t.Fatalf("parse cursor: %v", err)
+	}
+	second, info, err := SearchItems(userID, fresh, page)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if len(second) != 1 || info.HasMore || !second[0].ExpDate.Equal(today.AddDate(0, 0, 3)) {
+		t.Errorf("second page = %d items with %+v, want only the latest expiry and no more pages", len(second), info)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+var ErrInvalidPortionReference = errors.New("invalid portion reference")
+
+// Relative spread of a portion estimate when the model gives no usable range
+const (
+	portionSpread          = 0.30
+	portionSpreadReference = 0.15 // a size reference in the photo narrows the guess
+	defaultPlateDiameter   = 24.0 // cm, a common Indonesian dinner plate
+)
+
+// PortionReferenceHint describes the reference object for the prompt
+func PortionReferenceHint(ref *schema.PortionReference) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+
+	var hint string
+	switch ref.Kind {
+	case schema.ReferencePlate:
+		diameter := ref.Size
+		if diameter <= 0 {
+			diameter = defaultPlateDiameter
+		}
+		if diameter < 10 || diameter > 40 {
+			return "", fmt.Errorf("%w: plate diameter must be between 10 and 40 cm", ErrInvalidPortionReference)
+		}
+		hint = fmt.Sprintf("piring berdiameter %.0f cm", diameter)
+	case schema.ReferenceHand:
+		hint = "tangan orang dewasa (telapak sekitar 8-9 cm lebar dan 17-19 cm panjang dari pergelangan ke ujung jari tengah)"
+	case schema.ReferenceSpoon:
+		hint = "sendok makan standar (panjang sekitar 17 cm, satu sendok makan peres sekitar 15 ml)"
+	case schema.ReferencePackage:
+		if ref.Barcode == "" {
+			if ref.Note == "" {
+				return "", fmt.Errorf("%w: a package reference needs a barcode or a note", ErrInvalidPortionReference)
+			}
+			hint = "kemasan " + ref.Note
+			break
+		}
+		product, err := repository.GetProductByBarcode(ref.Barcode)
+		if err != nil {
+			return "", fmt.Errorf("%w: product %s is not in the catalog, scan it first", ErrInvalidPortionReference, ref.Barcode)
+		}
+		hint = "kemasan " + strings.TrimSpace(product.Brand+" "+product.Name)
+		if product.Quantity != "" {
+			hint += " berisi " + product.Quantity
+		}
+	default:
+		return "", fmt.Errorf("%w: kind must be plate, hand, spoon or package", ErrInvalidPortionReference)
+	}
+
+	if ref.Note != "" && ref.Kind != schema.ReferencePackage {
+		hint += " (" + ref.Note + ")"
+	}
+	return hint, nil
+}
+
+// ApplyPortionBounds makes every food's weight range usable and derives the nutrition bounds from it.
+// Label foods are exact, AI foods without a sensible range get one around their estimate.
+func ApplyPortionBounds(analysis *schema.FoodAnalysis, ref *schema.PortionReference) {
+	if analysis == nil {
+		return
+	}
+	analysis.Reference = ref
+	spread := portionSpreadFor(ref)
+
+	for i := range analysis.DetectedFoods {
+		food := &analysis.DetectedFoods[i]
+		if food.Weight <= 0 {
+			continue
+		}
+		if food.Source == schema.FoodSourceProduct {
+			food.WeightMin, food.WeightMax = food.Weight, food.Weight
+			continue
+		}
+		if food.WeightMin <= 0 || food.WeightMin > food.Weight {
+			food.WeightMin = math.Round(food.Weight * (1 - spread))
+		}
+		if food.WeightMax < food.Weight {
+			food.WeightMax = math.Round(food.Weight * (1 + spread))
+		}
+		// An upper bound far above the estimate is a model slip, it would make the range meaningless
+		if limit := math.Round(food.Weight * (1 + 2*spread)); food.WeightMax > limit {
+			food.WeightMax = limit
+		}
+	}
+
+	refreshNutritionRange(analysis)
+}
+
+func portionSpreadFor(ref *schema.PortionReference) float64 {
+	if ref != nil {
+		return portionSpreadReference
+	}
+	return portionSpread
+}
+
+// refreshNutritionRange recomputes the nutrition bounds after the foods changed. Analyses where every
+// weight is exact, from labels or entered by the user, have no range.
+func refreshNutritionRange(analysis *schema.FoodAnalysis) {
+	hasBounds := false
+	for _, food := range analysis.DetectedFoods {
+		if food.WeightMin > 0 && food.WeightMax > food.WeightMin {
+			hasBounds = true
+			break
+		}
+	}
+	if !hasBounds {
+		analysis.NutritionRange = nil
+		return
+	}
+
+	// Analyses saved before ApplyPortionBounds capped WeightMax may still carry an oversized range
+	maxRatio := 1 + 2*portionSpreadFor(analysis.Reference)
+
+	var bounds schema.NutritionRange
+	for _, food := range analysis.DetectedFoods {
+		low, high := 1.0, 1.0
+		if food.Weight > 0 && food.WeightMin > 0 && food.WeightMax > 0 {
+			low, high = food.WeightMin/food.Weight, min(food.WeightMax/food.Weight, maxRatio)
+		}
+		addNutrition(&bounds.Min, scaleNutrition(food.Nutrition, low))
+		addNutrition(&bounds.Max, scaleNutrition(food.Nutrition, high))
+	}
+	bounds.Min.Confidence = analysis.TotalNutrition.Confidence
+	bounds.Max.Confidence = analysis.TotalNutrition.Confidence
+	analysis.NutritionRange = &bounds
+}
+
+func addNutrition(total *schema.AINutrition, n schema.AINutrition) {
+	total.Calories += n.Calories
+	total.Protein += n.Protein
+	total.Carbs += n.Carbs
+	total.Fat += n.Fat
+	total.Sugar += n.Sugar
+	total.Fiber += n.Fiber
+	total.Sodium += n.Sodium
+}
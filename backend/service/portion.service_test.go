@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+func TestApplyPortionBounds(t *testing.T) {
+	plate := &schema.PortionReference{Kind: schema.ReferencePlate}
+
+	tests := []struct {
+		name     string
+		food     schema.DetectedFood
+		ref      *schema.PortionReference
+		min, max float64
+	}{
+		// Label foods are weighed by their package, whatever range the model gave
+		{"label food", schema.DetectedFood{Weight: 100, WeightMin: 50, WeightMax: 300, Source: schema.FoodSourceProduct}, nil, 100, 100},
+		{"missing range", schema.DetectedFood{Weight: 200, Source: schema.FoodSourceAI}, nil, 140, 260},
+		{"missing range with reference", schema.DetectedFood{Weight: 200, Source: schema.FoodSourceAI}, plate, 170, 230},
+		{"minimum above the estimate", schema.DetectedFood{Weight: 200, WeightMin: 250, WeightMax: 300, Source: schema.FoodSourceAI}, nil, 140, 300},
+		{"maximum below the estimate", schema.DetectedFood{Weight: 200, WeightMin: 180, WeightMax: 150, Source: schema.FoodSourceAI}, nil, 180, 260},
+		{"sensible range is kept", schema.DetectedFood{Weight: 100, WeightMin: 90, WeightMax: 120, Source: schema.FoodSourceAI}, nil, 90, 120},
+		// A maximum several times the estimate is capped at twice the usual spread
+		{"oversized maximum", schema.DetectedFood{Weight: 100, WeightMin: 80, WeightMax: 500, Source: schema.FoodSourceAI}, nil, 80, 160},
+		{"oversized maximum with reference", schema.DetectedFood{Weight: 100, WeightMin: 80, WeightMax: 500, Source: schema.FoodSourceAI}, plate, 80, 130},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := &schema.FoodAnalysis{DetectedFoods: []schema.DetectedFood{tt.food}}
+			ApplyPortionBounds(analysis, tt.ref)
+
+			food := analysis.DetectedFoods[0]
+			if food.WeightMin != tt.min || food.WeightMax != tt.max {
+				t.Errorf("range = %v-%v g, want %v-%v g", food.WeightMin, food.WeightMax, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestPortionBoundsNutritionRange(t *testing.T) {
+	label := schema.DetectedFood{Weight: 250, Source: schema.FoodSourceProduct, Nutrition: schema.AINutrition{Calories: 100}}
+	rice := schema.DetectedFood{Weight: 100, WeightMax: 500, Source: schema.FoodSourceAI, Nutrition: schema.AINutrition{Calories: 200}}
+
+	// Only exact weights, there is no range to show
+	analysis := &schema.FoodAnalysis{DetectedFoods: []schema.DetectedFood{label}}
+	ApplyPortionBounds(analysis, nil)
+	if analysis.NutritionRange != nil {
+		t.Errorf("label only: range = %+v, want none", analysis.NutritionRange)
+	}
+
+	// The label food counts once at both ends, the rice spans 70-160 g
+	analysis = &schema.FoodAnalysis{DetectedFoods: []schema.DetectedFood{label, rice}}
+	ApplyPortionBounds(analysis, nil)
+	if analysis.NutritionRange == nil {
+		t.Fatal("no range, want one for the estimated rice")
+	}
+	if got := analysis.NutritionRange.Min.Calories; got != 240 {
+		t.Errorf("min calories = %v, want 240", got)
+	}
+	if got := analysis.NutritionRange.Max.Calories; got != 420 {
+		t.Errorf("max calories = %v, want 420", got)
+	}
+
+	// A range stored before the cap is still bounded when the range is refreshed
+	stored := &schema.FoodAnalysis{DetectedFoods: []schema.DetectedFood{{Weight: 100, WeightMin: 70, WeightMax: 500, Nutrition: schema.AINutrition{Calories: 200}}}}
+	refreshNutritionRange(stored)
+	if got := stored.NutritionRange.Max.Calories; got != 320 {
+		t.Errorf("stored max calories = %v, want 320", got)
+	}
+}
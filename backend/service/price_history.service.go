@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	priceSourceReceipt = "receipt"
+	priceSourceCart    = "cart"
+)
+
+// canonicalUnit maps a unit to the one prices are compared in, with the size of one
+// given unit expressed in the canonical unit
+func canonicalUnit(unit string) (string, float64) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "kg", "kilogram":
+		return "kilogram", 1
+	case "g", "gr", "gram":
+		return "kilogram", 0.001
+	case "l", "liter", "litre":
+		return "liter", 1
+	case "ml", "mililiter":
+		return "liter", 0.001
+	case "ikat":
+		return "ikat", 1
+	default:
+		return "satuan", 1
+	}
+}
+
+// receiptPriceRecords prepares the price history records of every priced line of a receipt
+func receiptPriceRecords(receipt *schema.Receipt, products []schema.InventoryProduct) []schema.PriceRecord {
+	productIDs := make(map[string]uuid.UUID, len(products))
+	for _, product := range products {
+		productIDs[product.NormalizedName] = product.ID
+	}
+
+	var records []schema.PriceRecord
+	for _, line := range receipt.Lines {
+		if line.UnitPrice <= 0 {
+			continue
+		}
+
+		unit, size := canonicalUnit(line.Unit)
+		record := schema.PriceRecord{
+			UserID:         receipt.UserID,
+			Name:           line.Name,
+			NormalizedName: utils.NormalizeName(line.Name),
+			StoreName:      receipt.StoreName,
+			Unit:           unit,
+			UnitPrice:      line.UnitPrice / size,
+			Source:         priceSourceReceipt,
+			SourceID:       receipt.ID,
+			RecordedAt:     receipt.PurchaseDate,
+		}
+		if productID, ok := productIDs[record.NormalizedName]; ok {
+			record.ProductID = &productID
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// recordCartPrices adds the price paid for purchased cart lines to the price history
+func recordCartPrices(userID uuid.UUID, cartItems []schema.CartItem, items []schema.Item, purchasedAt time.Time) error {
+	var records []schema.PriceRecord
+	for i, cartItem := range cartItems {
+		if cartItem.PricePaid == nil || *cartItem.PricePaid <= 0 || cartItem.Amount <= 0 {
+			continue
+		}
+
+		unit, size := canonicalUnit(cartItem.AmountType)
+		records = append(records, schema.PriceRecord{
+			UserID:         userID,
+			ProductID:      items[i].ProductID,
+			Name:           cartItem.Name,
+			NormalizedName: utils.NormalizeName(cartItem.Name),
+			Unit:           unit,
+			UnitPrice:      *cartItem.PricePaid / (cartItem.Amount * size),
+			Source:         priceSourceCart,
+			SourceID:       cartItem.ID,
+			RecordedAt:     purchasedAt,
+		})
+	}
+
+	return repository.CreatePriceRecords(records)
+}
+
+// GetProductPriceTrend returns a product's price history with a per-store summary
+func GetProductPriceTrend(productID string, userID uuid.UUID) (*schema.PriceTrend, error) {
+	product, err := repository.GetInventoryProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.UserID != userID {
+		return nil, ErrItemNotOwned
+	}
+
+	records, err := repository.GetPriceRecordsByProduct(userID, product.ID, product.NormalizedName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	unit, _ := canonicalUnit(product.AmountType)
+	trend := &schema.PriceTrend{
+		ProductID: product.ID.String(),
+		Name:      product.Name,
+		Unit:      unit,
+		Points:    []schema.PriceRecord{},
+		Stores:    []schema.StorePrice{},
+	}
+
+	storeIndex := map[string]int{}
+	for _, record := range records {
+		// Prices in another unit cannot be compared with the product's stock
+		if record.Unit != unit {
+			continue
+		}
+		trend.Points = append(trend.Points, record)
+		trend.LatestPrice = record.UnitPrice
+
+		if record.StoreName == "" {
+			continue
+		}
+		i, ok := storeIndex[record.StoreName]
+		if !ok {
+			trend.Stores = append(trend.Stores, schema.StorePrice{
+				StoreName: record.StoreName,
+				MinPrice:  record.UnitPrice,
+				MaxPrice:  record.UnitPrice,
+			})
+			i = len(trend.Stores) - 1
+			storeIndex[record.StoreName] = i
+		}
+
+		store := &trend.Stores[i]
+		store.LatestPrice = record.UnitPrice
+		store.LastSeen = record.RecordedAt
+		store.MinPrice = min(store.MinPrice, record.UnitPrice)
+		store.MaxPrice = max(store.MaxPrice, record.UnitPrice)
+		store.AvgPrice = (store.AvgPrice*float64(store.Observations) + record.UnitPrice) / float64(store.Observations+1)
+		store.Observations++
+	}
+
+	for _, store := range trend.Stores {
+		if trend.CheapestStore == "" || store.LatestPrice < trend.CheapestPrice {
+			trend.CheapestStore = store.StoreName
+			trend.CheapestPrice = store.LatestPrice
+		}
+	}
+
+	return trend, nil
+}
+
+// linePrices is what is known about the price of one product in one unit
+type linePrices struct {
+	latest   *schema.LatestPrice
+	cheapest *schema.LatestPrice
+}
+
+func priceKey(normalizedName, unit string) string {
+	return normalizedName + "|" + unit
+}
+
+// getLinePrices indexes the latest price overall and the cheapest store price of every product
+func getLinePrices(userID uuid.UUID) (map[string]linePrices, error) {
+	prices, err := repository.GetLatestStorePrices(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]linePrices)
+	for i := range prices {
+		price := &prices[i]
+		key := priceKey(price.NormalizedName, price.Unit)
+		entry := index[key]
+		if entry.latest == nil || price.RecordedAt.After(entry.latest.RecordedAt) {
+			entry.latest = price
+		}
+		if price.StoreName != "" && (entry.cheapest == nil || price.UnitPrice < entry.cheapest.UnitPrice) {
+			entry.cheapest = price
+		}
+		index[key] = entry
+	}
+	return index, nil
+}
+
+// estimateCartLine prices one cart line with the latest and cheapest known unit prices
+func estimateCartLine(cartItem schema.CartItem, prices map[string]linePrices) (schema.CartLineEstimate, bool) {
+	line := schema.CartLineEstimate{
+		CartItemID: cartItem.ID.String(),
+		Name:       cartItem.Name,
+		Amount:     cartItem.Amount,
+		AmountType: cartItem.AmountType,
+	}
+
+	unit, size := canonicalUnit(cartItem.AmountType)
+	entry, ok := prices[priceKey(utils.NormalizeName(cartItem.Name), unit)]
+	if !ok || entry.latest == nil {
+		return line, false
+	}
+
+	amount := cartItem.Amount * size
+	latest := entry.latest.UnitPrice
+	line.LatestPrice = &latest
+	line.LatestStore = entry.latest.StoreName
+	line.EstimatedCost = latest * amount
+	line.CheapestCost = line.EstimatedCost
+	if entry.cheapest != nil {
+		cheapest := entry.cheapest.UnitPrice
+		line.CheapestPrice = &cheapest
+		line.CheapestStore = entry.cheapest.StoreName
+		line.CheapestCost = min(cheapest*amount, line.EstimatedCost)
+	}
+	return line, true
+}
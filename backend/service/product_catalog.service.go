@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrInvalidBarcode  = errors.New("barcode must be 8 to 14 digits")
+	ErrProductNameless = errors.New("product name is required")
+)
+
+const (
+	defaultCatalogSearchLimit = 20
+	maxCatalogSearchLimit     = 50
+)
+
+// CatalogService looks barcodes up in the local catalog first and falls back to an upstream
+// ProductService, caching whatever upstream knows. It is itself a ProductService.
+type CatalogService struct {
+	upstream ProductService
+}
+
+func NewCatalogService(upstream ProductService) *CatalogService {
+	return &CatalogService{
+		upstream: upstream,
+	}
+}
+
+func (s *CatalogService) GetProductByBarcode(barcode string) (*ProductInfo, error) {
+	product, err := repository.GetProductByBarcode(barcode)
+	if err == nil {
+		return productInfoFromProduct(product), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get product from catalog: %w", err)
+	}
+
+	if s.upstream == nil {
+		return nil, ErrProductNotFound
+	}
+	info, err := s.upstream.GetProductByBarcode(barcode)
+	if err != nil {
+		fmt.Printf("Upstream lookup for barcode %s failed: %v\n", barcode, err)
+		return nil, ErrProductNotFound
+	}
+
+	cached := productFromInfo(info)
+	if err := repository.UpsertProduct(&cached); err != nil {
+		fmt.Printf("Warning: failed to cache product %s: %v\n", barcode, err)
+	}
+	return info, nil
+}
+
+// SubmitProduct adds a product upstream does not know. For a product already in the catalog it only
+// fills in missing fields, one user cannot overwrite what everyone else scans. Corrections of
+// fields the catalog already has are not supported.
+func (s *CatalogService) SubmitProduct(barcode string, userID uuid.UUID, submission schema.ProductSubmission) (*schema.Product, error) {
+	if !isValidBarcode(barcode) {
+		return nil, ErrInvalidBarcode
+	}
+	if strings.TrimSpace(submission.Name) == "" {
+		return nil, ErrProductNameless
+	}
+
+	itemType := strings.TrimSpace(submission.ItemType)
+	if itemType == "" {
+		itemType = "Lainnya"
+	}
+	packageAmount, amountType := ParsePackageSize(submission.Quantity)
+
+	product := schema.Product{
+		Barcode:         barcode,
+		Name:            strings.TrimSpace(submission.Name),
+		Brand:           strings.TrimSpace(submission.Brand),
+		Quantity:        strings.TrimSpace(submission.Quantity),
+		PackageAmount:   packageAmount,
+		AmountType:      amountType,
+		Categories:      submission.Categories,
+		ItemType:        itemType,
+		Nutriments:      submission.Nutriments,
+		Allergens:       submission.Allergens,
+		IngredientsText: submission.IngredientsText,
+		ImageURL:        submission.ImageURL,
+		Source:          schema.ProductSourceUser,
+		SubmittedBy:     &userID,
+	}
+	if product.Categories == nil {
+		product.Categories = []string{}
+	}
+	if product.Allergens == nil {
+		product.Allergens = []string{}
+	}
+
+	if err := repository.FillProduct(&product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (s *CatalogService) SearchProducts(query string, limit int) ([]schema.Product, error) {
+	query = strings.TrimSpace(query)
+	if len(query) < 2 {
+		return nil, errors.New("search query must be at least 2 characters")
+	}
+	if limit <= 0 {
+		limit = defaultCatalogSearchLimit
+	}
+	if limit > maxCatalogSearchLimit {
+		limit = maxCatalogSearchLimit
+	}
+	return repository.SearchProducts(query, limit)
+}
+
+func isValidBarcode(barcode string) bool {
+	if len(barcode) < 8 || len(barcode) > 14 {
+		return false
+	}
+	for _, r := range barcode {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func productFromInfo(info *ProductInfo) schema.Product {
+	return schema.Product{
+		Barcode:         info.Barcode,
+		Name:            info.Name,
+		Brand:           info.Brand,
+		Quantity:        info.Quantity,
+		PackageAmount:   info.PackageAmount,
+		AmountType:      info.AmountType,
+		Categories:      info.Categories,
+		ItemType:        info.ItemType,
+		Nutriments:      info.Nutriments,
+		Allergens:       info.Allergens,
+		IngredientsText: info.IngredientsText,
+		ImageURL:        info.ImageURL,
+		Source:          info.Source,
+	}
+}
+
+func productInfoFromProduct(product *schema.Product) *ProductInfo {
+	return &ProductInfo{
+		Barcode:         product.Barcode,
+		Name:            product.Name,
+		Brand:           product.Brand,
+		Quantity:        product.Quantity,
+		PackageAmount:   product.PackageAmount,
+		AmountType:      product.AmountType,
+		Categories:      product.Categories,
+		ItemType:        product.ItemType,
+		Nutriments:      product.Nutriments,
+		Allergens:       product.Allergens,
+		IngredientsText: product.IngredientsText,
+		ImageURL:        product.ImageURL,
+		Source:          product.Source,
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+func TestSubmitProductRejects(t *testing.T) {
+	catalog := NewCatalogService(nil)
+
+	tests := []struct {
+		name    string
+		barcode string
+		product string
+		want    error
+	}{
+		{"blank name", "8991234567890", "   ", ErrProductNameless},
+		{"empty name", "8991234567890", "", ErrProductNameless},
+		{"short barcode", "12345", "Susu UHT", ErrInvalidBarcode},
+		{"letters in barcode", "89912345678AB", "Susu UHT", ErrInvalidBarcode},
+	}
+
+	for _, tt := range tests {
+		_, err := catalog.SubmitProduct(tt.barcode, uuid.New(), schema.ProductSubmission{Name: tt.product})
+		if err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"gorm.io/gorm"
+)
+
+// ProductNutrition scales a product's per-100 g label to the grams eaten. Label values are exact,
+// so the confidence is 1.
+func ProductNutrition(nutriments schema.ProductNutriments, grams float64) schema.AINutrition {
+	factor := grams / 100
+
+	// Labels give sodium in grams, the journal tracks milligrams
+	sodium := nutriments.Sodium
+	if sodium == 0 && nutriments.Salt > 0 {
+		sodium = nutriments.Salt / 2.5
+	}
+
+	return schema.AINutrition{
+		Calories:   nutriments.EnergyKcal * factor,
+		Protein:    nutriments.Proteins * factor,
+		Carbs:      nutriments.Carbohydrates * factor,
+		Fat:        nutriments.Fat * factor,
+		Sugar:      nutriments.Sugars * factor,
+		Fiber:      nutriments.Fiber * factor,
+		Sodium:     sodium * 1000 * factor,
+		Confidence: 1.0,
+	}
+}
+
+// productFoods turns the catalog products eaten in a meal into detected foods
+func productFoods(portions []schema.ProductPortion) ([]schema.DetectedFood, error) {
+	foods := make([]schema.DetectedFood, 0, len(portions))
+	for _, portion := range portions {
+		product, err := findPortionProduct(portion)
+		if err != nil {
+			return nil, err
+		}
+
+		grams := portion.Quantity
+		if grams <= 0 {
+			if product.AmountType != "kilogram" && product.AmountType != "liter" {
+				return nil, fmt.Errorf("quantity is required for %s, its package size is unknown", product.Name)
+			}
+			grams = product.PackageAmount * 1000
+		}
+
+		unit := "g"
+		if product.AmountType == "liter" {
+			unit = "ml"
+		}
+
+		foods = append(foods, schema.DetectedFood{
+			Name:        product.Name,
+			Portion:     fmt.Sprintf("%.0f %s", grams, unit),
+			Weight:      grams,
+			Nutrition:   ProductNutrition(product.Nutriments, grams),
+			Description: strings.TrimSpace(product.Brand + " " + product.Quantity),
+			Source:      schema.FoodSourceProduct,
+			Barcode:     product.Barcode,
+		})
+	}
+	return foods, nil
+}
+
+func findPortionProduct(portion schema.ProductPortion) (*schema.Product, error) {
+	var product *schema.Product
+	var err error
+	switch {
+	case portion.ProductID != "":
+		product, err = repository.GetProductByID(portion.ProductID)
+	case portion.Barcode != "":
+		product, err = repository.GetProductByBarcode(portion.Barcode)
+	default:
+		return nil, errors.New("product item needs a barcode or product_id")
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("product %s%s is not in the catalog, scan it first", portion.Barcode, portion.ProductID)
+	}
+	return product, err
+}
+
+// mergeProductFoods adds label-based components to an AI analysis, which may be nil when the
+// meal is products only, and recomputes the meal totals
+func mergeProductFoods(analysis *schema.FoodAnalysis, foods []schema.DetectedFood) *schema.FoodAnalysis {
+	if len(foods) == 0 {
+		return analysis
+	}
+
+	if analysis == nil {
+		var names []string
+		for _, food := range foods {
+			names = append(names, fmt.Sprintf("%s (%s)", food.Name, food.Portion))
+		}
+		analysis = &schema.FoodAnalysis{
+			AnalysisText: "Nilai gizi dihitung dari label kemasan: " + strings.Join(names, ", ") + ".",
+		}
+	}
+
+	for i := range analysis.DetectedFoods {
+		if analysis.DetectedFoods[i].Source == "" {
+			analysis.DetectedFoods[i].Source = schema.FoodSourceAI
+		}
+	}
+	analysis.DetectedFoods = append(analysis.DetectedFoods, foods...)
+
+	analysis.TotalNutrition = sumDetectedFoods(analysis.DetectedFoods, analysis.TotalNutrition.Confidence)
+	analysis.Confidence = analysis.TotalNutrition.Confidence
+	refreshNutritionRange(analysis)
+	return analysis
+}
+
+// sumDetectedFoods totals the foods of a meal. Confidence is the calorie-weighted average, AI foods
+// without their own confidence count with the analysis confidence.
+func sumDetectedFoods(foods []schema.DetectedFood, aiConfidence float64) schema.AINutrition {
+	var total schema.AINutrition
+	var weightedConfidence, weight float64
+	for _, food := range foods {
+		n := food.Nutrition
+		total.Calories += n.Calories
+		total.Protein += n.Protein
+		total.Carbs += n.Carbs
+		total.Fat += n.Fat
+		total.Sugar += n.Sugar
+		total.Fiber += n.Fiber
+		total.Sodium += n.Sodium
+
+		confidence := n.Confidence
+		if confidence == 0 {
+			confidence = aiConfidence
+		}
+		weightedConfidence += confidence * max(n.Calories, 1)
+		weight += max(n.Calories, 1)
+	}
+	if weight > 0 {
+		total.Confidence = weightedConfidence / weight
+	}
+	return total
+}
@@ -4,30 +4,93 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"mime/multipart"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/constants"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNothingToImport = errors.New("no accepted receipt items to import")
+	ErrNoReceiptText   = errors.New("no OCR text available for local receipt parsing")
+	ErrNoReceiptItems  = errors.New("no items found in receipt text")
 )
 
 type ReceiptService struct {
 	geminiService *GeminiService
+	ocrProvider   OCRProvider // optional, used by the local fallback when the client sends no text
 }
 
-func NewReceiptService(geminiService *GeminiService) (*ReceiptService, error) {
+func NewReceiptService(geminiService *GeminiService, ocrProvider OCRProvider) (*ReceiptService, error) {
 	return &ReceiptService{
 		geminiService: geminiService,
+		ocrProvider:   ocrProvider,
 	}, nil
 }
 
-func (s *ReceiptService) AnalyzeReceipt(file multipart.File, header *multipart.FileHeader) (*schema.ReceiptAnalysisResponse, error) {
-	imgBytes, err := io.ReadAll(file)
+// AnalyzeReceipt reads a receipt with Gemini, falling back to the local text parser when Gemini fails.
+// ocrText is optional text the client already recognized from the image.
+// The image is expected to be prepared by utils.PrepareImage.
+func (s *ReceiptService) AnalyzeReceipt(imgBytes []byte, mimeType string, ocrText string) (*schema.ReceiptAnalysisResponse, error) {
+	data, err := s.analyzeWithGemini(imgBytes, mimeType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read image file: %w", err)
+		fmt.Printf("Warning: Gemini receipt analysis failed, using local parser: %v\n", err)
+
+		var localErr error
+		data, localErr = s.analyzeLocally(imgBytes, mimeType, ocrText)
+		if localErr != nil {
+			return nil, fmt.Errorf("%v; local parser: %w", err, localErr)
+		}
+	}
+
+	return &schema.ReceiptAnalysisResponse{
+		Success: true,
+		Data:    data,
+	}, nil
+}
+
+// AnalyzeReceiptText parses OCR text of a receipt without calling Gemini
+func (s *ReceiptService) AnalyzeReceiptText(text string) (*schema.ReceiptAnalysisResponse, error) {
+	data := ParseReceiptText(text)
+	if len(data.Items) == 0 {
+		return nil, ErrNoReceiptItems
+	}
+	return &schema.ReceiptAnalysisResponse{
+		Success: true,
+		Data:    data,
+	}, nil
+}
+
+func (s *ReceiptService) analyzeLocally(imgBytes []byte, mimeType, ocrText string) (*schema.ReceiptData, error) {
+	text := strings.TrimSpace(ocrText)
+	if text == "" && s.ocrProvider != nil {
+		var err error
+		text, err = s.ocrProvider.ExtractText(imgBytes, mimeType)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrNoReceiptText
+	}
+
+	data := ParseReceiptText(text)
+	if len(data.Items) == 0 {
+		return nil, ErrNoReceiptItems
+	}
+	return data, nil
+}
+
+func (s *ReceiptService) analyzeWithGemini(imgBytes []byte, mimeType string) (*schema.ReceiptData, error) {
+	if s.geminiService == nil {
+		return nil, errors.New("Gemini service is not configured")
 	}
 
 	imgBase64 := base64.StdEncoding.EncodeToString(imgBytes)
@@ -38,7 +101,7 @@ func (s *ReceiptService) AnalyzeReceipt(file multipart.File, header *multipart.F
 				Parts: []geminiPart{
 					{Text: s.createReceiptPrompt()},
 					{InlineData: &geminiInlineData{
-						MimeType: header.Header.Get("Content-Type"),
+						MimeType: mimeType,
 						Data:     imgBase64,
 					}},
 				},
@@ -80,21 +143,183 @@ func (s *ReceiptService) AnalyzeReceipt(file multipart.File, header *multipart.F
 	responseText := geminiResp.Candidates[0].Content.Parts[0].Text
 	cleanedText := utils.CleanGeminiResponse(responseText)
 
-	var geminiReceiptData struct {
-		Items      []schema.ReceiptItem `json:"items"`
-		Confidence float64              `json:"confidence"`
-	}
+	var geminiReceiptData schema.ReceiptData
 	if err := json.Unmarshal([]byte(cleanedText), &geminiReceiptData); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal receipt response: %w", err)
 	}
 
-	return &schema.ReceiptAnalysisResponse{
-		Success: true,
-		Data: &schema.ReceiptData{
-			Items:      geminiReceiptData.Items,
-			Confidence: geminiReceiptData.Confidence,
-		},
-	}, nil
+	geminiReceiptData.Source = schema.ReceiptSourceGemini
+	return &geminiReceiptData, nil
+}
+
+// CreateImportDraft scans a receipt and prepares each line as a fridge item for the user to review.
+// imageID is the stored photo of the receipt, if it was kept.
+func (s *ReceiptService) CreateImportDraft(userID uuid.UUID, upload *ImageUpload, ocrText string, imageID *uuid.UUID) (*schema.ReceiptDraft, error) {
+	analysis, err := s.AnalyzeReceipt(upload.Prepared.Data, upload.Prepared.ContentType, ocrText)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := repository.GetInventoryProductsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory products: %w", err)
+	}
+
+	// Batches start on the purchase date printed on the receipt, or today
+	today := UserToday(userID)
+	if purchaseDate, err := time.Parse("2006-01-02", analysis.Data.PurchaseDate); err == nil && !purchaseDate.After(today) {
+		today = purchaseDate
+	}
+
+	draft := &schema.ReceiptDraft{
+		Items:      []schema.ReceiptDraftItem{},
+		Confidence: analysis.Data.Confidence,
+	}
+
+	receipt, err := s.SaveReceipt(userID, analysis.Data, imageID)
+	if err != nil {
+		fmt.Printf("Warning: failed to save receipt history: %v\n", err)
+	} else {
+		draft.ReceiptID = &receipt.ID
+	}
+	for _, receiptItem := range analysis.Data.Items {
+		quantity := receiptItem.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+
+		draftItem := schema.ReceiptDraftItem{
+			Name:       receiptItem.Name,
+			Quantity:   quantity,
+			Price:      receiptItem.Price,
+			Confidence: receiptItem.Confidence,
+			Type:       receiptItemType(receiptItem.Type),
+			Amount:     float64(quantity),
+			AmountType: receiptItemUnit(receiptItem.Unit),
+			StartDate:  today.Format("2006-01-02"),
+		}
+
+		if product, score := MatchInventoryProduct(receiptItem.Name, products); product != nil {
+			draftItem.MatchedProductID = &product.ID
+			draftItem.MatchedProductName = product.Name
+			draftItem.MatchScore = score
+			// Known products keep the type and unit the user already chose
+			draftItem.Type = product.Type
+			draftItem.AmountType = product.AmountType
+		}
+
+		draftItem.ExpDate = EstimateExpDate(draftItem.Name, draftItem.Type, today).Format("2006-01-02")
+		draft.Items = append(draft.Items, draftItem)
+	}
+
+	return draft, nil
+}
+
+// ConfirmImport creates every accepted draft line as a fridge batch and moves the draft's receipt,
+// when given, into the history with its prices, all in a single transaction
+func (s *ReceiptService) ConfirmImport(userID uuid.UUID, receiptID *uuid.UUID, lines []schema.ReceiptConfirmItem) ([]schema.Item, error) {
+	var receipt *schema.Receipt
+	if receiptID != nil {
+		var err error
+		receipt, err = s.GetReceiptDetail(receiptID.String(), userID)
+		if err != nil {
+			return nil, err
+		}
+		if !receipt.IsDraft {
+			return nil, repository.ErrReceiptAlreadyImported
+		}
+	}
+
+	products, err := repository.GetInventoryProductsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory products: %w", err)
+	}
+	owned := make(map[uuid.UUID]bool, len(products))
+	for _, product := range products {
+		owned[product.ID] = true
+	}
+
+	today := UserToday(userID)
+
+	var items []schema.Item
+	for _, line := range lines {
+		if !line.Accepted {
+			continue
+		}
+
+		startDate := today
+		if line.StartDate != "" {
+			startDate, err = time.Parse("2006-01-02", line.StartDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid start date format for %s", line.Name)
+			}
+		}
+
+		expDate := EstimateExpDate(line.Name, line.Type, startDate)
+		if line.ExpDate != "" {
+			expDate, err = time.Parse("2006-01-02", line.ExpDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expiration date format for %s", line.Name)
+			}
+		}
+
+		amount := line.Amount
+		if amount <= 0 {
+			amount = 1
+		}
+
+		var productID *uuid.UUID
+		if line.ProductID != nil && owned[*line.ProductID] {
+			productID = line.ProductID
+		}
+
+		items = append(items, schema.Item{
+			UserID:     userID,
+			ProductID:  productID,
+			Name:       line.Name,
+			Type:       receiptItemType(line.Type),
+			Amount:     amount,
+			AmountType: receiptItemUnit(line.AmountType),
+			StartDate:  startDate,
+			ExpDate:    expDate,
+		})
+	}
+
+	if len(items) == 0 {
+		return nil, ErrNothingToImport
+	}
+
+	var prices []schema.PriceRecord
+	if receipt != nil {
+		prices = receiptPriceRecords(receipt, products)
+	}
+	if err := repository.ImportReceipt(items, receipt, prices); err != nil {
+		if errors.Is(err, repository.ErrReceiptAlreadyImported) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to import receipt items: %w", err)
+	}
+	return items, nil
+}
+
+func receiptItemType(itemType string) string {
+	if strings.TrimSpace(itemType) == "" {
+		return "Lainnya"
+	}
+	return strings.TrimSpace(itemType)
+}
+
+func receiptItemUnit(unit string) string {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "kg", "kilogram":
+		return "kilogram"
+	case "l", "liter", "litre":
+		return "liter"
+	case "ikat":
+		return "ikat"
+	default:
+		return "satuan"
+	}
 }
 
 func (s *ReceiptService) createReceiptPrompt() string {
@@ -107,19 +332,32 @@ Berikan respons dalam format JSON dengan struktur berikut:
             "name": "nama item",
             "quantity": jumlah (integer),
             "price": harga satuan (float),
-            "confidence": nilai kepercayaan 0-1 (float)
+            "confidence": nilai kepercayaan 0-1 (float),
+            "type": "salah satu dari: Sayur, Buah, Daging, Rempah, Minuman, Lainnya",
+            "unit": "salah satu dari: kilogram, liter, ikat, satuan"
         }
     ],
+    "store_name": "nama toko",
+    "purchase_date": "tanggal pembelian format YYYY-MM-DD",
+    "currency": "kode mata uang, contoh IDR",
+    "subtotal": total harga item sebelum pajak (float),
+    "tax": total pajak (float),
+    "total": total yang harus dibayar (float),
+    "payment_method": "metode pembayaran, contoh tunai, debit, kartu kredit, QRIS",
     "confidence": nilai kepercayaan keseluruhan 0-1 (float)
 }
 
 Petunjuk analisis:
 - Fokus pada item-item yang dibeli (produk/barang)
-- Abaikan informasi toko, tanggal, waktu, total, pajak, dan kembalian
+- Ekstrak nama toko, tanggal, subtotal, pajak, total, dan metode pembayaran dari struk
+- Abaikan waktu, uang yang dibayarkan, dan kembalian
 - Ekstrak nama item, jumlah, dan harga jika tersedia
 - Berikan confidence score berdasarkan kejelasan teks
 - Gunakan bahasa Indonesia untuk nama item
-- Hindari duplikasi item yang sama
+- Jika item yang sama tercetak beberapa kali, gabungkan dan jumlahkan quantity-nya
 - Jika tidak ada quantity yang terdeteksi, gunakan 1 sebagai default
-- Jika tidak ada harga yang terdeteksi, gunakan 0.0 sebagai default`
+- Jika tidak ada harga yang terdeteksi, gunakan 0.0 sebagai default
+- Tentukan type sesuai kategori bahan makanan, gunakan "Lainnya" untuk barang non-makanan
+- Tentukan unit dari kemasan, gunakan "satuan" jika ragu
+- Jika informasi toko, tanggal, atau total tidak terbaca, gunakan string kosong atau 0.0`
 }
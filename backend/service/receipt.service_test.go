@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+)
+
+func TestReceiptDraftsStayOutOfSpending(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "")
+	receipts := &ReceiptService{}
+	upload := &ImageUpload{Prepared: &utils.ProcessedImage{ContentType: "image/jpeg"}}
+	start, end := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	totals := func() (float64, int64) {
+		t.Helper()
+		spent, err := repository.GetTotalSpending(userID, start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var prices int64
+		database.DB.Model(&schema.PriceRecord{}).Where("user_id = ?", userID).Count(&prices)
+		return spent, prices
+	}
+
+	// The same receipt scanned twice, then abandoned
+	var draft *schema.ReceiptDraft
+	for i := 0; i < 2; i++ {
+		var err error
+		draft, err = receipts.CreateImportDraft(userID, upload, alfamartReceipt, nil)
+		if err != nil {
+			t.Fatalf("draft: %v", err)
+		}
+	}
+	if spent, prices := totals(); spent != 0 || prices != 0 {
+		t.Fatalf("drafts counted: spent %v, %d prices", spent, prices)
+	}
+	history, _, err := receipts.GetReceiptHistory(userID, schema.PageRequest{Limit: 10})
+	if err != nil || len(history) != 0 {
+		t.Fatalf("history has %d drafts, %v", len(history), err)
+	}
+
+	var lines []schema.ReceiptConfirmItem
+	for _, item := range draft.Items {
+		lines = append(lines, schema.ReceiptConfirmItem{Name: item.Name, Type: item.Type, Amount: item.Amount, AmountType: item.AmountType, StartDate: item.StartDate, Accepted: true})
+	}
+	if _, err := receipts.ConfirmImport(userID, draft.ReceiptID, lines); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if spent, prices := totals(); spent != 75000 || prices != 4 {
+		t.Errorf("after import: spent %v, %d prices, want 75000 and 4", spent, prices)
+	}
+
+	// Confirming the same draft again imports nothing
+	if _, err := receipts.ConfirmImport(userID, draft.ReceiptID, lines); !errors.Is(err, repository.ErrReceiptAlreadyImported) {
+		t.Errorf("second confirm: err = %v, want ErrReceiptAlreadyImported", err)
+	}
+	if spent, prices := totals(); spent != 75000 || prices != 4 {
+		t.Errorf("after second confirm: spent %v, %d prices", spent, prices)
+	}
+	var batches int64
+	database.DB.Model(&schema.Item{}).Where("user_id = ?", userID).Count(&batches)
+	if batches != int64(len(lines)) {
+		t.Errorf("%d batches for %d lines", batches, len(lines))
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/utils"
+	"github.com/google/uuid"
+)
+
+var ErrReceiptNotOwned = errors.New("receipt does not belong to user")
+
+// Rounding on printed receipts, below this the lines are considered to add up
+const (
+	reconcileToleranceRatio = 0.01
+	reconcileToleranceMin   = 500.0
+)
+
+// SaveReceipt stores an analyzed receipt and its lines as a draft, which joins the user's history
+// once ConfirmImport imports it
+func (s *ReceiptService) SaveReceipt(userID uuid.UUID, data *schema.ReceiptData, imageID *uuid.UUID) (*schema.Receipt, error) {
+	purchaseDate := UserToday(userID)
+	if data.PurchaseDate != "" {
+		if parsed, err := time.Parse("2006-01-02", data.PurchaseDate); err == nil {
+			purchaseDate = parsed
+		}
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(data.Currency))
+	if currency == "" {
+		currency = "IDR"
+	}
+
+	receipt := schema.Receipt{
+		UserID:        userID,
+		StoreName:     strings.TrimSpace(data.StoreName),
+		PurchaseDate:  purchaseDate,
+		Currency:      currency,
+		Subtotal:      data.Subtotal,
+		Tax:           data.Tax,
+		Total:         data.Total,
+		PaymentMethod: data.PaymentMethod,
+		ImageID:       imageID,
+		Confidence:    data.Confidence,
+		IsDraft:       true,
+	}
+
+	for _, item := range data.Items {
+		quantity := item.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+		receipt.Lines = append(receipt.Lines, schema.ReceiptLine{
+			Name:       item.Name,
+			Quantity:   quantity,
+			UnitPrice:  item.Price,
+			LineTotal:  item.Price * float64(quantity),
+			Type:       receiptItemType(item.Type),
+			Unit:       receiptItemUnit(item.Unit),
+			Confidence: item.Confidence,
+		})
+	}
+
+	ReconcileReceipt(&receipt)
+
+	if err := repository.CreateReceipt(&receipt); err != nil {
+		return nil, err
+	}
+	if imageID != nil {
+		if err := LinkImage(*imageID, userID, schema.ImageOwnerReceipt, receipt.ID); err != nil {
+			fmt.Printf("Warning: failed to link receipt image: %v\n", err)
+		}
+	}
+	return &receipt, nil
+}
+
+// ReconcileReceipt checks that the line totals add up to the printed subtotal, or to the
+// total minus tax when no subtotal was read
+func ReconcileReceipt(receipt *schema.Receipt) {
+	var linesTotal float64
+	for _, line := range receipt.Lines {
+		linesTotal += line.LineTotal
+	}
+	receipt.LinesTotal = linesTotal
+
+	expected := receipt.Subtotal
+	if expected == 0 {
+		expected = receipt.Total - receipt.Tax
+	}
+	if expected <= 0 {
+		receipt.IsReconciled = false
+		receipt.Discrepancy = 0
+		return
+	}
+
+	receipt.Discrepancy = linesTotal - expected
+	tolerance := math.Max(expected*reconcileToleranceRatio, reconcileToleranceMin)
+	receipt.IsReconciled = math.Abs(receipt.Discrepancy) <= tolerance
+}
+
+func (s *ReceiptService) GetReceiptHistory(userID uuid.UUID, page schema.PageRequest) ([]schema.Receipt, schema.PageInfo, error) {
+	receipts, err := repository.GetReceiptsByUserID(userID, page)
+	if err != nil {
+		return nil, schema.PageInfo{}, err
+	}
+
+	receipts, info := utils.TrimPage(receipts, page.Limit, func(receipt schema.Receipt) schema.PageCursor {
+		return schema.PageCursor{Keys: []time.Time{receipt.PurchaseDate, receipt.CreatedAt}, ID: receipt.ID}
+	})
+	return receipts, info, nil
+}
+
+func (s *ReceiptService) GetReceiptDetail(receiptID string, userID uuid.UUID) (*schema.Receipt, error) {
+	receipt, err := repository.GetReceiptByID(receiptID)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.UserID != userID {
+		return nil, ErrReceiptNotOwned
+	}
+	return receipt, nil
+}
@@ -0,0 +1,673 @@
+package service
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+// Local receipt parsing, used when Gemini cannot read the receipt. It works on OCR text of the
+// common Indonesian supermarket layouts:
+//
+//	Indomaret / Alfamart, one line per item:  INDOMIE GRG 85     2    3,100    6,200
+//	Hypermarkets, price on the next line:     BERAS PANDAN WANGI 5KG
+//	                                            1 X 65,900              65,900
+
+const (
+	localItemConfidence         = 0.6 // quantity x price matches the line total
+	localUnverifiedConfidence   = 0.4 // only the line total could be read
+	localReceiptConfidence      = 0.5
+	localReconciledConfidence   = 0.7
+	minReceiptAmount            = 100.0 // no item costs less than Rp 100, smaller numbers belong to the name
+	maxReceiptQuantityPerLine   = 999.0
+	receiptHeaderStoreNameLines = 3
+)
+
+// Known chains, matched against the receipt header
+var receiptStores = []struct {
+	keyword string
+	name    string
+}{
+	{"indomaret", "Indomaret"},
+	{"alfamart", "Alfamart"},
+	{"alfamidi", "Alfamidi"},
+	{"lawson", "Lawson"},
+	{"hypermart", "Hypermart"},
+	{"carrefour", "Carrefour"},
+	{"transmart", "Transmart"},
+	{"giant", "Giant"},
+	{"superindo", "Superindo"},
+	{"lotte", "Lotte Mart"},
+	{"hero", "Hero"},
+	{"ranch market", "Ranch Market"},
+	{"foodhall", "Foodhall"},
+	{"yogya", "Yogya"},
+	{"tip top", "Tip Top"},
+}
+
+// Lines that are never items
+var receiptNoiseKeywords = []string{
+	"npwp", "telp", "telepon", "jl.", "jln", "jalan", "kasir", "member", "poin", "point",
+	"terima kasih", "terimakasih", "layanan konsumen", "kritik", "saran", "www.", ".com",
+	"sms", "whatsapp", "struk", "no.", "pt.", "tbk", "harga sudah termasuk",
+}
+
+var receiptPaymentMethods = []struct {
+	keyword string
+	name    string
+}{
+	{"tunai", "Tunai"},
+	{"cash", "Tunai"},
+	{"debit", "Debit"},
+	{"kredit", "Kartu Kredit"},
+	{"credit", "Kartu Kredit"},
+	{"qris", "QRIS"},
+	{"gopay", "GoPay"},
+	{"ovo", "OVO"},
+	{"dana", "DANA"},
+	{"shopeepay", "ShopeePay"},
+	{"linkaja", "LinkAja"},
+	{"flazz", "Flazz"},
+	{"e-money", "e-Money"},
+}
+
+var receiptDiscountKeywords = []string{"disc", "diskon", "potongan", "promo", "voucher"}
+
+// What a summary line at the end of the receipt holds
+const (
+	receiptSummaryTotal = iota
+	receiptSummarySubtotal
+	receiptSummaryItemCount // the item count, followed by the total on Alfamart
+	receiptSummaryTax
+	receiptSummaryInfo // printed for the shopper only, such as change and savings
+)
+
+// Labels that open a summary line, longest first so "total item" is not read as "total"
+var receiptSummaryLabels = []struct {
+	label string
+	kind  int
+}{
+	{"total belanja", receiptSummaryTotal},
+	{"total bayar", receiptSummaryTotal},
+	{"grand total", receiptSummaryTotal},
+	{"harga jual", receiptSummaryTotal},
+	{"jumlah bayar", receiptSummaryTotal},
+	{"total item", receiptSummaryItemCount},
+	{"jumlah item", receiptSummaryItemCount},
+	{"total hemat", receiptSummaryInfo},
+	{"anda hemat", receiptSummaryInfo},
+	{"hemat anda", receiptSummaryInfo},
+	{"sub total", receiptSummarySubtotal},
+	{"subtotal", receiptSummarySubtotal},
+	{"total", receiptSummaryTotal},
+	{"kembalian", receiptSummaryInfo},
+	{"kembali", receiptSummaryInfo},
+	{"change", receiptSummaryInfo},
+	{"dpp", receiptSummaryInfo},
+	{"ppn", receiptSummaryTax},
+	{"pajak", receiptSummaryTax},
+	{"tax", receiptSummaryTax},
+}
+
+var receiptWeightUnits = map[string]bool{"kg": true, "g": true, "gr": true, "gram": true}
+
+var receiptQuantityUnits = map[string]bool{
+	"kg": true, "g": true, "gr": true, "gram": true, "pcs": true, "pc": true, "bh": true, "buah": true, "pak": true,
+}
+
+var (
+	receiptDatePattern    = regexp.MustCompile(`\b(\d{1,2})[./-](\d{1,2})[./-](\d{2,4})\b`)
+	receiptISODatePattern = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	thousandsPattern      = regexp.MustCompile(`^\d{1,3}([.,]\d{3})+$`)
+	decimalPattern        = regexp.MustCompile(`^\d+([.,]\d{3})*[.,]\d{1,2}$`)
+	digitsPattern         = regexp.MustCompile(`^\d+$`)
+	letterPattern         = regexp.MustCompile(`[a-zA-Z]`)
+)
+
+// ParseReceiptText extracts items, totals, store and date from OCR text of a receipt
+func ParseReceiptText(text string) *schema.ReceiptData {
+	data := &schema.ReceiptData{
+		Items:    []schema.ReceiptItem{},
+		Currency: "IDR",
+		Source:   schema.ReceiptSourceLocal,
+	}
+
+	var pendingName string
+	inSummary := false
+
+	for i, rawLine := range strings.Split(text, "\n") {
+		line := strings.Join(strings.Fields(rawLine), " ")
+		if line == "" {
+			continue
+		}
+		lower := strings.ToLower(line)
+
+		if data.StoreName == "" && len(data.Items) == 0 {
+			if store := detectReceiptStore(lower); store != "" {
+				data.StoreName = store
+				continue
+			}
+		}
+		if data.PurchaseDate == "" {
+			if date := detectReceiptDate(line); date != "" {
+				data.PurchaseDate = date
+				continue
+			}
+		}
+		if containsReceiptWord(lower, receiptNoiseKeywords) {
+			continue
+		}
+
+		// Only a total ends the item list, other summary lines can sit between items
+		if summary, total := parseReceiptSummaryLine(lower, data); summary {
+			inSummary = inSummary || total
+			pendingName = ""
+			continue
+		}
+		if inSummary {
+			continue
+		}
+
+		if isReceiptDiscountLine(lower) {
+			applyReceiptDiscount(line, data)
+			continue
+		}
+
+		// Hypermarket layout, the quantity and price follow on their own line. Until the first
+		// item only lines laid out like one count, "BANDUNG 40115" in the address is not an item.
+		if pendingName != "" {
+			if item, structured, ok := parseReceiptPriceLine(pendingName, line); ok && (structured || len(data.Items) > 0) {
+				data.Items = append(data.Items, item)
+				pendingName = ""
+				continue
+			}
+		}
+
+		if item, structured, ok := parseReceiptItemLine(line); ok && (structured || len(data.Items) > 0) {
+			data.Items = append(data.Items, item)
+			pendingName = ""
+			continue
+		}
+
+		if letterPattern.MatchString(line) {
+			if len(data.Items) == 0 && data.StoreName == "" && i < receiptHeaderStoreNameLines {
+				data.StoreName = line
+				continue
+			}
+			pendingName = line
+		}
+	}
+
+	data.Confidence = localReceiptConfidence
+	if len(data.Items) == 0 {
+		data.Confidence = 0
+	} else if localReceiptReconciles(data) {
+		data.Confidence = localReconciledConfidence
+	}
+
+	return data
+}
+
+func detectReceiptStore(lower string) string {
+	for _, store := range receiptStores {
+		if strings.Contains(lower, store.keyword) {
+			return store.name
+		}
+	}
+	return ""
+}
+
+func detectReceiptDate(line string) string {
+	if match := receiptISODatePattern.FindStringSubmatch(line); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		day, _ := strconv.Atoi(match[3])
+		return formatReceiptDate(year, month, day)
+	}
+
+	if match := receiptDatePattern.FindStringSubmatch(line); match != nil {
+		day, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		year, _ := strconv.Atoi(match[3])
+		if year < 100 {
+			year += 2000
+		}
+		return formatReceiptDate(year, month, day)
+	}
+	return ""
+}
+
+func formatReceiptDate(year, month, day int) string {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return ""
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
+
+// parseReceiptSummaryLine fills totals, tax and payment method from a line opened by a summary label.
+// summary reports whether the line was one, total whether it was a total that ends the item list.
+// Totals and payments are a label and a single amount, "HARGA JUAL : 29,800".
+func parseReceiptSummaryLine(lower string, data *schema.ReceiptData) (summary bool, total bool) {
+	fields := receiptLabelFields(lower)
+	if len(fields) == 0 {
+		return false, false
+	}
+
+	for _, summaryLabel := range receiptSummaryLabels {
+		rest, ok := cutReceiptLabel(fields, summaryLabel.label)
+		if !ok {
+			continue
+		}
+		amounts, onlyAmounts := receiptAmounts(rest)
+
+		switch summaryLabel.kind {
+		case receiptSummaryInfo:
+			return true, false
+		case receiptSummaryItemCount:
+			// Alfamart prints the item count and the total on one line, "Total Item 3 56.500"
+			if !onlyAmounts || len(amounts) == 0 || len(amounts) > 2 {
+				return false, false
+			}
+			if len(amounts) == 2 && amounts[1] >= minReceiptAmount && data.Total == 0 {
+				data.Total = amounts[1]
+			}
+			return true, true
+		}
+
+		if !onlyAmounts || len(amounts) != 1 {
+			return false, false
+		}
+		switch summaryLabel.kind {
+		case receiptSummaryTax:
+			data.Tax = amounts[0]
+			return true, false
+		case receiptSummarySubtotal:
+			data.Subtotal = amounts[0]
+		default:
+			if data.Total == 0 {
+				data.Total = amounts[0]
+			}
+		}
+		return true, true
+	}
+
+	// Payment words are matched whole, "cash" must not catch "cashew", and "kartu debit" is paid by debit
+	label := fields
+	if label[0] == "kartu" || label[0] == "bayar" {
+		label = label[1:]
+	}
+	if len(label) < 2 {
+		return false, false
+	}
+	if amounts, onlyAmounts := receiptAmounts(label[1:]); !onlyAmounts || len(amounts) != 1 {
+		return false, false
+	}
+	for _, method := range receiptPaymentMethods {
+		if label[0] == method.keyword {
+			if data.PaymentMethod == "" {
+				data.PaymentMethod = method.name
+			}
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// isReceiptDiscountLine reports whether a line is a discount on the item above it: a discount word
+// opening the line, with a negative amount or with nothing but the amount after the word.
+// "PROMO MIE SEDAAP 5PCS 12,500" is an item on promotion.
+func isReceiptDiscountLine(lower string) bool {
+	fields := receiptLabelFields(lower)
+	if len(fields) < 2 {
+		return false
+	}
+	for _, keyword := range receiptDiscountKeywords {
+		if fields[0] != keyword {
+			continue
+		}
+		if amounts, onlyAmounts := receiptAmounts(fields[1:]); onlyAmounts && len(amounts) == 1 {
+			return true
+		}
+		amount, ok := lastReceiptNumber(lower)
+		return ok && amount < 0
+	}
+	return false
+}
+
+// receiptLabelFields splits a line into words, dropping the ":" and "=" printed after labels
+func receiptLabelFields(lower string) []string {
+	var fields []string
+	for _, field := range strings.Fields(lower) {
+		if field = strings.Trim(field, ":="); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// cutReceiptLabel removes a label matched as whole words at the start of the line
+func cutReceiptLabel(fields []string, label string) ([]string, bool) {
+	words := strings.Fields(label)
+	if len(fields) < len(words) {
+		return nil, false
+	}
+	for i, word := range words {
+		if fields[i] != word {
+			return nil, false
+		}
+	}
+	return fields[len(words):], true
+}
+
+// receiptAmounts reads the numbers after a label, onlyAmounts reports whether nothing else followed
+func receiptAmounts(fields []string) (amounts []float64, onlyAmounts bool) {
+	for _, field := range fields {
+		if field == "rp" || field == "rp." {
+			continue
+		}
+		number, ok := parseReceiptNumber(field)
+		if !ok {
+			return amounts, false
+		}
+		amounts = append(amounts, number)
+	}
+	return amounts, true
+}
+
+// applyReceiptDiscount takes a discount line off the item above it
+func applyReceiptDiscount(line string, data *schema.ReceiptData) {
+	amount, ok := lastReceiptNumber(line)
+	if !ok || len(data.Items) == 0 {
+		return
+	}
+
+	item := &data.Items[len(data.Items)-1]
+	lineTotal := item.Price*float64(item.Quantity) - math.Abs(amount)
+	if lineTotal <= 0 {
+		return
+	}
+	item.Price = lineTotal / float64(item.Quantity)
+}
+
+// parseReceiptItemLine reads "NAME [QTY] [PRICE] TOTAL" with optional x or @ between numbers,
+// structured reports whether the numbers were laid out like an item, see buildReceiptItem
+func parseReceiptItemLine(line string) (schema.ReceiptItem, bool, bool) {
+	fields := strings.Fields(line)
+
+	var numbers []float64
+	nameEnd := len(fields)
+	for nameEnd > 0 && len(numbers) < 3 {
+		field := strings.ToLower(fields[nameEnd-1])
+		if field == "x" || field == "@" || field == "rp" || field == "rp." {
+			nameEnd--
+			continue
+		}
+		number, ok := parseReceiptNumber(field)
+		if !ok {
+			break
+		}
+		numbers = append([]float64{number}, numbers...)
+		nameEnd--
+	}
+
+	name := strings.Join(fields[:nameEnd], " ")
+	if !letterPattern.MatchString(name) || len(numbers) == 0 {
+		return schema.ReceiptItem{}, false, false
+	}
+
+	return buildReceiptItem(name, numbers)
+}
+
+// parseReceiptPriceLine reads the "QTY [UNIT] X PRICE TOTAL" line printed under an item name
+func parseReceiptPriceLine(name, line string) (schema.ReceiptItem, bool, bool) {
+	fields := strings.Fields(strings.ToLower(line))
+
+	weighted := false
+	var numbers []float64
+	for _, field := range fields {
+		if field == "x" || field == "@" || field == "rp" || field == "rp." {
+			continue
+		}
+		if receiptQuantityUnits[field] {
+			weighted = weighted || receiptWeightUnits[field]
+			continue
+		}
+
+		// Weights use a decimal point, "0.512KG" or "0,512 kg"
+		if unit := receiptUnitSuffix(field); unit != "" && len(numbers) == 0 {
+			if quantity, ok := parseReceiptDecimal(strings.TrimSuffix(field, unit)); ok {
+				numbers = append(numbers, quantity)
+				weighted = weighted || receiptWeightUnits[unit]
+				continue
+			}
+		}
+		if len(numbers) == 0 && strings.HasPrefix(field, "0") {
+			if quantity, ok := parseReceiptDecimal(field); ok {
+				numbers = append(numbers, quantity)
+				continue
+			}
+		}
+
+		number, ok := parseReceiptNumber(field)
+		if !ok {
+			return schema.ReceiptItem{}, false, false
+		}
+		numbers = append(numbers, number)
+	}
+	if len(numbers) == 0 {
+		return schema.ReceiptItem{}, false, false
+	}
+
+	if weighted && len(numbers) == 3 {
+		// Sold by weight, keep the line as one priced unit
+		item := schema.ReceiptItem{
+			Name:       name,
+			Quantity:   1,
+			Price:      numbers[2],
+			Confidence: localUnverifiedConfidence,
+		}
+		if receiptAmountsMatch(numbers[0]*numbers[1], numbers[2]) {
+			item.Confidence = localItemConfidence
+		}
+		return item, true, numbers[2] >= minReceiptAmount
+	}
+	return buildReceiptItem(name, numbers)
+}
+
+// buildReceiptItem reads the numbers after an item name. A leading number is only taken as the quantity
+// when quantity x price gives the total, or the total divides into a whole price, otherwise it belongs
+// to the name ("INDOMIE GRG 85"). structured reports whether the numbers had that layout, a single
+// amount on its own could be anything, such as the postcode in the address.
+func buildReceiptItem(name string, numbers []float64) (schema.ReceiptItem, bool, bool) {
+	item := schema.ReceiptItem{Name: name, Quantity: 1, Confidence: localUnverifiedConfidence}
+	total := numbers[len(numbers)-1]
+	if total < minReceiptAmount {
+		return schema.ReceiptItem{}, false, false
+	}
+
+	structured := false
+	switch len(numbers) {
+	case 1:
+		item.Price = total
+	case 2:
+		first := numbers[0]
+		unitPrice := total / first
+		switch {
+		case isReceiptQuantity(first) && unitPrice >= minReceiptAmount && unitPrice == math.Trunc(unitPrice):
+			item.Quantity = int(first)
+			item.Price = unitPrice
+			structured = true
+		case first >= minReceiptAmount && receiptAmountsMatch(first*math.Round(total/first), total):
+			item.Quantity = int(math.Round(total / first))
+			item.Price = first
+			item.Confidence = localItemConfidence
+			structured = true
+		case first > 0 && first < minReceiptAmount:
+			item.Name = name + " " + strconv.FormatFloat(first, 'f', -1, 64)
+			item.Price = total
+		default:
+			item.Price = total
+		}
+	default:
+		quantity, price := numbers[len(numbers)-3], numbers[len(numbers)-2]
+		switch {
+		case isReceiptQuantity(quantity) && receiptAmountsMatch(quantity*price, total):
+			item.Quantity = int(quantity)
+			item.Price = price
+			item.Confidence = localItemConfidence
+			structured = true
+		case receiptAmountsMatch(price, total) && quantity > 0 && quantity < minReceiptAmount:
+			// No quantity printed, the number before the price is part of the name
+			item.Name = name + " " + strconv.FormatFloat(quantity, 'f', -1, 64)
+			item.Price = price
+			item.Confidence = localItemConfidence
+			structured = true
+		default:
+			item.Price = total
+		}
+	}
+	return item, structured, true
+}
+
+func isReceiptQuantity(value float64) bool {
+	return value > 0 && value == math.Trunc(value) && value <= maxReceiptQuantityPerLine
+}
+
+// parseReceiptNumber reads Indonesian amounts: "6,200", "6.200", "Rp6.200", "-1.000", "(1,000)", "1.000-"
+func parseReceiptNumber(field string) (float64, bool) {
+	field = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(field), "rp."), "rp")
+
+	negative := false
+	if strings.HasPrefix(field, "(") && strings.HasSuffix(field, ")") {
+		field, negative = field[1:len(field)-1], true
+	}
+	if strings.HasPrefix(field, "-") {
+		field, negative = field[1:], true
+	}
+	if strings.HasSuffix(field, "-") {
+		field, negative = field[:len(field)-1], true
+	}
+
+	var value float64
+	var err error
+	switch {
+	case digitsPattern.MatchString(field):
+		value, err = strconv.ParseFloat(field, 64)
+	case thousandsPattern.MatchString(field):
+		value, err = strconv.ParseFloat(strings.NewReplacer(".", "", ",", "").Replace(field), 64)
+	case decimalPattern.MatchString(field):
+		return parseReceiptDecimal(field)
+	default:
+		return 0, false
+	}
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		value = -value
+	}
+	return value, true
+}
+
+// parseReceiptDecimal reads a quantity where the last separator is the decimal point
+func parseReceiptDecimal(field string) (float64, bool) {
+	last := strings.LastIndexAny(field, ".,")
+	if last < 0 {
+		value, err := strconv.ParseFloat(field, 64)
+		return value, err == nil
+	}
+
+	whole := strings.NewReplacer(".", "", ",", "").Replace(field[:last])
+	value, err := strconv.ParseFloat(whole+"."+field[last+1:], 64)
+	return value, err == nil
+}
+
+func receiptUnitSuffix(field string) string {
+	for _, unit := range []string{"kg", "gr", "g"} {
+		if strings.HasSuffix(field, unit) && len(field) > len(unit) {
+			return unit
+		}
+	}
+	return ""
+}
+
+func lastReceiptNumber(line string) (float64, bool) {
+	fields := strings.Fields(line)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if number, ok := parseReceiptNumber(strings.Trim(fields[i], ":")); ok {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+func countReceiptNumbers(line string) int {
+	count := 0
+	for _, field := range strings.Fields(line) {
+		if _, ok := parseReceiptNumber(strings.Trim(field, ":")); ok {
+			count++
+		}
+	}
+	return count
+}
+
+func receiptAmountsMatch(a, b float64) bool {
+	return math.Abs(a-b) <= math.Max(b*reconcileToleranceRatio, 1)
+}
+
+// localReceiptReconciles checks the parsed lines against the printed totals
+func localReceiptReconciles(data *schema.ReceiptData) bool {
+	expected := data.Subtotal
+	if expected == 0 {
+		expected = data.Total - data.Tax
+	}
+	if expected <= 0 {
+		return false
+	}
+
+	var linesTotal float64
+	for _, item := range data.Items {
+		linesTotal += item.Price * float64(item.Quantity)
+	}
+	tolerance := math.Max(expected*reconcileToleranceRatio, reconcileToleranceMin)
+	return math.Abs(linesTotal-expected) <= tolerance
+}
+
+// containsReceiptWord reports whether a keyword occurs as a whole word, "saran" must not catch
+// "sarana". Keywords with punctuation at an end, such as "jl." and ".com", are open on that side.
+func containsReceiptWord(s string, keywords []string) bool {
+	for _, keyword := range keywords {
+		for offset := 0; ; {
+			index := strings.Index(s[offset:], keyword)
+			if index < 0 {
+				break
+			}
+			start, end := offset+index, offset+index+len(keyword)
+			if isReceiptWordBoundary(s, start-1, keyword[0]) && isReceiptWordBoundary(s, end, keyword[len(keyword)-1]) {
+				return true
+			}
+			offset = start + 1
+		}
+	}
+	return false
+}
+
+// isReceiptWordBoundary checks the character at i next to a keyword that ends in edge
+func isReceiptWordBoundary(s string, i int, edge byte) bool {
+	if !isReceiptWordChar(edge) || i < 0 || i >= len(s) {
+		return true
+	}
+	return !isReceiptWordChar(s[i])
+}
+
+func isReceiptWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+const indomaretReceipt = `INDOMARET
+PT. INDOMARCO PRISMATAMA
+JL. SOEKARNO HATTA NO. 123
+BANDUNG 40115
+NPWP : 01.337.994.6-092.000
+12.03.2024-18:42 2.0.31 4HX1-011-ABC/KSR01
+INDOMIE GRG 85       2    3,100     6,200
+AQUA 600ML           1    3,500     3,500
+SILVERQUEEN 62G      1   15,900    15,900
+POTONGAN                           -2,000
+INDOMIE AYAM BWG 85             6,200
+HARGA JUAL :                       29,800
+TUNAI      :                       50,000
+KEMBALI    :                       20,200
+DPP = 26,847  PPN = 2,953
+LAYANAN KONSUMEN SMS/WA 0811 1500 280`
+
+const alfamartReceipt = `ALFAMART
+PT. SUMBER ALFARIA TRIJAYA, TBK
+JL. M.H. THAMRIN NO. 9
+TANGERANG 15143
+NPWP: 01.336.238.9-054.000
+Bon 1A2B-3C4D          Kasir: RINA
+SARIROTI TWR CKL    2    5,500    11,000
+ULTRA MILK CKL 250  3    6,200    18,600
+ANDA HEMAT                         1,000
+SUSU UHT FULL CRM 1L 1  18,500    18,500
+BERAS SLYR 5KG      1   26,900    26,900
+Total Item 4                      75,000
+Tunai                            100,000
+Kembalian                         25,000
+DPP = 67,568  PPN = 7,432
+Tgl. 12-03-2024 18:42:11 V.2024.1.0`
+
+func TestParseReceiptText(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		store      string
+		date       string
+		total      float64
+		payment    string
+		items      []schema.ReceiptItem
+		reconciles bool
+	}{
+		{
+			name:    "indomaret",
+			text:    indomaretReceipt,
+			store:   "Indomaret",
+			date:    "2024-03-12",
+			total:   29800,
+			payment: "Tunai",
+			items: []schema.ReceiptItem{
+				{Name: "INDOMIE GRG 85", Quantity: 2, Price: 3100},
+				{Name: "AQUA 600ML", Quantity: 1, Price: 3500},
+				{Name: "SILVERQUEEN 62G", Quantity: 1, Price: 13900},
+				// "85" is the flavour, not a quantity: 6,200 / 85 is no price
+				{Name: "INDOMIE AYAM BWG 85", Quantity: 1, Price: 6200},
+			},
+			reconciles: true,
+		},
+		{
+			name:    "alfamart",
+			text:    alfamartReceipt,
+			store:   "Alfamart",
+			date:    "2024-03-12",
+			total:   75000,
+			payment: "Tunai",
+			items: []schema.ReceiptItem{
+				{Name: "SARIROTI TWR CKL", Quantity: 2, Price: 5500},
+				{Name: "ULTRA MILK CKL 250", Quantity: 3, Price: 6200},
+				// "ANDA HEMAT" above only tells the savings, it does not end the items
+				{Name: "SUSU UHT FULL CRM 1L", Quantity: 1, Price: 18500},
+				{Name: "BERAS SLYR 5KG", Quantity: 1, Price: 26900},
+			},
+			reconciles: true,
+		},
+		{
+			name:  "header lines are not items",
+			text:  "TOKO SUMBER REJEKI\nBANDUNG 40115\nKOTA 12 40115\nGULA PASIR 1KG   1  17,500  17,500\nTOTAL   17,500",
+			store: "TOKO SUMBER REJEKI",
+			total: 17500,
+			items: []schema.ReceiptItem{
+				{Name: "GULA PASIR 1KG", Quantity: 1, Price: 17500},
+			},
+			reconciles: true,
+		},
+		{
+			name:  "item named like a summary label",
+			text:  "TOKO MAKMUR\nHEMAT PACK SUSU   2  12,000  24,000\nROTI TAWAR   1  15,000  15,000\nTOTAL   39,000",
+			store: "TOKO MAKMUR",
+			total: 39000,
+			items: []schema.ReceiptItem{
+				{Name: "HEMAT PACK SUSU", Quantity: 2, Price: 12000},
+				{Name: "ROTI TAWAR", Quantity: 1, Price: 15000},
+			},
+			reconciles: true,
+		},
+		{
+			name:  "item on promotion is not a discount",
+			text:  "INDOMARET\nGULA PASIR 1KG   1  17,500  17,500\nPROMO MIE SEDAAP 5PCS   12,500\nTOTAL   30,000",
+			store: "Indomaret",
+			total: 30000,
+			items: []schema.ReceiptItem{
+				{Name: "GULA PASIR 1KG", Quantity: 1, Price: 17500},
+				{Name: "PROMO MIE SEDAAP 5PCS", Quantity: 1, Price: 12500},
+			},
+			reconciles: true,
+		},
+		{
+			name:  "hypermarket price lines",
+			text:  "HYPERMART\nBERAS PANDAN WANGI 5KG\n1 X 65,900   65,900\nDAGING SAPI\n0.512 KG X 125,000   64,000\nSUBTOTAL   129,900",
+			store: "Hypermart",
+			items: []schema.ReceiptItem{
+				{Name: "BERAS PANDAN WANGI 5KG", Quantity: 1, Price: 65900},
+				{Name: "DAGING SAPI", Quantity: 1, Price: 64000},
+			},
+			reconciles: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := ParseReceiptText(tt.text)
+
+			if data.StoreName != tt.store {
+				t.Errorf("store = %q, want %q", data.StoreName, tt.store)
+			}
+			if data.PurchaseDate != tt.date {
+				t.Errorf("date = %q, want %q", data.PurchaseDate, tt.date)
+			}
+			if data.Total != tt.total {
+				t.Errorf("total = %v, want %v", data.Total, tt.total)
+			}
+			if data.PaymentMethod != tt.payment {
+				t.Errorf("payment = %q, want %q", data.PaymentMethod, tt.payment)
+			}
+			if len(data.Items) != len(tt.items) {
+				t.Fatalf("got %d items %+v, want %d", len(data.Items), data.Items, len(tt.items))
+			}
+			for i, want := range tt.items {
+				got := data.Items[i]
+				if got.Name != want.Name || got.Quantity != want.Quantity || got.Price != want.Price {
+					t.Errorf("item %d = %q x%d @ %v, want %q x%d @ %v", i, got.Name, got.Quantity, got.Price, want.Name, want.Quantity, want.Price)
+				}
+			}
+			if reconciles := localReceiptReconciles(data); reconciles != tt.reconciles {
+				t.Errorf("reconciles = %v, want %v", reconciles, tt.reconciles)
+			}
+		})
+	}
+}
+
+func TestParseReceiptNumber(t *testing.T) {
+	tests := []struct {
+		field string
+		want  float64
+		ok    bool
+	}{
+		{"6,200", 6200, true},
+		{"6.200", 6200, true},
+		{"Rp6.200", 6200, true},
+		{"-1.000", -1000, true},
+		{"(1,000)", -1000, true},
+		{"1.000-", -1000, true},
+		{"85", 85, true},
+		{"62G", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseReceiptNumber(tt.field)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseReceiptNumber(%q) = %v, %v, want %v, %v", tt.field, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestContainsReceiptWord(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"kritik dan saran hubungi kami", true},
+		{"jl. m.h. thamrin no. 9", true},
+		{"belanja di www.alfamart.co.id", true},
+		{"sarana air 600ml 1 3,000 3,000", false},
+		{"pointer 1 2,000 2,000", false},
+	}
+
+	for _, tt := range tests {
+		if got := containsReceiptWord(tt.line, receiptNoiseKeywords); got != tt.want {
+			t.Errorf("containsReceiptWord(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
 	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
@@ -26,8 +27,10 @@ func NewRecipeService(geminiService *GeminiService, db *gorm.DB) *RecipeService
 
 // GenerateAndSaveRecipes generates recipes based on various user data and saves them to the DB.
 func (s *RecipeService) GenerateAndSaveRecipes(userID uuid.UUID) error {
+	loc := UserLocation(userID)
+
 	// 1. Get user's fresh items
-	items, err := repository.GetAllFreshItem(userID.String())
+	items, err := repository.GetAllFreshItem(userID.String(), WallClock(time.Now(), loc))
 	if err != nil {
 		return fmt.Errorf("failed to get user items: %w", err)
 	}
@@ -43,7 +46,8 @@ func (s *RecipeService) GenerateAndSaveRecipes(userID uuid.UUID) error {
 	}
 
 	// 3. Get user's daily nutrition summary
-	todayJournals, err := repository.GetTodayFoodJournalByUserID(userID.String())
+	todayStart, todayEnd := TodayBounds(loc)
+	todayJournals, err := repository.GetTodayFoodJournalByUserID(userID.String(), todayStart, todayEnd)
 	if err != nil {
 		fmt.Printf("Could not get nutrition summary for user %s: %v\n", userID, err)
 	}
@@ -59,8 +63,14 @@ func (s *RecipeService) GenerateAndSaveRecipes(userID uuid.UUID) error {
 		todayNutrition.Sodium += journal.AINutrition.Sodium
 	}
 
+	targets, err := GetNutritionTargets(userID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get nutrition targets for user %s: %v\n", userID, err)
+		targets = ComputeNutritionTargets(userPref)
+	}
+
 	// 4. Create prompt for Gemini
-	prompt := s.createRecipePrompt(items, userPref, &todayNutrition)
+	prompt := s.createRecipePrompt(items, userPref, targets, &todayNutrition)
 
 	// 5. Call Gemini service
 	response, err := s.geminiService.GenerateContent(prompt)
@@ -77,7 +87,14 @@ func (s *RecipeService) GenerateAndSaveRecipes(userID uuid.UUID) error {
 		return fmt.Errorf("failed to unmarshal recipes response: %w", err)
 	}
 
-	// 7. Save recipes to the database
+	// 7. Flag recipes that do not fit the meal budget
+	if mealBudget, ok := recipeMealBudget(userPref); ok {
+		for i := range recipes {
+			recipes[i].OverBudget = float64(recipes[i].Price) > mealBudget
+		}
+	}
+
+	// 8. Save recipes to the database
 	if err := repository.UpsertGeneratedRecipes(userID, recipes); err != nil {
 		return fmt.Errorf("failed to save generated recipes: %w", err)
 	}
@@ -85,7 +102,18 @@ func (s *RecipeService) GenerateAndSaveRecipes(userID uuid.UUID) error {
 	return nil
 }
 
-func (s *RecipeService) createRecipePrompt(items []schema.Item, pref *schema.UserPreference, nutrition *schema.AINutrition) string {
+// Daily food cost is spread over this many meals when budgeting a single recipe
+const mealsPerDay = 3
+
+// recipeMealBudget is the most one recipe may cost in budget-aware mode
+func recipeMealBudget(pref *schema.UserPreference) (float64, bool) {
+	if !pref.BudgetAwareMode || pref.DailyFoodCost <= 0 {
+		return 0, false
+	}
+	return pref.DailyFoodCost / mealsPerDay, true
+}
+
+func (s *RecipeService) createRecipePrompt(items []schema.Item, pref *schema.UserPreference, targets *schema.NutritionTargets, nutrition *schema.AINutrition) string {
 	var itemNames []string
 	for _, item := range items {
 		itemNames = append(itemNames, item.Name)
@@ -101,19 +129,26 @@ func (s *RecipeService) createRecipePrompt(items []schema.Item, pref *schema.Use
 		tagsStr = "tidak ada preferensi spesifik"
 	}
 
-	// Basic daily needs (can be made more dynamic later)
-	targetCalories := 2000.0
-	targetProtein := 50.0
-	targetCarbs := 250.0
-	targetFat := 70.0
-
+	remaining := RemainingNutrition(targets, *nutrition)
 	nutritionNeedsStr := fmt.Sprintf(
-		"Sisa kebutuhan gizi hari ini: Kalori: %.0f kcal, Protein: %.0f g, Karbohidrat: %.0f g, Lemak: %.0f g.",
-		targetCalories-nutrition.Calories,
-		targetProtein-nutrition.Protein,
-		targetCarbs-nutrition.Carbs,
-		targetFat-nutrition.Fat,
+		"Sisa kebutuhan gizi hari ini: Kalori: %.0f kcal, Protein: %.0f g, Karbohidrat: %.0f g, Lemak: %.0f g, Serat: %.0f g. "+
+			"Sisa batas harian: Gula: %.0f g, Natrium: %.0f mg.",
+		remaining.Calories,
+		remaining.Protein,
+		remaining.Carbs,
+		remaining.Fat,
+		remaining.Fiber,
+		remaining.Sugar,
+		remaining.Sodium,
 	)
+	if len(targets.Notes) > 0 {
+		nutritionNeedsStr += " Catatan: " + strings.Join(targets.Notes, "; ") + "."
+	}
+
+	budgetStr := "tidak dibatasi"
+	if mealBudget, ok := recipeMealBudget(pref); ok {
+		budgetStr = fmt.Sprintf("maksimal Rp %.0f per resep (anggaran makan harian Rp %.0f)", mealBudget, pref.DailyFoodCost)
+	}
 
 	prompt := fmt.Sprintf(`
 	Anda adalah seorang ahli gizi dan koki. Buatkan 8 rekomendasi resep masakan yang dipersonalisasi untuk pengguna dengan informasi berikut:
@@ -122,12 +157,14 @@ func (s *RecipeService) createRecipePrompt(items []schema.Item, pref *schema.Use
 	2.  **Preferensi Rasa/Masakan (Tags)**: %s
 	3.  **Kebutuhan Gizi Hari Ini**: %s
 	4.  **Info Pengguna**: Usia: %d, Target Kesehatan: %s, Aktivitas: %s
+	5.  **Anggaran**: %s
 
 	**Tugas Anda:**
 	Buat resep yang memaksimalkan penggunaan **Bahan Tersedia**.
 	Sesuaikan resep dengan **Preferensi Rasa/Masakan**.
 	Prioritaskan resep yang membantu memenuhi **Kebutuhan Gizi Hari Ini**.
 	Pertimbangkan **Info Pengguna** untuk membuat resep yang relevan dan sehat.
+	Isi field "price" dengan estimasi biaya bahan yang masih perlu dibeli (Rupiah) dan usahakan tidak melebihi **Anggaran**.
 
 	**PENTING: Berikan respons HANYA dalam format array JSON yang valid dan bisa di-parse. Jangan tambahkan teks atau markdown lain di luar array JSON.**
 	Struktur JSON harus sama persis dengan contoh di bawah ini, termasuk semua nama field dan tipe datanya.
@@ -176,7 +213,7 @@ func (s *RecipeService) createRecipePrompt(items []schema.Item, pref *schema.Use
 	    ]
 	  }
 	]
-	`, itemsStr, tagsStr, nutritionNeedsStr, pref.Age, pref.HealthTarget, pref.DailyActivity)
+	`, itemsStr, tagsStr, nutritionNeedsStr, pref.Age, pref.HealthTarget, pref.DailyActivity, budgetStr)
 
 	return prompt
 }
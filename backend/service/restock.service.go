@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+type LowStockItem struct {
+	ProductID       uuid.UUID
+	Name            string
+	Type            string
+	AmountType      string
+	CurrentAmount   float64
+	MinQuantity     float64
+	RestockQuantity float64
+	Shortfall       float64
+}
+
+type RestockRuleInput struct {
+	MinQuantity     float64
+	RestockQuantity float64 // optional, defaults to MinQuantity
+}
+
+func SetRestockRule(productID string, userID uuid.UUID, input RestockRuleInput) (*schema.RestockRule, error) {
+	if input.MinQuantity <= 0 {
+		return nil, errors.New("minimum quantity must be greater than zero")
+	}
+	if input.RestockQuantity == 0 {
+		input.RestockQuantity = input.MinQuantity
+	}
+	if input.RestockQuantity < input.MinQuantity {
+		return nil, errors.New("restock quantity cannot be lower than minimum quantity")
+	}
+
+	product, err := repository.GetInventoryProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.UserID != userID {
+		return nil, ErrItemNotOwned
+	}
+
+	rule := schema.RestockRule{
+		UserID:          userID,
+		ProductID:       product.ID,
+		MinQuantity:     input.MinQuantity,
+		RestockQuantity: input.RestockQuantity,
+	}
+	if err := repository.UpsertRestockRule(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func DeleteRestockRule(productID string, userID uuid.UUID) error {
+	product, err := repository.GetInventoryProductByID(productID)
+	if err != nil {
+		return err
+	}
+	if product.UserID != userID {
+		return ErrItemNotOwned
+	}
+	return repository.DeleteRestockRule(productID)
+}
+
+// GetLowStockItems lists products whose fresh stock is below their minimum quantity
+func GetLowStockItems(userID uuid.UUID) ([]LowStockItem, error) {
+	rules, err := repository.GetRestockRulesByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restock rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return []LowStockItem{}, nil
+	}
+
+	stock, err := repository.GetFreshStockByProduct(userID, UserClock(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+
+	lowStock := []LowStockItem{}
+	for _, rule := range rules {
+		current := stock[rule.ProductID]
+		if current >= rule.MinQuantity {
+			continue
+		}
+		lowStock = append(lowStock, LowStockItem{
+			ProductID:       rule.ProductID,
+			Name:            rule.Product.Name,
+			Type:            rule.Product.Type,
+			AmountType:      rule.Product.AmountType,
+			CurrentAmount:   current,
+			MinQuantity:     rule.MinQuantity,
+			RestockQuantity: rule.RestockQuantity,
+			Shortfall:       rule.RestockQuantity - current,
+		})
+	}
+	return lowStock, nil
+}
+
+// EvaluateRestock syncs the user's Restock cart with the products currently below threshold.
+// It is safe to run repeatedly, lines are added, resized or dropped to match current stock.
+func EvaluateRestock(userID uuid.UUID) error {
+	lowStock, err := GetLowStockItems(userID)
+	if err != nil {
+		return err
+	}
+
+	cart, err := repository.FindOrCreateRestockCart(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get restock cart: %w", err)
+	}
+
+	existing, err := repository.GetCartItemsByProduct(cart.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get restock cart items: %w", err)
+	}
+
+	for _, low := range lowStock {
+		if cartItem, ok := existing[low.ProductID]; ok {
+			delete(existing, low.ProductID)
+			if cartItem.Amount == low.Shortfall {
+				continue
+			}
+			if err := repository.UpdateCartItemAmount(cartItem.ID, low.Shortfall); err != nil {
+				return fmt.Errorf("failed to update restock line: %w", err)
+			}
+			continue
+		}
+
+		productID := low.ProductID
+		cartItem := schema.CartItem{
+			CartID:     cart.ID,
+			ProductID:  &productID,
+			Name:       low.Name,
+			Type:       low.Type,
+			Amount:     low.Shortfall,
+			AmountType: low.AmountType,
+		}
+		if err := repository.CreateCartItem(&cartItem); err != nil {
+			return fmt.Errorf("failed to add restock line: %w", err)
+		}
+	}
+
+	// Whatever is left is back above threshold
+	for _, cartItem := range existing {
+		if err := repository.DeleteCartItem(cartItem.ID); err != nil {
+			return fmt.Errorf("failed to remove restock line: %w", err)
+		}
+	}
+
+	return nil
+}
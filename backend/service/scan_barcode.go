@@ -4,7 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
 )
 
 type ProductService interface {
@@ -12,7 +17,30 @@ type ProductService interface {
 }
 
 type ProductInfo struct {
-	Name string `json:"name"`
+	Barcode         string                   `json:"barcode"`
+	Name            string                   `json:"name"`
+	Brand           string                   `json:"brand"`
+	Quantity        string                   `json:"quantity"`       // package size as printed, e.g. "500 g"
+	PackageAmount   float64                  `json:"package_amount"` // package size in AmountType
+	AmountType      string                   `json:"amount_type"`
+	Categories      []string                 `json:"categories"`
+	ItemType        string                   `json:"item_type"`
+	Nutriments      schema.ProductNutriments `json:"nutriments"`
+	Allergens       []string                 `json:"allergens"`
+	IngredientsText string                   `json:"ingredients_text"`
+	ImageURL        string                   `json:"image_url"`
+	Source          string                   `json:"source"`
+}
+
+// ItemPrefill is a new fridge item filled from a scanned product, shaped like the item create request
+type ItemPrefill struct {
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Amount     float64 `json:"amount"`
+	AmountType string  `json:"amountType"`
+	Desc       string  `json:"desc"`
+	StartDate  string  `json:"startDate"`
+	ExpDate    string  `json:"expDate"`
 }
 
 type productService struct {
@@ -24,7 +52,75 @@ type OpenFoodFactsResponse struct {
 }
 
 type OpenFoodFactsProduct struct {
-	Brands string `json:"brands"`
+	ProductName         string         `json:"product_name"`
+	ProductNameID       string         `json:"product_name_id"`
+	ProductNameEN       string         `json:"product_name_en"`
+	GenericName         string         `json:"generic_name"`
+	Brands              string         `json:"brands"`
+	Quantity            string         `json:"quantity"`
+	ProductQuantity     offNumber      `json:"product_quantity"`
+	ProductQuantityUnit string         `json:"product_quantity_unit"`
+	Categories          string         `json:"categories"`
+	CategoriesTags      []string       `json:"categories_tags"`
+	Nutriments          map[string]any `json:"nutriments"`
+	AllergensTags       []string       `json:"allergens_tags"`
+	IngredientsText     string         `json:"ingredients_text"`
+	IngredientsTextID   string         `json:"ingredients_text_id"`
+	ImageURL            string         `json:"image_url"`
+	ImageFrontURL       string         `json:"image_front_url"`
+}
+
+// offNumber accepts both numbers and numeric strings, Open Food Facts uses either
+type offNumber float64
+
+func (n *offNumber) UnmarshalJSON(data []byte) error {
+	value, ok := parseOFFNumber(strings.Trim(string(data), `"`))
+	if ok {
+		*n = offNumber(value)
+	}
+	return nil
+}
+
+// Item types guessed from Open Food Facts category tags, first match wins
+var offCategoryItemTypes = []struct {
+	keyword  string
+	itemType string
+}{
+	{"vegetable", "Sayur"},
+	{"fruit", "Buah"},
+	// Fish and milk keep for less time than meat and drinks, they go before those
+	{"fish", "Ikan"},
+	{"seafood", "Ikan"},
+	{"meat", "Daging"},
+	{"poultr", "Daging"},
+	{"milks", "Susu"},
+	{"dairies", "Susu"},
+	{"spice", "Rempah"},
+	{"herb", "Rempah"},
+	{"beverage", "Minuman"},
+	{"drink", "Minuman"},
+	{"water", "Minuman"},
+	{"juice", "Minuman"},
+}
+
+var packageSizePattern = regexp.MustCompile(`(?i)(?:(\d+)\s*x\s*)?(\d+(?:[.,]\d+)?)\s*(kg|g|gr|gram|ml|l|liter|litre)\b`)
+
+// stubProductService answers from a fixed set of products instead of calling Open Food Facts
+type stubProductService struct {
+	products map[string]*ProductInfo
+}
+
+// NewStubProductService returns a ProductService that only knows the given products, keyed by barcode
+func NewStubProductService(products map[string]*ProductInfo) ProductService {
+	return &stubProductService{products: products}
+}
+
+func (ps *stubProductService) GetProductByBarcode(barcode string) (*ProductInfo, error) {
+	product, ok := ps.products[barcode]
+	if !ok {
+		return nil, fmt.Errorf("product not found in stub catalog")
+	}
+	return product, nil
 }
 
 func NewProductService() ProductService {
@@ -37,7 +133,7 @@ func NewProductService() ProductService {
 
 func (ps *productService) GetProductByBarcode(barcode string) (*ProductInfo, error) {
 	url := fmt.Sprintf("https://world.openfoodfacts.org/api/v2/product/%s.json", barcode)
-	
+
 	resp, err := ps.httpClient.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call Open Food Facts API: %w", err)
@@ -53,17 +149,173 @@ func (ps *productService) GetProductByBarcode(barcode string) (*ProductInfo, err
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if openFoodFactsResp.Product == nil || openFoodFactsResp.Product.Brands == "" {
+	if openFoodFactsResp.Product == nil {
 		return nil, fmt.Errorf("product not found in Open Food Facts")
 	}
 
-	productInfo := &ProductInfo{
-		Name: openFoodFactsResp.Product.Brands,
-	}
-
+	productInfo := openFoodFactsResp.Product.toProductInfo(barcode)
 	if productInfo.Name == "" {
-		productInfo.Name = "Nama tidak tersedia"
+		return nil, fmt.Errorf("product not found in Open Food Facts")
 	}
 
 	return productInfo, nil
-}
\ No newline at end of file
+}
+
+func (p *OpenFoodFactsProduct) toProductInfo(barcode string) *ProductInfo {
+	info := &ProductInfo{
+		Barcode:         barcode,
+		Name:            firstNonEmpty(p.ProductNameID, p.ProductName, p.ProductNameEN, p.GenericName),
+		Brand:           strings.TrimSpace(strings.Split(p.Brands, ",")[0]),
+		Quantity:        strings.TrimSpace(p.Quantity),
+		Categories:      splitOFFList(p.Categories),
+		IngredientsText: firstNonEmpty(p.IngredientsTextID, p.IngredientsText),
+		ImageURL:        firstNonEmpty(p.ImageFrontURL, p.ImageURL),
+		Allergens:       []string{},
+		Source:          schema.ProductSourceOpenFoodFacts,
+	}
+
+	// Older entries only carry the brand
+	if info.Name == "" {
+		info.Name = info.Brand
+	}
+
+	for _, tag := range p.AllergensTags {
+		info.Allergens = append(info.Allergens, stripOFFLanguage(tag))
+	}
+
+	info.PackageAmount, info.AmountType = p.packageSize()
+	info.ItemType = offItemType(p.CategoriesTags)
+	info.Nutriments = schema.ProductNutriments{
+		EnergyKcal:    offNutriment(p.Nutriments, "energy-kcal_100g"),
+		Proteins:      offNutriment(p.Nutriments, "proteins_100g"),
+		Carbohydrates: offNutriment(p.Nutriments, "carbohydrates_100g"),
+		Sugars:        offNutriment(p.Nutriments, "sugars_100g"),
+		Fat:           offNutriment(p.Nutriments, "fat_100g"),
+		SaturatedFat:  offNutriment(p.Nutriments, "saturated-fat_100g"),
+		Fiber:         offNutriment(p.Nutriments, "fiber_100g"),
+		Salt:          offNutriment(p.Nutriments, "salt_100g"),
+		Sodium:        offNutriment(p.Nutriments, "sodium_100g"),
+	}
+	// Energy is sometimes only given in kJ
+	if info.Nutriments.EnergyKcal == 0 {
+		info.Nutriments.EnergyKcal = offNutriment(p.Nutriments, "energy_100g") / 4.184
+	}
+
+	return info
+}
+
+// packageSize converts the package size to the fridge units, kilogram or liter, or one satuan
+func (p *OpenFoodFactsProduct) packageSize() (float64, string) {
+	if p.ProductQuantity > 0 && p.ProductQuantityUnit != "" {
+		unit, size := canonicalUnit(p.ProductQuantityUnit)
+		if unit == "kilogram" || unit == "liter" {
+			return float64(p.ProductQuantity) * size, unit
+		}
+	}
+	return ParsePackageSize(p.Quantity)
+}
+
+// ParsePackageSize reads a printed package size such as "500 g", "1L" or "6 x 200 ml"
+func ParsePackageSize(quantity string) (float64, string) {
+	match := packageSizePattern.FindStringSubmatch(quantity)
+	if match == nil {
+		return 1, "satuan"
+	}
+
+	amount, ok := parseOFFNumber(match[2])
+	if !ok || amount <= 0 {
+		return 1, "satuan"
+	}
+	if match[1] != "" {
+		count, _ := strconv.Atoi(match[1])
+		amount *= float64(count)
+	}
+
+	unit, size := canonicalUnit(match[3])
+	return amount * size, unit
+}
+
+// ItemPrefill turns the product into a fridge item bought on startDate
+func (p *ProductInfo) ItemPrefill(startDate time.Time) ItemPrefill {
+	itemType := p.ItemType
+	if itemType == "" {
+		itemType = "Lainnya"
+	}
+
+	amount, amountType := p.PackageAmount, p.AmountType
+	if amount <= 0 || amountType == "" {
+		amount, amountType = 1, "satuan"
+	}
+
+	var desc []string
+	if p.Brand != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(p.Brand)) {
+		desc = append(desc, p.Brand)
+	}
+	if p.Quantity != "" {
+		desc = append(desc, p.Quantity)
+	}
+
+	return ItemPrefill{
+		Name:       p.Name,
+		Type:       itemType,
+		Amount:     amount,
+		AmountType: amountType,
+		Desc:       strings.Join(desc, " "),
+		StartDate:  startDate.Format("2006-01-02"),
+		ExpDate:    EstimateExpDate(p.Name, itemType, startDate).Format("2006-01-02"),
+	}
+}
+
+func offItemType(categoryTags []string) string {
+	for _, entry := range offCategoryItemTypes {
+		for _, tag := range categoryTags {
+			if strings.Contains(tag, entry.keyword) {
+				return entry.itemType
+			}
+		}
+	}
+	return "Lainnya"
+}
+
+func offNutriment(nutriments map[string]any, key string) float64 {
+	switch value := nutriments[key].(type) {
+	case float64:
+		return value
+	case string:
+		number, _ := parseOFFNumber(value)
+		return number
+	}
+	return 0
+}
+
+func parseOFFNumber(value string) (float64, bool) {
+	number, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", "."), 64)
+	return number, err == nil
+}
+
+// stripOFFLanguage turns a tag like "en:milk" into "milk"
+func stripOFFLanguage(tag string) string {
+	if i := strings.Index(tag, ":"); i >= 0 {
+		return tag[i+1:]
+	}
+	return tag
+}
+
+func splitOFFList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package service
+
+import "testing"
+
+func TestOFFItemType(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		itemType string
+		days     int
+	}{
+		{"canned tuna", []string{"en:seafood", "en:fishes", "en:canned-fishes"}, "Ikan", 2},
+		{"fish and meat", []string{"en:meats-and-their-products", "en:fishes"}, "Ikan", 2},
+		{"chicken", []string{"en:meats", "en:poultries"}, "Daging", 3},
+		{"uht milk", []string{"en:beverages", "en:dairies", "en:milks", "en:uht-milks"}, "Susu", 7},
+		{"cheese", []string{"en:dairies", "en:cheeses"}, "Susu", 7},
+		{"tea", []string{"en:beverages", "en:tea-based-beverages"}, "Minuman", 30},
+		{"unknown", []string{"en:snacks"}, "Lainnya", 14},
+	}
+
+	for _, tt := range tests {
+		itemType := offItemType(tt.tags)
+		if itemType != tt.itemType {
+			t.Errorf("%s: type = %s, want %s", tt.name, itemType, tt.itemType)
+		}
+		// The type has its own entry in the shelf-life table
+		if days := ShelfLifeDays("", itemType); days != tt.days {
+			t.Errorf("%s: %s keeps %d days, want %d", tt.name, itemType, days, tt.days)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outcome of adding one scanned barcode to the fridge
+const (
+	ScanStatusAdded       = "added"
+	ScanStatusIncremented = "incremented"
+	ScanStatusNotFound    = "not_found"
+	ScanStatusFailed      = "failed"
+)
+
+const maxScanBatchSize = 100
+
+type ScanEntry struct {
+	Barcode  string
+	Quantity int    // number of packages, defaults to 1
+	ExpDate  string // optional printed expiry, yyyy-mm-dd
+}
+
+type ScanResult struct {
+	Barcode  string       `json:"barcode"`
+	Quantity int          `json:"quantity"`
+	Status   string       `json:"status"`
+	Product  *ProductInfo `json:"product,omitempty"`
+	Item     *schema.Item `json:"item,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// ScanToInventory looks each barcode up and stores it as a fridge batch, one package size per
+// scanned package. A batch bought today with the same expiry is topped up instead of duplicated.
+func ScanToInventory(products ProductService, userID uuid.UUID, entries []ScanEntry) ([]ScanResult, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("no barcodes to scan")
+	}
+	if len(entries) > maxScanBatchSize {
+		return nil, fmt.Errorf("at most %d barcodes can be scanned at once", maxScanBatchSize)
+	}
+
+	today := UserToday(userID)
+
+	results := make([]ScanResult, 0, len(entries))
+	for _, entry := range mergeScanEntries(entries) {
+		results = append(results, scanEntryToInventory(products, userID, entry, today))
+	}
+	return results, nil
+}
+
+// mergeScanEntries sums repeated scans of the same barcode and expiry
+func mergeScanEntries(entries []ScanEntry) []ScanEntry {
+	var merged []ScanEntry
+	index := map[string]int{}
+	for _, entry := range entries {
+		entry.Barcode = strings.TrimSpace(entry.Barcode)
+		if entry.Quantity <= 0 {
+			entry.Quantity = 1
+		}
+
+		key := entry.Barcode + "|" + entry.ExpDate
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += entry.Quantity
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, entry)
+	}
+	return merged
+}
+
+func scanEntryToInventory(products ProductService, userID uuid.UUID, entry ScanEntry, today time.Time) ScanResult {
+	result := ScanResult{Barcode: entry.Barcode, Quantity: entry.Quantity}
+
+	info, err := products.GetProductByBarcode(entry.Barcode)
+	if err != nil {
+		result.Status = ScanStatusNotFound
+		result.Error = err.Error()
+		return result
+	}
+	result.Product = info
+
+	prefill := info.ItemPrefill(today)
+	if entry.ExpDate != "" {
+		if _, err := time.Parse("2006-01-02", entry.ExpDate); err != nil {
+			result.Status = ScanStatusFailed
+			result.Error = "invalid expiration date format"
+			return result
+		}
+		prefill.ExpDate = entry.ExpDate
+	}
+	expDate, _ := time.Parse("2006-01-02", prefill.ExpDate)
+
+	product, err := repository.FindOrCreateInventoryProduct(userID, prefill.Name, prefill.Type, prefill.AmountType)
+	if err != nil {
+		result.Status = ScanStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	// Stock counted in pieces keeps counting packages
+	amount, amountType := prefill.Amount*float64(entry.Quantity), prefill.AmountType
+	if product.AmountType != amountType && product.AmountType == "satuan" {
+		amount, amountType = float64(entry.Quantity), product.AmountType
+	}
+
+	batch, err := repository.FindUnopenedBatch(product.ID, today, expDate)
+	if err == nil && batch.AmountType == amountType {
+		if err := repository.IncrementItemAmount(batch.ID, amount); err != nil {
+			result.Status = ScanStatusFailed
+			result.Error = err.Error()
+			return result
+		}
+		batch.Amount += amount
+		result.Status = ScanStatusIncremented
+		result.Item = batch
+		return result
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		result.Status = ScanStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	var desc *string
+	if prefill.Desc != "" {
+		desc = &prefill.Desc
+	}
+	items := []schema.Item{{
+		UserID:     userID,
+		ProductID:  &product.ID,
+		Name:       prefill.Name,
+		Type:       prefill.Type,
+		Amount:     amount,
+		AmountType: amountType,
+		Desc:       desc,
+		StartDate:  today,
+		ExpDate:    expDate,
+	}}
+	if err := repository.CreateItemBatches(items); err != nil {
+		result.Status = ScanStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = ScanStatusAdded
+	result.Item = &items[0]
+	return result
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+func indomieInfo(barcode string) *ProductInfo {
+	return &ProductInfo{
+		Barcode:       barcode,
+		Name:          "Indomie Mi Goreng",
+		Brand:         "Indofood",
+		Quantity:      "85 g",
+		PackageAmount: 85,
+		AmountType:    "gram",
+		ItemType:      "Lainnya",
+		Source:        schema.ProductSourceOpenFoodFacts,
+	}
+}
+
+func TestScanToInventoryRejectsBatchSize(t *testing.T) {
+	products := NewStubProductService(nil)
+
+	if _, err := ScanToInventory(products, uuid.New(), nil); err == nil {
+		t.Error("empty scan: want error")
+	}
+	if _, err := ScanToInventory(products, uuid.New(), make([]ScanEntry, maxScanBatchSize+1)); err == nil {
+		t.Error("oversized scan: want error")
+	}
+}
+
+func TestMergeScanEntries(t *testing.T) {
+	merged := mergeScanEntries([]ScanEntry{
+		{Barcode: " 8991002101234 "},
+		{Barcode: "8991002101234", Quantity: 2},
+		{Barcode: "8991002101234", ExpDate: "2026-12-01"},
+		{Barcode: "8992761111113", Quantity: -1},
+	})
+
+	want := []ScanEntry{
+		{Barcode: "8991002101234", Quantity: 3},
+		{Barcode: "8991002101234", Quantity: 1, ExpDate: "2026-12-01"},
+		{Barcode: "8992761111113", Quantity: 1},
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("got %+v, want %+v", merged, want)
+	}
+	for i := range want {
+		if merged[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, merged[i], want[i])
+		}
+	}
+}
+
+func TestItemPrefillFromStub(t *testing.T) {
+	products := NewStubProductService(map[string]*ProductInfo{"8991002101234": indomieInfo("8991002101234")})
+
+	info, err := products.GetProductByBarcode("8991002101234")
+	if err != nil {
+		t.Fatalf("stub lookup: %v", err)
+	}
+	today := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+	prefill := info.ItemPrefill(today)
+
+	if prefill.Name != "Indomie Mi Goreng" || prefill.Amount != 85 || prefill.AmountType != "gram" {
+		t.Errorf("prefill = %+v", prefill)
+	}
+	if prefill.Desc != "Indofood 85 g" {
+		t.Errorf("desc = %q, want brand and size", prefill.Desc)
+	}
+	if prefill.StartDate != "2026-03-12" || prefill.ExpDate <= prefill.StartDate {
+		t.Errorf("dates = %s to %s", prefill.StartDate, prefill.ExpDate)
+	}
+
+	if _, err := products.GetProductByBarcode("0000000000000"); err == nil {
+		t.Error("unknown barcode: want error")
+	}
+}
+
+func TestScanToInventory(t *testing.T) {
+	useTestDatabase(t)
+	userID := createTestUser(t, "")
+	barcode := testBarcode()
+	products := NewStubProductService(map[string]*ProductInfo{barcode: indomieInfo(barcode)})
+
+	results, err := ScanToInventory(products, userID, []ScanEntry{
+		{Barcode: barcode, Quantity: 2},
+		{Barcode: barcode},
+		{Barcode: "0000000000000"},
+	})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want the repeated barcode merged: %+v", len(results), results)
+	}
+	if results[0].Status != ScanStatusAdded || results[0].Item.Amount != 255 {
+		t.Errorf("first scan = %s with %+v, want added with 3 x 85 g", results[0].Status, results[0].Item)
+	}
+	if results[1].Status != ScanStatusNotFound {
+		t.Errorf("unknown barcode = %s, want %s", results[1].Status, ScanStatusNotFound)
+	}
+
+	// The same purchase scanned again tops up today's batch
+	results, err = ScanToInventory(products, userID, []ScanEntry{{Barcode: barcode}})
+	if err != nil {
+		t.Fatalf("second scan: %v", err)
+	}
+	if results[0].Status != ScanStatusIncremented {
+		t.Fatalf("second scan = %s, want %s", results[0].Status, ScanStatusIncremented)
+	}
+	batch, err := repository.GetItemByID(results[0].Item.ID.String())
+	if err != nil {
+		t.Fatalf("get batch: %v", err)
+	}
+	if batch.Amount != 340 {
+		t.Errorf("batch amount = %v, want 340", batch.Amount)
+	}
+}
+
+func TestCatalogServiceCachesUpstream(t *testing.T) {
+	useTestDatabase(t)
+	barcode := testBarcode()
+
+	catalog := NewCatalogService(NewStubProductService(map[string]*ProductInfo{barcode: indomieInfo(barcode)}))
+	if _, err := catalog.GetProductByBarcode(barcode); err != nil {
+		t.Fatalf("upstream lookup: %v", err)
+	}
+
+	// Upstream no longer knows it, the cached copy answers
+	offline := NewCatalogService(NewStubProductService(nil))
+	info, err := offline.GetProductByBarcode(barcode)
+	if err != nil {
+		t.Fatalf("cached lookup: %v", err)
+	}
+	if info.Name != "Indomie Mi Goreng" {
+		t.Errorf("cached name = %q", info.Name)
+	}
+
+	if _, err := offline.GetProductByBarcode(testBarcode()); err != ErrProductNotFound {
+		t.Errorf("unknown barcode error = %v, want ErrProductNotFound", err)
+	}
+}
+
+func TestSubmitProductOnlyFillsMissingFields(t *testing.T) {
+	useTestDatabase(t)
+	barcode := testBarcode()
+	info := indomieInfo(barcode)
+	info.Brand = ""
+
+	catalog := NewCatalogService(NewStubProductService(map[string]*ProductInfo{barcode: info}))
+	if _, err := catalog.GetProductByBarcode(barcode); err != nil {
+		t.Fatalf("upstream lookup: %v", err)
+	}
+	cached, err := repository.GetProductByBarcode(barcode)
+	if err != nil {
+		t.Fatalf("get cached: %v", err)
+	}
+
+	product, err := catalog.SubmitProduct(barcode, uuid.New(), schema.ProductSubmission{
+		Name:     "Produk Palsu",
+		Brand:    "Indofood",
+		Quantity: "1 kg",
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	if product.ID != cached.ID {
+		t.Errorf("submitted product ID = %s, want the stored %s", product.ID, cached.ID)
+	}
+	if product.Name != "Indomie Mi Goreng" || product.Quantity != "85 g" || product.PackageAmount != 85 {
+		t.Errorf("existing fields changed: %+v", product)
+	}
+	if product.Brand != "Indofood" {
+		t.Errorf("brand = %q, want the empty field filled", product.Brand)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"strings"
+	"time"
+)
+
+// Days an unopened item stays good in the fridge, keyed by lowercase item type
+var shelfLifeDays = map[string]int{
+	"sayur":   5,
+	"sayuran": 5,
+	"buah":    7,
+	"daging":  3,
+	"ikan":    2,
+	"susu":    7,
+	"minuman": 30,
+	"saus":    180,
+	"rempah":  14,
+	"bumbu":   180,
+	"makanan": 3,
+	"food":    3,
+	"drink":   30,
+	"lainnya": 14,
+}
+
+// Name keywords are more specific than the type, checked first
+var nameShelfLifeDays = []struct {
+	keyword string
+	days    int
+}{
+	{"telur", 21},
+	{"susu", 7},
+	{"yogurt", 14},
+	{"keju", 30},
+	{"roti", 5},
+	{"tahu", 3},
+	{"tempe", 3},
+	{"ayam", 2},
+	{"ikan", 2},
+	{"udang", 2},
+	{"daging", 3},
+	{"sosis", 14},
+	{"nugget", 60},
+	{"kecap", 365},
+	{"saus", 180},
+	{"sambal", 90},
+	{"mentega", 60},
+}
+
+const defaultShelfLifeDays = 7
+
+func ShelfLifeDays(name, itemType string) int {
+	lowerName := strings.ToLower(name)
+	for _, entry := range nameShelfLifeDays {
+		if strings.Contains(lowerName, entry.keyword) {
+			return entry.days
+		}
+	}
+	if days, ok := shelfLifeDays[strings.ToLower(strings.TrimSpace(itemType))]; ok {
+		return days
+	}
+	return defaultShelfLifeDays
+}
+
+// EstimateExpDate guesses the printed expiry of an item bought on startDate
+func EstimateExpDate(name, itemType string, startDate time.Time) time.Time {
+	return startDate.AddDate(0, 0, ShelfLifeDays(name, itemType))
+}
+
+// Days an item stays good after its package is opened, keyed by lowercase item type
+var afterOpeningShelfLifeDays = map[string]int{
+	"sayur":   3,
+	"sayuran": 3,
+	"buah":    3,
+	"daging":  2,
+	"ikan":    2,
+	"susu":    5,
+	"minuman": 5,
+	"saus":    30,
+	"rempah":  30,
+	"bumbu":   30,
+	"makanan": 3,
+	"food":    3,
+	"drink":   5,
+	"lainnya": 7,
+}
+
+const defaultAfterOpeningShelfLifeDays = 7
+
+func AfterOpeningShelfLifeDays(itemType string) int {
+	if days, ok := afterOpeningShelfLifeDays[strings.ToLower(strings.TrimSpace(itemType))]; ok {
+		return days
+	}
+	return defaultAfterOpeningShelfLifeDays
+}
+
+// EffectiveExpDate returns the printed expiry, shortened to the after-opening shelf life once opened
+func EffectiveExpDate(expDate time.Time, itemType string, openedAt *time.Time) time.Time {
+	if openedAt == nil {
+		return expDate
+	}
+
+	openedExp := openedAt.AddDate(0, 0, AfterOpeningShelfLifeDays(itemType))
+	if openedExp.Before(expDate) {
+		return openedExp
+	}
+	return expDate
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+var validSpendingPeriods = map[string]bool{"day": true, "week": true, "month": true}
+
+// GetSpendingReport builds spending totals for [startDate, endDate] and compares them with the
+// daily food budget from onboarding
+func GetSpendingReport(userID uuid.UUID, startDate, endDate time.Time, period string) (*schema.SpendingReport, error) {
+	if period == "" {
+		period = "day"
+	}
+	if !validSpendingPeriods[period] {
+		return nil, errors.New("period must be one of day, week, month")
+	}
+	if endDate.Before(startDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
+	// End date is inclusive for callers, exclusive for queries
+	end := endDate.AddDate(0, 0, 1)
+
+	pref, err := repository.GetUserPreference(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	buckets, err := repository.GetSpendingByPeriod(userID, startDate, end, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending by period: %w", err)
+	}
+	byStore, err := repository.GetSpendingByStore(userID, startDate, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending by store: %w", err)
+	}
+	byCategory, err := repository.GetSpendingByCategory(userID, startDate, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending by category: %w", err)
+	}
+
+	report := &schema.SpendingReport{
+		Period:      period,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		DailyBudget: pref.DailyFoodCost,
+		Buckets:     buckets,
+		ByStore:     byStore,
+		ByCategory:  byCategory,
+	}
+	if report.Buckets == nil {
+		report.Buckets = []schema.SpendingBucket{}
+	}
+
+	for i := range report.Buckets {
+		bucket := &report.Buckets[i]
+		report.Total += bucket.Total
+
+		bucketEnd := nextPeriodStart(bucket.PeriodStart, period)
+		bucket.Budget = pref.DailyFoodCost * overlapDays(bucket.PeriodStart, bucketEnd, startDate, end)
+		bucket.OverBudget = bucket.Budget > 0 && bucket.Total > bucket.Budget
+	}
+
+	report.Budget = pref.DailyFoodCost * overlapDays(startDate, end, startDate, end)
+	report.BudgetRemaining = report.Budget - report.Total
+	report.OverBudget = report.Budget > 0 && report.Total > report.Budget
+
+	return report, nil
+}
+
+// GetBudgetStatus reports spending against budget for the current day, week and month
+func GetBudgetStatus(userID uuid.UUID, now time.Time) ([]schema.BudgetStatus, error) {
+	pref, err := repository.GetUserPreference(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	var statuses []schema.BudgetStatus
+	for _, period := range []string{"day", "week", "month"} {
+		start := periodStart(now, period)
+		end := nextPeriodStart(start, period)
+
+		spent, err := repository.GetTotalSpending(userID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get spending: %w", err)
+		}
+
+		budget := pref.DailyFoodCost * overlapDays(start, end, start, end)
+		statuses = append(statuses, schema.BudgetStatus{
+			Period:     period,
+			StartDate:  start,
+			Spent:      spent,
+			Budget:     budget,
+			Remaining:  budget - spent,
+			OverBudget: budget > 0 && spent > budget,
+		})
+	}
+	return statuses, nil
+}
+
+// RemainingMonthlyBudget is what is left of this month's food budget, and whether a budget is set at all
+func RemainingMonthlyBudget(userID uuid.UUID, now time.Time) (float64, bool, error) {
+	pref, err := repository.GetUserPreference(userID)
+	if err != nil {
+		return 0, false, err
+	}
+	if pref.DailyFoodCost <= 0 {
+		return 0, false, nil
+	}
+
+	start := periodStart(now, "month")
+	end := nextPeriodStart(start, "month")
+	spent, err := repository.GetTotalSpending(userID, start, end)
+	if err != nil {
+		return 0, false, err
+	}
+	return pref.DailyFoodCost*overlapDays(start, end, start, end) - spent, true, nil
+}
+
+// EstimateCartCost prices each unpurchased line with the latest and the cheapest store price seen
+// for it and flags the cart when it does not fit in this month's remaining budget
+func EstimateCartCost(cartID uuid.UUID, userID uuid.UUID) (*schema.CartBudgetEstimate, error) {
+	cart, err := repository.GetCartDetail(cartID)
+	if err != nil {
+		return nil, err
+	}
+	if cart.UserID != userID {
+		return nil, ErrCartNotOwned
+	}
+
+	cartItems, err := repository.GetCartItems(cartID)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := getLinePrices(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	estimate := &schema.CartBudgetEstimate{
+		CartID: cartID.String(),
+		Lines:  []schema.CartLineEstimate{},
+	}
+	for _, cartItem := range cartItems {
+		if cartItem.IsPurchased {
+			continue
+		}
+
+		line, priced := estimateCartLine(cartItem, prices)
+		if cartItem.PricePaid != nil {
+			line.EstimatedCost = *cartItem.PricePaid
+			line.CheapestCost = *cartItem.PricePaid
+			priced = true
+		}
+		if priced {
+			estimate.PricedLines++
+		} else {
+			estimate.UnpricedLines++
+		}
+
+		estimate.EstimatedTotal += line.EstimatedCost
+		estimate.CheapestTotal += line.CheapestCost
+		estimate.Lines = append(estimate.Lines, line)
+	}
+	estimate.PotentialSaving = estimate.EstimatedTotal - estimate.CheapestTotal
+
+	remaining, hasBudget, err := RemainingMonthlyBudget(userID, UserToday(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	if hasBudget {
+		estimate.BudgetRemaining = remaining
+		estimate.OverBudget = estimate.EstimatedTotal > remaining
+	}
+
+	return estimate, nil
+}
+
+func periodStart(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case "week":
+		// Weeks start on Monday, like date_trunc('week')
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+func nextPeriodStart(start time.Time, period string) time.Time {
+	switch period {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// overlapDays counts the whole days [aStart, aEnd) shares with [bStart, bEnd)
+func overlapDays(aStart, aEnd, bStart, bEnd time.Time) float64 {
+	start := aStart
+	if bStart.After(start) {
+		start = bStart
+	}
+	end := aEnd
+	if bEnd.Before(end) {
+		end = bEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return float64(int(end.Sub(start).Hours()/24 + 0.5))
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/config"
+)
+
+var ErrInvalidFileSignature = errors.New("file link is invalid or expired")
+
+// ObjectStorage keeps uploaded files under a key and hands out time-limited links to them
+type ObjectStorage interface {
+	Put(key string, data []byte, contentType string) error
+	Delete(key string) error
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// NewObjectStorage picks the backend named by STORAGE_DRIVER
+func NewObjectStorage(cfg config.StorageConfig) (ObjectStorage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocalStorage(cfg.LocalDir, cfg.PublicURL, cfg.SigningKey)
+	case "s3":
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// LocalStorage keeps files on disk and serves them through /files with HMAC-signed links
+type LocalStorage struct {
+	dir        string
+	publicURL  string
+	signingKey []byte
+}
+
+func NewLocalStorage(dir, publicURL, signingKey string) (*LocalStorage, error) {
+	if signingKey == "" {
+		return nil, errors.New("storage signing key is not set")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{
+		dir:        dir,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		signingKey: []byte(signingKey),
+	}, nil
+}
+
+func (s *LocalStorage) Put(key string, data []byte, contentType string) error {
+	path, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.publicURL + "/files/" + key + "?" + query.Encode(), nil
+}
+
+// VerifySignedURL checks the expires and signature parameters of a link made by SignedURL
+func (s *LocalStorage) VerifySignedURL(key, expires, signature string) error {
+	if !isCleanStorageKey(key) {
+		return ErrInvalidFileSignature
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return ErrInvalidFileSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidFileSignature
+	}
+	return nil
+}
+
+// Path is where a key lives on disk, keys may not leave the storage directory
+func (s *LocalStorage) Path(key string) (string, error) {
+	if !isCleanStorageKey(key) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// isCleanStorageKey accepts relative slash-separated keys without empty, "." or ".." segments
+func isCleanStorageKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
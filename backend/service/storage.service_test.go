@@ -0,0 +1,93 @@
+package service
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	storage, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/", "signing-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	storage := newTestLocalStorage(t)
+	key := "images/user-1/foto.jpg"
+
+	link, err := storage.SignedURL(key, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Path != "/files/"+key {
+		t.Fatalf("link path = %s", parsed.Path)
+	}
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+	if err := storage.VerifySignedURL(key, expires, signature); err != nil {
+		t.Fatalf("own link rejected: %v", err)
+	}
+
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	later := strconv.FormatInt(time.Now().Add(2*time.Hour).Unix(), 10)
+	tests := []struct {
+		name      string
+		key       string
+		expires   string
+		signature string
+	}{
+		{"other key", "images/user-2/foto.jpg", expires, signature},
+		{"extended expiry", key, later, signature},
+		{"tampered signature", key, expires, strings.Repeat("0", len(signature))},
+		{"missing signature", key, expires, ""},
+		{"expired", key, past, storage.sign(key, past)},
+		{"bad expiry", key, "tomorrow", signature},
+		{"parent directory", "../" + key, expires, storage.sign("../"+key, expires)},
+		{"nested parent directory", "images/../../secret", expires, storage.sign("images/../../secret", expires)},
+		{"absolute", "/etc/passwd", expires, storage.sign("/etc/passwd", expires)},
+		{"empty", "", expires, storage.sign("", expires)},
+	}
+	for _, tt := range tests {
+		if err := storage.VerifySignedURL(tt.key, tt.expires, tt.signature); err != ErrInvalidFileSignature {
+			t.Errorf("%s: err = %v, want ErrInvalidFileSignature", tt.name, err)
+		}
+	}
+}
+
+func TestLocalStoragePath(t *testing.T) {
+	storage := newTestLocalStorage(t)
+
+	if err := storage.Put("images/a.jpg", []byte("jpeg bytes"), "image/jpeg"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	path, err := storage.Path("images/a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "jpeg bytes" {
+		t.Errorf("read back %q, %v", data, err)
+	}
+
+	for _, key := range []string{"", "../a.jpg", "images/../../a.jpg", "/a.jpg", "images//a.jpg", `images\..\a.jpg`} {
+		if _, err := storage.Path(key); err == nil {
+			t.Errorf("Path(%q): want error", key)
+		}
+	}
+
+	if err := storage.Delete("images/a.jpg"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still on disk: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/repository"
+	"github.com/google/uuid"
+)
+
+// DefaultTimezone is used until the user picks one, WIB covers most users
+const DefaultTimezone = "Asia/Jakarta"
+
+// LoadTimezone checks an IANA zone name such as Asia/Jakarta (WIB), Asia/Makassar (WITA) or Asia/Jayapura (WIT)
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// UserLocation returns the time zone from the user's profile, or DefaultTimezone
+func UserLocation(userID uuid.UUID) *time.Location {
+	pref, err := repository.GetUserPreference(userID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get timezone for user %s: %v\n", userID, err)
+		pref = nil
+	}
+
+	name := DefaultTimezone
+	if pref != nil && pref.Timezone != "" {
+		name = pref.Timezone
+	}
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		loc, _ = LoadTimezone(DefaultTimezone)
+	}
+	return loc
+}
+
+// WallClock is the local date and time at t in loc, stored as UTC. Calendar dates such as ExpDate are
+// kept as UTC midnight, so this is what they compare against.
+func WallClock(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+}
+
+// LocalDate is the calendar date at t in loc, as UTC midnight
+func LocalDate(t time.Time, loc *time.Location) time.Time {
+	return WallClock(t, loc).Truncate(24 * time.Hour)
+}
+
+// LocalDayBounds returns the instants the local day of date starts and ends in loc
+func LocalDayBounds(date time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// UserClock is the user's current wall-clock time, see WallClock
+func UserClock(userID uuid.UUID) time.Time {
+	return WallClock(time.Now(), UserLocation(userID))
+}
+
+// UserToday is the user's current calendar date, see LocalDate
+func UserToday(userID uuid.UUID) time.Time {
+	return LocalDate(time.Now(), UserLocation(userID))
+}
+
+// TodayBounds returns the instants today starts and ends in loc
+func TodayBounds(loc *time.Location) (time.Time, time.Time) {
+	return LocalDayBounds(time.Now().In(loc), loc)
+}
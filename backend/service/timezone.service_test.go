@@ -0,0 +1,181 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/database"
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+	"github.com/google/uuid"
+)
+
+func mustLoadTimezone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestLocalTimeAroundMidnight(t *testing.T) {
+	// 23:30 WIB is already the next day in WITA and WIT
+	lateWIB := time.Date(2026, 3, 12, 16, 30, 0, 0, time.UTC)
+	// 23:59 WIT is still the same day everywhere
+	lateWIT := time.Date(2026, 3, 12, 14, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		timezone  string
+		instant   time.Time
+		wallClock string
+		date      string
+		dayStart  time.Time // the local midnight starting that date, as an instant
+	}{
+		{"Asia/Jakarta", lateWIB, "2026-03-12 23:30", "2026-03-12", time.Date(2026, 3, 11, 17, 0, 0, 0, time.UTC)},
+		{"Asia/Makassar", lateWIB, "2026-03-13 00:30", "2026-03-13", time.Date(2026, 3, 12, 16, 0, 0, 0, time.UTC)},
+		{"Asia/Jayapura", lateWIB, "2026-03-13 01:30", "2026-03-13", time.Date(2026, 3, 12, 15, 0, 0, 0, time.UTC)},
+		{"Asia/Jakarta", lateWIT, "2026-03-12 21:59", "2026-03-12", time.Date(2026, 3, 11, 17, 0, 0, 0, time.UTC)},
+		{"Asia/Makassar", lateWIT, "2026-03-12 22:59", "2026-03-12", time.Date(2026, 3, 11, 16, 0, 0, 0, time.UTC)},
+		{"Asia/Jayapura", lateWIT, "2026-03-12 23:59", "2026-03-12", time.Date(2026, 3, 11, 15, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone+" "+tt.wallClock, func(t *testing.T) {
+			loc := mustLoadTimezone(t, tt.timezone)
+
+			wallClock := WallClock(tt.instant, loc)
+			if wallClock.Location() != time.UTC || wallClock.Format("2006-01-02 15:04") != tt.wallClock {
+				t.Errorf("WallClock = %v, want %s UTC", wallClock, tt.wallClock)
+			}
+
+			date := LocalDate(tt.instant, loc)
+			if date.Format("2006-01-02") != tt.date || !date.Equal(date.Truncate(24*time.Hour)) {
+				t.Errorf("LocalDate = %v, want %s at UTC midnight", date, tt.date)
+			}
+
+			start, end := LocalDayBounds(date, loc)
+			if !start.Equal(tt.dayStart) || end.Sub(start) != 24*time.Hour {
+				t.Errorf("LocalDayBounds = %v to %v, want 24 hours from %v", start, end, tt.dayStart)
+			}
+			if tt.instant.Before(start) || !tt.instant.Before(end) {
+				t.Errorf("%v is outside its own day %v to %v", tt.instant, start, end)
+			}
+		})
+	}
+}
+
+func TestTodayBounds(t *testing.T) {
+	for _, name := range []string{"Asia/Jakarta", "Asia/Makassar", "Asia/Jayapura"} {
+		loc := mustLoadTimezone(t, name)
+		now := time.Now()
+		start, end := TodayBounds(loc)
+
+		if now.Before(start) || !now.Before(end) {
+			t.Errorf("%s: now %v is outside today %v to %v", name, now, start, end)
+		}
+		if end.Sub(start) != 24*time.Hour {
+			t.Errorf("%s: today lasts %v", name, end.Sub(start))
+		}
+		if local := start.In(loc); local.Hour() != 0 || local.Minute() != 0 {
+			t.Errorf("%s: today starts at %v local time", name, local)
+		}
+	}
+}
+
+func TestExpiryUsesWallClock(t *testing.T) {
+	// Expiry dates are stored as the local calendar day at UTC midnight
+	expDate := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+	lateWIB := time.Date(2026, 3, 12, 16, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		timezone string
+		expired  bool
+	}{
+		{"Asia/Jakarta", false}, // 23:30, half an hour left
+		{"Asia/Makassar", true}, // 00:30 the next day
+		{"Asia/Jayapura", true}, // 01:30 the next day
+	}
+	for _, tt := range tests {
+		now := WallClock(lateWIB, mustLoadTimezone(t, tt.timezone))
+		if expired := expDate.Before(now); expired != tt.expired {
+			t.Errorf("%s: expired = %v, want %v", tt.timezone, expired, tt.expired)
+		}
+	}
+}
+
+func TestExpiredItemsFollowUserClock(t *testing.T) {
+	useTestDatabase(t)
+	wib := createTestUser(t, "Asia/Jakarta")
+	wit := createTestUser(t, "Asia/Jayapura")
+
+	// Both items expire at this moment of the WITA clock, an hour ahead of WIB and an hour behind WIT
+	expDate := WallClock(time.Now(), mustLoadTimezone(t, "Asia/Makassar"))
+
+	tests := []struct {
+		userID  uuid.UUID
+		expired int
+	}{
+		{wib, 0},
+		{wit, 1},
+	}
+	for _, tt := range tests {
+		item := schema.Item{UserID: tt.userID, Name: "Susu", Amount: 1, AmountType: "liter", StartDate: expDate, ExpDate: expDate}
+		if err := database.DB.Create(&item).Error; err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+
+		items, err := SearchAllItems(tt.userID, schema.ItemSearch{State: schema.ItemStateExpired})
+		if err != nil {
+			t.Fatalf("expired items: %v", err)
+		}
+		if len(items) != tt.expired {
+			t.Errorf("user in %s sees %d expired items, want %d", UserLocation(tt.userID), len(items), tt.expired)
+		}
+	}
+}
+
+func TestNutritionProgressGroupsByLocalDay(t *testing.T) {
+	useTestDatabase(t)
+
+	// Eaten at 23:30 WIB, which is 01:30 the next day in WIT
+	eatenAt := time.Date(2026, 3, 12, 16, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		timezone string
+		day      string
+	}{
+		{"Asia/Jakarta", "2026-03-12"},
+		{"Asia/Makassar", "2026-03-13"},
+		{"Asia/Jayapura", "2026-03-13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			userID := createTestUser(t, tt.timezone)
+			journal := schema.FoodJournal{
+				UserID:       userID,
+				MealName:     "Nasi goreng",
+				MealType:     "dinner",
+				AINutrition:  schema.AINutrition{Calories: 600},
+				FoodAnalysis: "{}",
+				CreatedAt:    eatenAt,
+			}
+			if err := database.DB.Create(&journal).Error; err != nil {
+				t.Fatalf("create journal: %v", err)
+			}
+
+			from := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+			to := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+			progress, err := GetNutritionProgress(userID, from, to, "day")
+			if err != nil {
+				t.Fatalf("progress: %v", err)
+			}
+			if len(progress.Buckets) != 1 {
+				t.Fatalf("got %d buckets, want 1: %+v", len(progress.Buckets), progress.Buckets)
+			}
+			bucket := progress.Buckets[0]
+			if bucket.PeriodStart.Format("2006-01-02") != tt.day || bucket.Meals != 1 || bucket.Calories != 600 {
+				t.Errorf("bucket = %s with %d meals and %v kcal, want %s with the meal", bucket.PeriodStart.Format("2006-01-02"), bucket.Meals, bucket.Calories, tt.day)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	_ "golang.org/x/image/webp" // registers the WebP decoder with image.Decode
+)
+
+const (
+	ThumbnailSize = 320 // px, longest side
+	jpegQuality   = 85
+
+	MinImageDimension = 64         // px, smaller photos are useless to the AI
+	maxImagePixels    = 50_000_000 // guards against decompression bombs
+)
+
+// ImageError is why an upload was rejected, Code is stable for clients to match on
+type ImageError struct {
+	Code    string
+	Message string
+}
+
+func (e *ImageError) Error() string {
+	return e.Message
+}
+
+var (
+	ErrImageTooLarge    = &ImageError{Code: "image_too_large", Message: "image file or resolution is too large"}
+	ErrImageUnsupported = &ImageError{Code: "image_unsupported_type", Message: "only JPEG, PNG, WebP and HEIC images are supported"}
+	ErrImageCorrupt     = &ImageError{Code: "image_corrupt", Message: "image could not be decoded"}
+	ErrImageTooSmall    = &ImageError{Code: "image_too_small", Message: "image is too small to analyze"}
+)
+
+// DetectImageType identifies an image by its magic bytes, the filename and declared type are not trusted
+func DetectImageType(data []byte) (string, error) {
+	switch {
+	case len(data) >= 3 && bytes.Equal(data[:3], []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg", nil
+	case len(data) >= 8 && bytes.Equal(data[:8], []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png", nil
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "image/webp", nil
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		// ISO base media file, HEIC when the major brand is one of the HEIF image brands
+		switch string(data[8:12]) {
+		case "heic", "heix", "heim", "heis", "hevc", "hevx":
+			return "image/heic", nil
+		case "mif1", "msf1":
+			return "image/heif", nil
+		}
+	}
+	return "", ErrImageUnsupported
+}
+
+// IsHEIF reports whether the content type is HEIC or HEIF. There is no HEVC decoder to convert
+// them, so they are validated and passed on as uploaded, which Gemini accepts.
+func IsHEIF(contentType string) bool {
+	return contentType == "image/heic" || contentType == "image/heif"
+}
+
+// PrepareImage validates an upload and turns it into what is sent to the AI: upright, without
+// metadata and at most maxDimension on its longest side. WebP is re-encoded like the others,
+// HEIC and HEIF are only checked and keep their original bytes and size.
+func PrepareImage(data []byte, maxDimension int) (*ProcessedImage, error) {
+	contentType, err := DetectImageType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var width, height int
+	if IsHEIF(contentType) {
+		width, height = HEIFDimensions(data)
+		if width == 0 || height == 0 {
+			return nil, ErrImageCorrupt
+		}
+	} else {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrImageCorrupt
+		}
+		width, height = config.Width, config.Height
+	}
+	if width*height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	if width < MinImageDimension && height < MinImageDimension {
+		return nil, ErrImageTooSmall
+	}
+
+	if IsHEIF(contentType) {
+		return &ProcessedImage{Data: data, ContentType: contentType, Width: width, Height: height}, nil
+	}
+
+	processed, err := NormalizeImage(data, maxDimension)
+	if err != nil {
+		return nil, ErrImageCorrupt
+	}
+	return processed, nil
+}
+
+// ProcessedImage is an upload re-encoded without its metadata
+type ProcessedImage struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// NormalizeImage decodes a JPEG, PNG or WebP, applies its EXIF orientation, shrinks it so the longest
+// side is at most maxDimension (0 keeps the size) and encodes it again. Re-encoding drops EXIF, so GPS
+// position and camera details never reach storage.
+func NormalizeImage(data []byte, maxDimension int) (*ProcessedImage, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	rgba := toRGBA(img)
+	if format == "jpeg" {
+		rgba = OrientImage(rgba, JPEGOrientation(data))
+	}
+	rgba = ResizeImage(rgba, maxDimension)
+
+	// A WebP with an alpha channel keeps its transparency as PNG
+	_, alpha := img.(*image.NYCbCrA)
+	return encodeImage(rgba, format == "png" || (format == "webp" && alpha))
+}
+
+// Thumbnail makes a small JPEG preview of an already normalized image
+func Thumbnail(data []byte) (*ProcessedImage, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return encodeImage(ResizeImage(toRGBA(img), ThumbnailSize), false)
+}
+
+func encodeImage(img *image.RGBA, keepPNG bool) (*ProcessedImage, error) {
+	var buf bytes.Buffer
+	contentType := "image/jpeg"
+	if keepPNG {
+		// PNG keeps transparency, everything else becomes a JPEG
+		contentType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	} else if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	return &ProcessedImage{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+// HEIFDimensions reads the picture size from the ispe boxes of a HEIC or HEIF file, 0 when it has none.
+// Photos split into grid tiles have a box per tile as well, the largest box is the whole picture.
+func HEIFDimensions(data []byte) (int, int) {
+	var width, height int
+	for pos := 0; ; pos += 4 {
+		i := bytes.Index(data[pos:], []byte("ispe"))
+		if i < 0 {
+			break
+		}
+		pos += i
+		// Box type, then version and flags, then the 32-bit width and height
+		if pos+16 > len(data) {
+			break
+		}
+		w := int(binary.BigEndian.Uint32(data[pos+8:]))
+		h := int(binary.BigEndian.Uint32(data[pos+12:]))
+		if w*h > width*height {
+			width, height = w, h
+		}
+	}
+	return width, height
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// JPEGOrientation reads the EXIF orientation tag (1-8) of a JPEG, 1 when it has none
+func JPEGOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		// Start of scan, the metadata segments are over
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		if marker == 0xE1 {
+			if orientation, err := exifOrientation(data[pos+4 : end]); err == nil {
+				return orientation
+			}
+		}
+		pos = end
+	}
+	return 1
+}
+
+func exifOrientation(segment []byte) (int, error) {
+	if len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+		return 0, errors.New("not an EXIF segment")
+	}
+	tiff := segment[6:]
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, errors.New("invalid TIFF header")
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0, errors.New("IFD out of range")
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 0, errors.New("invalid orientation")
+			}
+			return orientation, nil
+		}
+	}
+	return 0, errors.New("no orientation tag")
+}
+
+// OrientImage turns pixels stored in the given EXIF orientation upright
+func OrientImage(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs a quarter turn clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // needs a quarter turn counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			si := sy*src.Stride + sx*4
+			di := y*dst.Stride + x*4
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// ResizeImage scales the image down by area averaging so its longest side is at most maxDimension
+func ResizeImage(src *image.RGBA, maxDimension int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if maxDimension <= 0 || (w <= maxDimension && h <= maxDimension) {
+		return src
+	}
+
+	dw, dh := maxDimension, h*maxDimension/w
+	if h > w {
+		dw, dh = w*maxDimension/h, maxDimension
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := sy * src.Stride
+				for sx := x0; sx < x1; sx++ {
+					p := src.Pix[row+sx*4 : row+sx*4+4]
+					sum[0] += int(p[0])
+					sum[1] += int(p[1])
+					sum[2] += int(p[2])
+					sum[3] += int(p[3])
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			di := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[di+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
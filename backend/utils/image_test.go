@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func encodedTestImage(t *testing.T, width, height int, asPNG bool) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 120, 255})
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	if asPNG {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// heicWithSizes is the start of a HEIC file with an ispe box for each size, enough to be detected and measured
+func heicWithSizes(sizes ...[2]uint32) []byte {
+	data := []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic")
+	for _, size := range sizes {
+		data = append(data, 0, 0, 0, 20)
+		data = append(data, "ispe\x00\x00\x00\x00"...)
+		data = binary.BigEndian.AppendUint32(data, size[0])
+		data = binary.BigEndian.AppendUint32(data, size[1])
+	}
+	return append(data, make([]byte, 64)...)
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestPrepareImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		maxDimension  int
+		contentType   string
+		width, height int
+	}{
+		{"jpeg", encodedTestImage(t, 200, 100, false), 0, "image/jpeg", 200, 100},
+		{"jpeg shrunk", encodedTestImage(t, 200, 100, false), 100, "image/jpeg", 100, 50},
+		{"png", encodedTestImage(t, 80, 80, true), 0, "image/png", 80, 80},
+		{"webp", readTestdata(t, "video-001.lossy.webp"), 0, "image/jpeg", 150, 103},
+		{"webp shrunk", readTestdata(t, "video-001.lossy.webp"), 75, "image/jpeg", 75, 51},
+		{"webp with alpha", readTestdata(t, "yellow_rose.lossy-with-alpha.webp"), 0, "image/png", 400, 301},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := PrepareImage(tt.data, tt.maxDimension)
+			if err != nil {
+				t.Fatalf("prepare: %v", err)
+			}
+			if processed.ContentType != tt.contentType || processed.Width != tt.width || processed.Height != tt.height {
+				t.Errorf("got %s %dx%d, want %s %dx%d", processed.ContentType, processed.Width, processed.Height, tt.contentType, tt.width, tt.height)
+			}
+
+			// The output is re-encoded, not the upload passed through
+			if contentType, err := DetectImageType(processed.Data); err != nil || contentType != tt.contentType {
+				t.Errorf("output is %q, %v, want %s", contentType, err, tt.contentType)
+			}
+			config, _, err := image.DecodeConfig(bytes.NewReader(processed.Data))
+			if err != nil || config.Width != tt.width || config.Height != tt.height {
+				t.Errorf("output decodes as %dx%d, %v", config.Width, config.Height, err)
+			}
+		})
+	}
+}
+
+func TestPrepareImageRejects(t *testing.T) {
+	webp := readTestdata(t, "video-001.lossy.webp")
+
+	tests := []struct {
+		name string
+		data []byte
+		want *ImageError
+	}{
+		{"heic without size", heicWithSizes(), ErrImageCorrupt},
+		{"tiny heic", heicWithSizes([2]uint32{32, 32}), ErrImageTooSmall},
+		{"huge heic", heicWithSizes([2]uint32{10000, 10000}), ErrImageTooLarge},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00"), ErrImageUnsupported},
+		{"empty", nil, ErrImageUnsupported},
+		{"truncated webp", webp[:40], ErrImageCorrupt},
+		{"truncated jpeg", encodedTestImage(t, 100, 100, false)[:20], ErrImageCorrupt},
+		{"tiny png", encodedTestImage(t, 10, 10, true), ErrImageTooSmall},
+	}
+
+	for _, tt := range tests {
+		if _, err := PrepareImage(tt.data, 0); err != tt.want {
+			t.Errorf("%s: err = %v, want %s", tt.name, err, tt.want.Code)
+		}
+	}
+}
+
+func TestPrepareImageKeepsHEIC(t *testing.T) {
+	// A photo stored as 512px grid tiles, the largest ispe box is the whole picture
+	heic := heicWithSizes([2]uint32{512, 512}, [2]uint32{4032, 3024}, [2]uint32{512, 512})
+
+	processed, err := PrepareImage(heic, 1024)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if processed.ContentType != "image/heic" || processed.Width != 4032 || processed.Height != 3024 {
+		t.Errorf("prepared %s %dx%d, want the 4032x3024 HEIC", processed.ContentType, processed.Width, processed.Height)
+	}
+	if !bytes.Equal(processed.Data, heic) {
+		t.Error("HEIC bytes were changed, want them passed through")
+	}
+}
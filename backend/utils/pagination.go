@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/andi-frame/TeamName_KulkasKu/backend/schema"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("limit must be a positive number")
+)
+
+// ParsePageRequest reads the ?limit= and ?cursor= query values, the limit defaults to
+// schema.DefaultPageLimit and is capped at schema.MaxPageLimit
+func ParsePageRequest(limitStr, cursorStr string) (schema.PageRequest, error) {
+	page := schema.PageRequest{Limit: schema.DefaultPageLimit}
+
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return page, ErrInvalidLimit
+		}
+		page.Limit = min(limit, schema.MaxPageLimit)
+	}
+
+	if cursorStr != "" {
+		cursor, err := DecodeCursor(cursorStr)
+		if err != nil {
+			return page, err
+		}
+		page.Cursor = cursor
+	}
+	return page, nil
+}
+
+// EncodeCursor makes an opaque cursor string for clients to send back
+func EncodeCursor(cursor schema.PageCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(value string) (*schema.PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor schema.PageCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// TrimPage drops the extra row a page query loads to detect a following page, and describes the page
+func TrimPage[T any](rows []T, limit int, cursorOf func(T) schema.PageCursor) ([]T, schema.PageInfo) {
+	info := schema.PageInfo{Limit: limit}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		info.HasMore = true
+		info.NextCursor = EncodeCursor(cursorOf(rows[len(rows)-1]))
+	}
+	return rows, info
+}
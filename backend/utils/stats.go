@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// FisherExactGreater is the one-sided p-value of Fisher's exact test for the 2x2 table
+//
+//	a b
+//	c d
+//
+// against the alternative that the first row has the larger share in the first column
+func FisherExactGreater(a, b, c, d int) float64 {
+	rowTotal := a + b
+	colTotal := a + c
+	total := a + b + c + d
+	if total == 0 {
+		return 1
+	}
+
+	p := 0.0
+	for x := a; x <= min(rowTotal, colTotal); x++ {
+		p += math.Exp(logHypergeometric(x, rowTotal, colTotal, total))
+	}
+	return math.Min(p, 1)
+}
+
+// logHypergeometric is log P(X = x) for x successes in a draw of n from total items of which k are successes
+func logHypergeometric(x, n, k, total int) float64 {
+	return logChoose(k, x) + logChoose(total-k, n-x) - logChoose(total, n)
+}
+
+func logChoose(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// BenjaminiHochberg adjusts p-values for testing many hypotheses at once, the returned q-values
+// keep the expected share of false discoveries among values below a cut-off at that cut-off
+func BenjaminiHochberg(pValues []float64) []float64 {
+	n := len(pValues)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return pValues[order[i]] < pValues[order[j]] })
+
+	qValues := make([]float64, n)
+	running := 1.0
+	for rank := n; rank >= 1; rank-- {
+		i := order[rank-1]
+		running = math.Min(running, pValues[i]*float64(n)/float64(rank))
+		qValues[i] = running
+	}
+	return qValues
+}
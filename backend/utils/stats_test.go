@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFisherExactGreater(t *testing.T) {
+	tests := []struct {
+		a, b, c, d int
+		want       float64
+	}{
+		// 4 of 8 drawn, P(X >= 3) = (C(4,3)C(4,1) + C(4,4)C(4,0)) / C(8,4) = 17/70
+		{3, 1, 1, 3, 0.242857},
+		// Fisher's tea tasting, all four cups right
+		{4, 0, 0, 4, 0.014286},
+		// The opposite direction is not evidence, P(X >= 1) = 1 - 1/70
+		{1, 3, 3, 1, 0.985714},
+		// (C(4,3)C(8,1) + C(4,4)C(8,0)) / C(12,4) = 33/495
+		{3, 1, 1, 7, 0.066667},
+		{0, 5, 0, 5, 1},
+		{0, 0, 0, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := FisherExactGreater(tt.a, tt.b, tt.c, tt.d); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("FisherExactGreater(%d, %d, %d, %d) = %v, want %v", tt.a, tt.b, tt.c, tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestBenjaminiHochberg(t *testing.T) {
+	tests := []struct {
+		name    string
+		pValues []float64
+		want    []float64
+	}{
+		// Ranked 0.005, 0.01, 0.03, 0.04 scale to 0.02, 0.02, 0.04, 0.04
+		{"unsorted", []float64{0.01, 0.04, 0.03, 0.005}, []float64{0.02, 0.04, 0.04, 0.02}},
+		// A q-value never exceeds the one of a larger p-value
+		{"monotone", []float64{0.9, 0.8}, []float64{0.9, 0.9}},
+		{"single", []float64{0.2}, []float64{0.2}},
+		{"empty", nil, []float64{}},
+	}
+	for _, tt := range tests {
+		got := BenjaminiHochberg(tt.pValues)
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: got %d q-values, want %d", tt.name, len(got), len(tt.want))
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: q-values = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
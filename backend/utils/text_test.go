@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigramSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		// The example of the pg_trgm documentation
+		{"word", "two words", 0.363636},
+		{"nasi goreng", "Nasi Goreng", 1},
+		{"gado gado", "gado-gado", 1},
+		{"jus alpukat", "alpukat", 0.666667},
+		{"kopi", "teh", 0},
+		{"", "nasi", 0},
+	}
+	for _, tt := range tests {
+		if got := TrigramSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("TrigramSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -8,7 +8,7 @@ import { Bar, BarChart, CartesianGrid, Legend, ResponsiveContainer, Tooltip, XAx
 import { FoodCard } from "@/components/food-card";
 
 import { Item } from "@/types/item.types";
-import api from "@/utils/axios";
+import api, { getAllPages } from "@/utils/axios";
 
 import { useRecipeStore } from "@/store/useRecipeStore";
 import { useRouter } from "next/navigation";
@@ -67,8 +67,7 @@ export default function Dashboard() {
     const fetchDashboardData = async () => {
       try {
         // Fetch fresh items
-        const itemsResponse = await api.get("/item/fresh");
-        setIngredients(itemsResponse.data.data || []);
+        setIngredients(await getAllPages<Item>("/item/fresh"));
 
         // Fetch food journal dashboard data
         const dashboardResponse = await api.get("/food-journal/dashboard");
@@ -133,11 +132,8 @@ export default function Dashboard() {
   useEffect(() => {
     const checkExpiringItems = async () => {
       try {
-        const response1 = await api.get("/item/fresh");
-        const response2 = await api.get("/item/expired");
-
-        const items = response1.data.data || [];
-        items.push(...(response2.data.data || []));
+        const items = await getAllPages<Item>("/item/fresh");
+        items.push(...(await getAllPages<Item>("/item/expired")));
 
         setTotalItems(items.length);
 
@@ -2,7 +2,7 @@
 
 import React, { useState, useEffect } from "react";
 import { Plus, ShoppingCart, Trash2, X, Package, Info } from "lucide-react";
-import api from "@/utils/axios";
+import api, { getAllPages } from "@/utils/axios";
 import { Cart, CartItem } from "@/types/cart.types";
 
 const Page = () => {
@@ -35,13 +35,7 @@ const Page = () => {
   const fetchCarts = async () => {
     try {
       setLoading(true);
-      const response = await api.get(`${API_BASE}/cart/all`);
-
-      if (response.status == 200) {
-        setCarts(response.data);
-      } else {
-        setError("Failed to fetch carts");
-      }
+      setCarts(await getAllPages<Cart>(`${API_BASE}/cart/all`));
     } catch (err) {
       setError("Error fetching carts");
       console.error(err);
@@ -31,6 +31,7 @@ const Page = () => {
   const [startDate, setStartDate] = useState("");
   const [expDate, setExpDate] = useState("");
   const [desc, setDesc] = useState("");
+  const [imageId, setImageId] = useState<string | null>(null);
   const [showScanner, setShowScanner] = useState(false);
   const [showAIResult, setShowAIResult] = useState(false);
   const [aiResult, setAiResult] = useState<ImagePredictionResult | null>(null);
@@ -67,6 +68,13 @@ const Page = () => {
   const handleBarcodeResult = (result: BarcodeResult) => {
     // Fill form with barcode data directly (no AI analysis needed)
     setName(result.name);
+    if (result.prefill) {
+      setType(result.prefill.type);
+      setAmount(result.prefill.amount.toString());
+      setAmountType(result.prefill.amountType);
+      setExpDate(result.prefill.expDate);
+      setDesc(result.prefill.desc);
+    }
     setShowScanner(false);
   };
 
@@ -82,6 +90,7 @@ const Page = () => {
       if (aiResult.condition_description) {
         setDesc(aiResult.condition_description);
       }
+      setImageId(aiResult.image?.ID ?? null);
       setShowAIResult(false);
       setAiResult(null);
     }
@@ -190,6 +199,7 @@ const Page = () => {
         desc,
         startDate,
         expDate,
+        ...(imageId ? { imageId } : {}),
       });
 
       console.log("Response:", response.data);
@@ -203,6 +213,7 @@ const Page = () => {
       setStartDate(new Date().toISOString().split("T")[0]);
       setExpDate("");
       setDesc("");
+      setImageId(null);
 
       // eslint-disable-next-line @typescript-eslint/no-explicit-any
     } catch (err: any) {
@@ -2,7 +2,7 @@
 
 import { useEffect, useState } from "react";
 import { AlertTriangle, Clock, X, Bell, ChevronDown, ChevronUp } from "lucide-react";
-import api from "@/utils/axios";
+import { getAllPages } from "@/utils/axios";
 import { Item } from "@/types/item.types";
 
 interface ToastNotificationProps {
@@ -196,8 +196,7 @@ export default function ExpiryNotification() {
   useEffect(() => {
     const checkExpiringItems = async () => {
       try {
-        const response = await api.get("/item/fresh");
-        const items = response.data.data || [];
+        const items = await getAllPages<Item>("/item/fresh");
         
         const now = new Date();
         const filtered = items.filter((item: Item) => {
@@ -63,6 +63,7 @@ const FoodJournalForm: React.FC<FoodJournalFormProps> = ({ onFoodJournalCreated
 
   const [isAnalyzing, setIsAnalyzing] = useState(false);
   const [analysisResult, setAnalysisResult] = useState<FoodAnalysisResult | null>(null);
+  const [imageId, setImageId] = useState<string | null>(null);
 
   const [isSubmitting, setIsSubmitting] = useState(false);
 
@@ -155,7 +156,10 @@ const FoodJournalForm: React.FC<FoodJournalFormProps> = ({ onFoodJournalCreated
         const response = await api.post("/food-journal/analyze-image", formData, {
           headers: { "Content-Type": "multipart/form-data" },
         });
-        analysisData = response.data;
+        // The stored photo comes back next to the analysis, keep it out of the saved analysis
+        const { image, ...analysis } = response.data;
+        analysisData = analysis;
+        setImageId(image?.ID ?? null);
       } else {
         const response = await api.post("/food-journal/analyze-text", {
           description: description,
@@ -195,6 +199,7 @@ const FoodJournalForm: React.FC<FoodJournalFormProps> = ({ onFoodJournalCreated
     setEditableMealName("");
     setEditableDescription("");
     setAnalysisResult(null);
+    setImageId(null);
     handleRemoveImage();
     setMealType("breakfast");
     setFeelingBefore("normal");
@@ -216,6 +221,7 @@ const FoodJournalForm: React.FC<FoodJournalFormProps> = ({ onFoodJournalCreated
         raw_input: description,
         processed_input: editableDescription,
         food_analysis: JSON.stringify(analysisResult),
+        ...(imageId ? { image_id: imageId } : {}),
       });
 
       resetForm();
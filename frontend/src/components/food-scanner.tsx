@@ -276,6 +276,9 @@ export function FoodScanner({ onBarcodeResult, onImageResult, onReceiptResult, o
         const result = {
           name: response.data.data.name,
           barcode: response.data.data.barcode,
+          brand: response.data.data.brand,
+          image_url: response.data.data.image_url,
+          prefill: response.data.prefill,
         };
 
         console.log("Barcode scan result:", result);
@@ -2,7 +2,7 @@
 
 import { useEffect, useState, useCallback } from "react";
 import { FoodCard } from "@/components/food-card";
-import api from "@/utils/axios";
+import { getAllPages } from "@/utils/axios";
 import { Item } from "@/types/item.types";
 import Popup from "../popup";
 import FoodCardPopup from "./food-card-popup";
@@ -63,15 +63,12 @@ export default function ExpiredGroup() {
     if (searchValue || startDate || expDate) {
       const getSearchedExpiredItems = async () => {
         try {
-          const response = await api.get("/item/expired/search", {
-            params: { 
-              name: searchValue,
-              start: startDate,
-              exp: expDate,
-              itemType: itemType
-             }
+          const data = await getAllPages<Item>("/item/expired/search", {
+            name: searchValue,
+            start: startDate,
+            exp: expDate,
+            itemType: itemType
           });
-          const data = response.data.data;
           const sortedData = sortItems(data);
           setExpiredItems(sortedData);
           setHasExpiredItems(sortedData.length > 0);
@@ -90,12 +87,9 @@ export default function ExpiredGroup() {
     } else {
       const getAllExpiredItems = async () => {
         try {
-          const response = await api.get("/item/expired", {
-            params: {
-              itemType: itemType
-            }
+          const data = await getAllPages<Item>("/item/expired", {
+            itemType: itemType
           });
-          const data = response.data.data;
           const sortedData = sortItems(data);
           setExpiredItems(sortedData);
           setHasExpiredItems(sortedData.length > 0);
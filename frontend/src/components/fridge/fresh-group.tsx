@@ -2,7 +2,7 @@
 
 import { useEffect, useState, useCallback } from "react";
 import { FoodCard } from "@/components/food-card";
-import api from "@/utils/axios";
+import { getAllPages } from "@/utils/axios";
 import { Item } from "@/types/item.types";
 import Popup from "../popup";
 import FoodCardPopup from "./food-card-popup";
@@ -54,15 +54,12 @@ export default function FreshGroup() {
     if (searchValue || startDate || expDate) {
       const getSearchFreshItem = async () => {
         try {
-          const response = await api.get("/item/fresh/search", {
-            params: { 
-              name: searchValue,
-              start: startDate,
-              exp: expDate,
-              itemType: itemType,
-             }
+          const data = await getAllPages<Item>("/item/fresh/search", {
+            name: searchValue,
+            start: startDate,
+            exp: expDate,
+            itemType: itemType,
           });
-          const data = response.data.data;
           setFreshItems(sortItems(data));
           console.log(data);
         } catch (error) {
@@ -73,12 +70,9 @@ export default function FreshGroup() {
     } else {
       const getAllFreshItems = async () => {
         try {
-          const response = await api.get("/item/fresh", {
-            params: {
-              itemType: itemType
-            }
+          const data = await getAllPages<Item>("/item/fresh", {
+            itemType: itemType
           });
-          const data = response.data.data;
           setFreshItems(sortItems(data));
         } catch (error) {
           console.error("Error fetching fresh items:", error);
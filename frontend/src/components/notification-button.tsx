@@ -3,7 +3,7 @@
 import { Bell, AlertTriangle } from "lucide-react";
 import { useNotificationStore } from "@/store/useNotificationStore";
 import { useState, useEffect } from "react";
-import api from "@/utils/axios";
+import { getAllPages } from "@/utils/axios";
 import { Item } from "@/types/item.types";
 
 export default function NotificationButton() {
@@ -13,8 +13,7 @@ export default function NotificationButton() {
   useEffect(() => {
     const checkExpiringItems = async () => {
       try {
-        const response = await api.get("/item/fresh");
-        const items = response.data.data || [];
+        const items = await getAllPages<Item>("/item/fresh");
         
         // Filter items yang akan expired dalam 1-2 hari
         const now = new Date();
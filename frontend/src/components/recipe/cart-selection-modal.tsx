@@ -2,7 +2,7 @@
 
 import React, { useState, useEffect } from 'react';
 import { X, ShoppingCart, Plus } from 'lucide-react';
-import api from '../../utils/axios';
+import { getAllPages } from '../../utils/axios';
 
 interface Cart {
   ID: string;
@@ -52,10 +52,7 @@ export function CartSelectionModal({
       setIsLoading(true);
       console.log('Fetching carts for recipe:', recipeName);
       try {
-        const response = await api.get('/cart/all');
-        console.log('Cart response:', response.data);
-        
-        const cartsData = Array.isArray(response.data) ? response.data : (response.data?.data || []);
+        const cartsData = await getAllPages<Cart>('/cart/all');
         
         // Filter carts that match this recipe
         const recipeCarts = cartsData.filter((cart: Cart) => 
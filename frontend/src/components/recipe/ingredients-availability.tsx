@@ -6,7 +6,7 @@ import React, { useState, useEffect, useCallback } from 'react';
 import { Check, X, Plus, ShoppingCart } from 'lucide-react';
 import { IngredientType, Ingredient } from '../../types/recipe.types';
 import { Item } from '../../types/item.types';
-import api from '../../utils/axios';
+import api, { getAllPages } from '../../utils/axios';
 import { CartSelectionModal } from './cart-selection-modal';
 
 interface IngredientsAvailabilityProps {
@@ -118,12 +118,9 @@ export function IngredientsAvailability({
     const fetchUserItems = async () => {
       setIsLoading(true);
       try {
-        const freshResponse = await api.get('/item/fresh');
-        const expiredResponse = await api.get('/item/expired');
-        
         const allItems = [
-          ...(freshResponse.data.data || []),
-          ...(expiredResponse.data.data || [])
+          ...(await getAllPages<Item>('/item/fresh')),
+          ...(await getAllPages<Item>('/item/expired'))
         ];
         
         setUserItems(allItems);
@@ -1,5 +1,8 @@
 import { create } from "zustand";
 
+// Day boundaries follow the device's zone, e.g. Asia/Makassar for WITA
+const browserTimezone = () => Intl.DateTimeFormat().resolvedOptions().timeZone || "Asia/Jakarta";
+
 interface OnboardingState {
   step: number;
   formData: {
@@ -15,6 +18,7 @@ interface OnboardingState {
     healthTarget: string;
     fridgeCapacity: number;
     fridgeModel: string;
+    timezone: string;
   };
   setStep: (step: number) => void;
   setFormData: (data: Partial<OnboardingState["formData"]>) => void;
@@ -36,6 +40,7 @@ export const useOnboardingStore = create<OnboardingState>((set) => ({
     healthTarget: "",
     fridgeCapacity: 300, // Default to Kulkas 2 Pintu
     fridgeModel: "Kulkas 2 Pintu",
+    timezone: browserTimezone(),
   },
   setStep: (step) => set({ step }),
   setFormData: (data) => set((state) => ({ formData: { ...state.formData, ...data } })),
@@ -55,6 +60,7 @@ export const useOnboardingStore = create<OnboardingState>((set) => ({
         healthTarget: "",
         fridgeCapacity: 300,
         fridgeModel: "Kulkas 2 Pintu",
+        timezone: browserTimezone(),
       },
     }),
 }));
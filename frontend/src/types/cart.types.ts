@@ -5,6 +5,7 @@ export interface Cart {
   UserID: string;
   Name: string;
   Desc?: string | null;
+  IsRestock?: boolean;
 }
 
 export interface CartItem {
@@ -12,9 +13,15 @@ export interface CartItem {
   CreatedAt: string;
   UpdatedAt: string;
   CartID: string;
+  ProductID?: string | null;
   Name: string;
   Type: string;
   Amount: number;
   AmountType: string;
   Desc?: string | null;
+  IsChecked?: boolean;
+  IsPurchased?: boolean;
+  PricePaid?: number | null;
+  PurchasedAt?: string | null;
+  ItemID?: string | null;
 }
@@ -3,6 +3,7 @@ export interface Item {
   CreatedAt: string;
   UpdatedAt: string;
   UserId: string;
+  ProductID?: string | null;
   Name: string;
   Type: string;
   Amount: number;
@@ -10,4 +11,6 @@ export interface Item {
   Desc?: string | null;
   StartDate: string;
   ExpDate: string;
+  OpenedAt?: string | null;
+  EffectiveExpDate?: string | null;
 }
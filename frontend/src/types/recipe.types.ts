@@ -131,4 +131,5 @@ export interface RecipeDetail {
   cooking_tool: any[];
   tips_and_trick: any[];
   purchase_detail: any[];
+  over_budget?: boolean;
 }
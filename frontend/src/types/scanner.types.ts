@@ -1,8 +1,21 @@
 export type ScannerType = "barcode" | "image" | "receipt";
 
+export interface BarcodeItemPrefill {
+  name: string;
+  type: string;
+  amount: number;
+  amountType: string;
+  desc: string;
+  startDate: string;
+  expDate: string;
+}
+
 export interface BarcodeResult {
   name: string;
   barcode: string;
+  brand?: string;
+  image_url?: string;
+  prefill?: BarcodeItemPrefill;
 }
 
 export interface ImagePredictionResult {
@@ -10,6 +23,7 @@ export interface ImagePredictionResult {
   predicted_remaining_days: number;
   reasoning: string;
   condition_description?: string;
+  image?: { ID: string; url: string; thumbnail_url: string };
 }
 
 export interface ReceiptItem {
@@ -19,11 +33,33 @@ export interface ReceiptItem {
   confidence: number;
 }
 
+export interface ReceiptDraftItem {
+  name: string;
+  quantity: number;
+  price: number;
+  confidence: number;
+  type: string;
+  amount: number;
+  amount_type: string;
+  start_date: string;
+  exp_date: string;
+  matched_product_id: string | null;
+  matched_product_name?: string;
+  match_score: number;
+}
+
+export interface ReceiptDraft {
+  receipt_id: string | null;
+  items: ReceiptDraftItem[];
+  confidence: number;
+}
+
 export interface ReceiptResult {
   items: ReceiptItem[];
   total_items: number;
   confidence: number;
   processing_time?: string;
+  source?: "gemini" | "local";
 }
 
 export interface FoodScannerProps {
@@ -24,4 +24,17 @@ api.interceptors.response.use(
   }
 );
 
+// Lists come back in pages of { data, pagination: { next_cursor } }, this follows the cursors
+// for callers that need every row
+export async function getAllPages<T>(url: string, params: Record<string, unknown> = {}): Promise<T[]> {
+  const rows: T[] = [];
+  let cursor: string | undefined;
+  do {
+    const response = await api.get(url, { params: { ...params, limit: 100, cursor } });
+    rows.push(...(response.data.data || []));
+    cursor = response.data.pagination?.next_cursor || undefined;
+  } while (cursor);
+  return rows;
+}
+
 export default api;